@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	outrightsmle "github.com/jhw/go-outrights-mle/pkg/outrights-mle"
+)
+
+// LeagueConfig holds configuration for each league
+type LeagueConfig struct {
+	Code           string // ENG1, ENG2, ENG3, ENG4
+	FootballDataID string // E0, E1, E2, E3
+	StartYear      int    // 2015 (for 2015-16 season)
+	EndYear        int    // 2024 (for 2024-25 season)
+}
+
+// English leagues configuration - 10 years of data (2015-16 to 2024-25)
+var englandLeagues = []LeagueConfig{
+	{Code: "ENG1", FootballDataID: "E0", StartYear: 2015, EndYear: 2024},
+	{Code: "ENG2", FootballDataID: "E1", StartYear: 2015, EndYear: 2024},
+	{Code: "ENG3", FootballDataID: "E2", StartYear: 2015, EndYear: 2024},
+	{Code: "ENG4", FootballDataID: "E3", StartYear: 2015, EndYear: 2024},
+}
+
+// FetchOptions controls request pacing, retries and resumption for FetchAllEvents
+type FetchOptions struct {
+	RequestInterval time.Duration // Minimum gap between any two requests, retries included
+	MaxRetries      int           // Attempts per season before giving up on it
+	BackoffBase     time.Duration // Delay before the first retry, doubling for each further retry
+	ManifestFile    string        // Records completed seasons so an aborted run can resume ("" disables)
+}
+
+// DefaultFetchOptions returns the pacing used before the options were configurable
+func DefaultFetchOptions() FetchOptions {
+	return FetchOptions{
+		RequestInterval: 1 * time.Second,
+		MaxRetries:      3,
+		BackoffBase:     2 * time.Second,
+		ManifestFile:    "fixtures/fetch-manifest.json",
+	}
+}
+
+// rateLimiter spaces requests at least interval apart
+type rateLimiter struct {
+	interval time.Duration
+	last     time.Time
+}
+
+// wait blocks until interval has passed since the previous request
+func (l *rateLimiter) wait() {
+	if !l.last.IsZero() {
+		if remaining := l.interval - time.Since(l.last); remaining > 0 {
+			time.Sleep(remaining)
+		}
+	}
+	l.last = time.Now()
+}
+
+// fetchManifest holds the events of every season fetched so far, keyed by "league/season"
+type fetchManifest struct {
+	Seasons map[string][]outrightsmle.MatchResult `json:"seasons"`
+}
+
+// manifestKey identifies one league season in a fetchManifest
+func manifestKey(league LeagueConfig, season string) string {
+	return league.Code + "/" + season
+}
+
+// loadFetchManifest reads a manifest, returning an empty one if the file does not exist
+func loadFetchManifest(filename string) (*fetchManifest, error) {
+	manifest := &fetchManifest{Seasons: make(map[string][]outrightsmle.MatchResult)}
+	if filename == "" {
+		return manifest, nil
+	}
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %w", filename, err)
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest %s: %w", filename, err)
+	}
+	if manifest.Seasons == nil {
+		manifest.Seasons = make(map[string][]outrightsmle.MatchResult)
+	}
+	return manifest, nil
+}
+
+// save writes the manifest through a temp file and rename, so an abort never leaves it half written
+func (m *fetchManifest) save(filename string) error {
+	if filename == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", filename, err)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing manifest %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return fmt.Errorf("replacing manifest %s: %w", filename, err)
+	}
+	return nil
+}
+
+// FetchAllEvents downloads all football events from football-data.co.uk
+// Returns a single concatenated list of all matches across all leagues and seasons
+// Seasons already in options.ManifestFile are not refetched; the manifest is removed once every
+// season has been fetched, so the next run starts fresh
+func FetchAllEvents(options FetchOptions) ([]outrightsmle.MatchResult, error) {
+	if options.MaxRetries < 1 {
+		return nil, fmt.Errorf("max retries must be at least 1, got %d", options.MaxRetries)
+	}
+	manifest, err := loadFetchManifest(options.ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var allEvents []outrightsmle.MatchResult
+
+	fmt.Printf("📥 Fetching football events from football-data.co.uk...\n")
+	fmt.Printf("    Leagues: ENG1-4, Seasons: 2015-16 to 2024-25\n")
+	fmt.Printf("    Rate limiting: %v between requests, %d attempts with backoff from %v\n", options.RequestInterval, options.MaxRetries, options.BackoffBase)
+	if len(manifest.Seasons) > 0 {
+		fmt.Printf("    Resuming: %d seasons already fetched (%s)\n", len(manifest.Seasons), options.ManifestFile)
+	}
+	fmt.Printf("\n")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	limiter := &rateLimiter{interval: options.RequestInterval}
+	
+	totalRequests := 0
+	for _, league := range englandLeagues {
+		totalRequests += (league.EndYear - league.StartYear + 1)
+	}
+
+	requestCount := 0
+	fetchedCount := 0
+	failedCount := 0
+	startTime := time.Now()
+
+	for _, league := range englandLeagues {
+		fmt.Printf("🏈 Processing %s (%s)...\n", league.Code, league.FootballDataID)
+
+		for year := league.StartYear; year <= league.EndYear; year++ {
+			requestCount++
+			season := fmt.Sprintf("%02d%02d", year%100, (year+1)%100) // "1516", "1617", etc.
+			
+			fmt.Printf("  📅 Season %d-%02d (%s) [%d/%d]", year, (year+1)%100, season, requestCount, totalRequests)
+
+			if events, exists := manifest.Seasons[manifestKey(league, season)]; exists {
+				allEvents = append(allEvents, events...)
+				fmt.Printf(" ↩ %d events from manifest\n", len(events))
+				continue
+			}
+
+			events, err := fetchSeasonEvents(client, limiter, options, league, season)
+			fetchedCount++
+			if err != nil {
+				failedCount++
+				fmt.Printf(" ❌ Error: %v\n", err)
+				continue
+			}
+
+			manifest.Seasons[manifestKey(league, season)] = events
+			if err := manifest.save(options.ManifestFile); err != nil {
+				return nil, err
+			}
+
+			allEvents = append(allEvents, events...)
+			fmt.Printf(" ✓ %d events\n", len(events))
+		}
+		fmt.Printf("  ✓ %s complete\n\n", league.Code)
+	}
+
+	elapsed := time.Since(startTime)
+	fmt.Printf("🎯 Data fetching complete!\n")
+	fmt.Printf("   Total events: %d\n", len(allEvents))
+	fmt.Printf("   Total time: %v\n", elapsed)
+	if fetchedCount > 0 {
+		fmt.Printf("   Average per request: %v\n", elapsed/time.Duration(fetchedCount))
+	}
+
+	if failedCount > 0 {
+		fmt.Printf("⚠️  %d seasons failed; run again to resume from %s\n", failedCount, options.ManifestFile)
+	} else if options.ManifestFile != "" {
+		if err := os.Remove(options.ManifestFile); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("removing manifest %s: %w", options.ManifestFile, err)
+		}
+	}
+
+	return allEvents, nil
+}
+
+// fetchSeasonEvents downloads and parses events for a single league season
+func fetchSeasonEvents(client *http.Client, limiter *rateLimiter, options FetchOptions, league LeagueConfig, season string) ([]outrightsmle.MatchResult, error) {
+	url := fmt.Sprintf("https://www.football-data.co.uk/mmz4281/%s/%s.csv", season, league.FootballDataID)
+
+	var lastErr error
+	for attempt := 0; attempt < options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: BackoffBase, then doubling
+			time.Sleep(options.BackoffBase << uint(attempt-1))
+		}
+		// Be a good net citizen - keep requests at least RequestInterval apart
+		limiter.wait()
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		// Set browser-like user agent and friendly headers
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+		req.Header.Set("Accept", "text/csv,text/plain,*/*")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("HTTP request failed: %w", err)
+			continue // Retry on network error
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			events, err := parseCSVEvents(resp.Body, league.Code, season)
+			resp.Body.Close()
+			return events, err
+		}
+		resp.Body.Close()
+
+		// Retry when the server is busy or rate limiting; other HTTP errors are final
+		lastErr = fmt.Errorf("HTTP %d after %d attempts: %s", resp.StatusCode, attempt+1, url)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return nil, lastErr
+		}
+	}
+
+	return nil, lastErr
+}
+
+// parseCSVEvents parses the football-data.co.uk CSV format into MatchResult events
+func parseCSVEvents(reader io.Reader, leagueCode, season string) ([]outrightsmle.MatchResult, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1 // Allow variable field count
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+
+	// Find column indices from header row
+	header := records[0]
+	dateCol := findColumn(header, "Date")
+	homeTeamCol := findColumn(header, "HomeTeam")
+	awayTeamCol := findColumn(header, "AwayTeam")
+	homeGoalsCol := findColumn(header, "FTHG") // Full Time Home Goals
+	awayGoalsCol := findColumn(header, "FTAG") // Full Time Away Goals
+	halfTimeHomeCol := findColumn(header, "HTHG") // Half Time Home Goals (optional)
+	halfTimeAwayCol := findColumn(header, "HTAG") // Half Time Away Goals (optional)
+
+	if dateCol == -1 || homeTeamCol == -1 || awayTeamCol == -1 || homeGoalsCol == -1 || awayGoalsCol == -1 {
+		return nil, fmt.Errorf("required columns not found in CSV header")
+	}
+
+	var events []outrightsmle.MatchResult
+
+	// Parse data rows
+	for _, record := range records[1:] {
+		if len(record) <= max(dateCol, homeTeamCol, awayTeamCol, homeGoalsCol, awayGoalsCol) {
+			continue // Skip malformed rows
+		}
+
+		// Parse date
+		dateStr := strings.TrimSpace(record[dateCol])
+		if dateStr == "" {
+			continue
+		}
+
+		date, err := parseDate(dateStr)
+		if err != nil {
+			continue // Skip rows with invalid dates
+		}
+
+		// Parse team names
+		homeTeam := strings.TrimSpace(record[homeTeamCol])
+		awayTeam := strings.TrimSpace(record[awayTeamCol])
+		if homeTeam == "" || awayTeam == "" {
+			continue
+		}
+
+		// Parse goals
+		homeGoals, err := strconv.Atoi(strings.TrimSpace(record[homeGoalsCol]))
+		if err != nil {
+			continue
+		}
+
+		awayGoals, err := strconv.Atoi(strings.TrimSpace(record[awayGoalsCol]))
+		if err != nil {
+			continue
+		}
+
+		// Create match event with 4-digit season format (e.g., "2425" for 2024-25)
+		event := outrightsmle.MatchResult{
+			Date:      date.Format("2006-01-02"),
+			Season:    season, // Already in YYMM format (e.g., "2425")
+			League:    leagueCode,
+			HomeTeam:  homeTeam,
+			AwayTeam:  awayTeam,
+			HomeGoals: homeGoals,
+			AwayGoals: awayGoals,
+		}
+
+		// Half-time score, when the season's file has it and the row is filled in
+		if halfTimeHomeCol != -1 && halfTimeAwayCol != -1 && len(record) > max(halfTimeHomeCol, halfTimeAwayCol) {
+			halfTimeHome, homeErr := strconv.Atoi(strings.TrimSpace(record[halfTimeHomeCol]))
+			halfTimeAway, awayErr := strconv.Atoi(strings.TrimSpace(record[halfTimeAwayCol]))
+			if homeErr == nil && awayErr == nil {
+				event.HalfTimeHomeGoals = &halfTimeHome
+				event.HalfTimeAwayGoals = &halfTimeAway
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no valid events parsed from CSV")
+	}
+
+	return events, nil
+}
+
+// parseDate handles multiple date formats used by football-data.co.uk
+func parseDate(dateStr string) (time.Time, error) {
+	// Try different date formats
+	formats := []string{
+		"02/01/06",   // DD/MM/YY
+		"2/1/06",     // D/M/YY
+		"02/01/2006", // DD/MM/YYYY
+		"2/1/2006",   // D/M/YYYY
+	}
+
+	for _, format := range formats {
+		if date, err := time.Parse(format, dateStr); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
+
+// findColumn finds the index of a column in the CSV header
+func findColumn(header []string, columnName string) int {
+	for i, col := range header {
+		if strings.EqualFold(strings.TrimSpace(col), columnName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Helper function to find the maximum of multiple integers
+func max(vals ...int) int {
+	if len(vals) == 0 {
+		return 0
+	}
+	maxVal := vals[0]
+	for _, v := range vals[1:] {
+		if v > maxVal {
+			maxVal = v
+		}
+	}
+	return maxVal
+}
\ No newline at end of file
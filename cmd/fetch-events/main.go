@@ -0,0 +1,215 @@
+// Command fetch-events downloads ENG1-4 results from football-data.co.uk into an events file,
+// and optionally bookmaker prices from The Odds API into an odds file alongside it
+//
+// Run from the repository root with:
+//
+//	go run ./cmd/fetch-events [-output fixtures/events.json] [-interval 1s] [-retries 3] [-manifest fixtures/fetch-manifest.json] [-merge]
+//	ODDS_API_KEY=... go run ./cmd/fetch-events -odds fixtures/odds.json [-odds-only] [-team-aliases aliases.json]
+//
+// An aborted or partly failed run leaves the manifest behind, and the next run resumes from it
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	outrightsmle "github.com/jhw/go-outrights-mle/pkg/outrights-mle"
+)
+
+func main() {
+	var (
+		output   = flag.String("output", "fixtures/events.json", "Events file to write")
+		interval = flag.Duration("interval", DefaultFetchOptions().RequestInterval, "Minimum gap between requests, retries included")
+		retries  = flag.Int("retries", DefaultFetchOptions().MaxRetries, "Attempts per season before skipping it")
+		manifest = flag.String("manifest", DefaultFetchOptions().ManifestFile, "Manifest of seasons fetched so far, so an aborted run resumes (empty disables)")
+		merge    = flag.Bool("merge", false, "Merge the fetched events into -output (deduplicated, sorted by date) instead of overwriting it")
+		odds     = flag.String("odds", "", "Also fetch bookmaker 1X2 and outright prices from The Odds API (needs ODDS_API_KEY) into this file")
+		oddsOnly = flag.Bool("odds-only", false, "Fetch only the -odds file, matching team names against the events already in -output")
+		aliases  = flag.String("team-aliases", "", "JSON file mapping bookmaker team names to canonical team names")
+	)
+	flag.Parse()
+
+	if *oddsOnly {
+		if *odds == "" {
+			log.Fatalf("-odds-only needs -odds")
+		}
+		events, err := loadEventsFromFile(*output)
+		if err != nil {
+			log.Fatalf("Failed to load events for team names: %v", err)
+		}
+		if err := fetchOddsToFile(events, *aliases, *odds); err != nil {
+			log.Fatalf("Fetch odds failed: %v", err)
+		}
+		return
+	}
+
+	options := DefaultFetchOptions()
+	options.RequestInterval = *interval
+	options.MaxRetries = *retries
+	options.ManifestFile = *manifest
+	events, err := FetchAllEvents(options)
+	if err != nil {
+		log.Fatalf("Fetch events failed: %v", err)
+	}
+	if *merge {
+		added, err := mergeEventsToFile(events, *output)
+		if err != nil {
+			log.Fatalf("Failed to merge events: %v", err)
+		}
+		fmt.Printf("💾 Merged %d new events into %s\n", added, *output)
+	} else {
+		if err := saveEventsToFile(events, *output); err != nil {
+			log.Fatalf("Failed to save events: %v", err)
+		}
+		fmt.Printf("💾 Saved %d events to %s\n", len(events), *output)
+	}
+
+	if *odds != "" {
+		if err := fetchOddsToFile(events, *aliases, *odds); err != nil {
+			log.Fatalf("Fetch odds failed: %v", err)
+		}
+	}
+}
+
+// fetchOddsToFile pulls bookmaker prices for every league in events, mapping bookmaker team
+// names to the events' teams, and saves them as an odds snapshot
+func fetchOddsToFile(events []outrightsmle.MatchResult, aliasesFile, filename string) error {
+	apiKey := os.Getenv("ODDS_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("ODDS_API_KEY must be set")
+	}
+	aliases, err := loadTeamAliases(aliasesFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📥 Fetching bookmaker odds from The Odds API...\n")
+	snapshot, err := newOddsAPIClient(apiKey).fetchOdds(outrightsmle.ExtractLeagues(events), outrightsmle.ExtractGlobalEntities(events).Teams, aliases)
+	if err != nil {
+		return err
+	}
+	if len(snapshot.Unmatched) > 0 {
+		fmt.Printf("⚠️  Unmatched team names: %s (add them to -team-aliases)\n", strings.Join(snapshot.Unmatched, ", "))
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("writing file %s: %w", filename, err)
+	}
+	fmt.Printf("✓ Saved %d fixture and %d outright prices to %s\n", len(snapshot.Fixtures), len(snapshot.Outrights), filename)
+	return nil
+}
+
+// loadTeamAliases reads an external name -> canonical team JSON map (no file means no aliases)
+func loadTeamAliases(filename string) (map[string]string, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return aliases, nil
+}
+
+// saveEventsToFile saves events to a JSON file
+// It writes a temp file and renames it over filename, so an interrupted save never leaves a
+// truncated file behind
+func saveEventsToFile(events []outrightsmle.MatchResult, filename string) error {
+	// Create directories if they don't exist
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	// Write to a temp file in the same directory so the rename stays on one filesystem
+	file, err := os.CreateTemp(dir, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp file in %s: %w", dir, err)
+	}
+	tmpName := file.Name()
+	defer os.Remove(tmpName) // No-op once renamed
+
+	// Encode JSON with indentation for readability
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(events); err != nil {
+		file.Close()
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", tmpName, err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("replacing %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// mergeEventsToFile merges events into an existing events file instead of overwriting it
+// Matches are deduplicated on (date, home, away, league), with the new copy replacing the old,
+// and the merged list is sorted by date. Returns how many of events were not already in the file
+func mergeEventsToFile(events []outrightsmle.MatchResult, filename string) (int, error) {
+	existing, err := loadEventsFromFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	type matchKey struct{ date, home, away, league string }
+	keyOf := func(event outrightsmle.MatchResult) matchKey {
+		return matchKey{event.Date, event.HomeTeam, event.AwayTeam, event.League}
+	}
+
+	index := make(map[matchKey]int, len(existing)+len(events))
+	merged := make([]outrightsmle.MatchResult, 0, len(existing)+len(events))
+	added := 0
+	for i, event := range append(existing, events...) {
+		key := keyOf(event)
+		if position, exists := index[key]; exists {
+			merged[position] = event
+			continue
+		}
+		if i >= len(existing) {
+			added++
+		}
+		index[key] = len(merged)
+		merged = append(merged, event)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Date < merged[j].Date })
+
+	if err := saveEventsToFile(merged, filename); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// loadEventsFromFile loads events from a JSON file
+func loadEventsFromFile(filename string) ([]outrightsmle.MatchResult, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	var events []outrightsmle.MatchResult
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", filename, err)
+	}
+	return events, nil
+}
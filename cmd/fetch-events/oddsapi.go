@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	outrightsmle "github.com/jhw/go-outrights-mle/pkg/outrights-mle"
+)
+
+// oddsAPIBaseURL is The Odds API (the-odds-api.com) v4 endpoint
+const oddsAPIBaseURL = "https://api.the-odds-api.com/v4"
+
+// oddsAPISports maps league codes to The Odds API sport keys for match odds
+var oddsAPISports = map[string]string{
+	"ENG1": "soccer_epl",
+	"ENG2": "soccer_efl_champ",
+	"ENG3": "soccer_england_league1",
+	"ENG4": "soccer_england_league2",
+}
+
+// oddsAPIOutrightSports maps league codes to The Odds API sport keys for league winner markets
+var oddsAPIOutrightSports = map[string]string{
+	"ENG1": "soccer_epl_winner",
+}
+
+// oddsAPIClient pulls UK bookmaker prices from The Odds API
+type oddsAPIClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// newOddsAPIClient returns a client for The Odds API v4
+func newOddsAPIClient(apiKey string) *oddsAPIClient {
+	return &oddsAPIClient{
+		apiKey:     apiKey,
+		baseURL:    oddsAPIBaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// oddsAPIEvent is the shared event shape of the odds endpoints
+type oddsAPIEvent struct {
+	CommenceTime time.Time `json:"commence_time"`
+	HomeTeam     string    `json:"home_team"`
+	AwayTeam     string    `json:"away_team"`
+	Bookmakers   []struct {
+		Key     string `json:"key"`
+		Markets []struct {
+			Key      string `json:"key"`
+			Outcomes []struct {
+				Name  string  `json:"name"`
+				Price float64 `json:"price"`
+			} `json:"outcomes"`
+		} `json:"markets"`
+	} `json:"bookmakers"`
+}
+
+// fetchOdds pulls 1X2 prices for each league's upcoming fixtures and winner prices where The
+// Odds API lists an outright, mapping bookmaker team names to teams (aliases first)
+func (c *oddsAPIClient) fetchOdds(leagues []string, teams []string, aliases map[string]string) (*outrightsmle.OddsSnapshot, error) {
+	snapshot := &outrightsmle.OddsSnapshot{FetchedAt: time.Now().UTC()}
+	unmatched := make(map[string]bool)
+	match := func(name string) string {
+		team := outrightsmle.MatchTeamName(name, teams, aliases)
+		if team == "" && !unmatched[name] {
+			unmatched[name] = true
+			snapshot.Unmatched = append(snapshot.Unmatched, name)
+		}
+		return team
+	}
+
+	for _, league := range leagues {
+		sport, exists := oddsAPISports[league]
+		if !exists {
+			return nil, fmt.Errorf("no Odds API sport for league %s", league)
+		}
+		events, err := c.fetchEvents(sport, "h2h")
+		if err != nil {
+			return nil, fmt.Errorf("league %s: %w", league, err)
+		}
+
+		for _, event := range events {
+			homeTeam, awayTeam := match(event.HomeTeam), match(event.AwayTeam)
+			if homeTeam == "" || awayTeam == "" {
+				continue
+			}
+			for _, bookmaker := range event.Bookmakers {
+				for _, market := range bookmaker.Markets {
+					if market.Key != "h2h" {
+						continue
+					}
+					odds := outrightsmle.FixtureOdds{
+						League:    league,
+						Date:      event.CommenceTime.UTC().Format("2006-01-02"),
+						HomeTeam:  homeTeam,
+						AwayTeam:  awayTeam,
+						Bookmaker: bookmaker.Key,
+					}
+					// Outcomes are named by team, with "Draw" for the draw
+					for _, outcome := range market.Outcomes {
+						switch outcome.Name {
+						case event.HomeTeam:
+							odds.Prices[0] = outcome.Price
+						case "Draw":
+							odds.Prices[1] = outcome.Price
+						case event.AwayTeam:
+							odds.Prices[2] = outcome.Price
+						}
+					}
+					snapshot.Fixtures = append(snapshot.Fixtures, odds)
+				}
+			}
+		}
+
+		sport, exists = oddsAPIOutrightSports[league]
+		if !exists {
+			continue
+		}
+		events, err = c.fetchEvents(sport, "outrights")
+		if err != nil {
+			return nil, fmt.Errorf("league %s outrights: %w", league, err)
+		}
+		for _, event := range events {
+			for _, bookmaker := range event.Bookmakers {
+				odds := outrightsmle.OutrightOdds{League: league, Market: "Winner", Bookmaker: bookmaker.Key, Prices: make(map[string]float64)}
+				for _, market := range bookmaker.Markets {
+					for _, outcome := range market.Outcomes {
+						if team := match(outcome.Name); team != "" {
+							odds.Prices[team] = outcome.Price
+						}
+					}
+				}
+				snapshot.Outrights = append(snapshot.Outrights, odds)
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// fetchEvents reads one sport's odds for a market type ("h2h" or "outrights")
+func (c *oddsAPIClient) fetchEvents(sport, markets string) ([]oddsAPIEvent, error) {
+	query := url.Values{
+		"apiKey":     {c.apiKey},
+		"regions":    {"uk"},
+		"markets":    {markets},
+		"oddsFormat": {"decimal"},
+	}
+	// The API only takes the key as a query parameter, so errors report the endpoint without it
+	endpoint := fmt.Sprintf("%s/sports/%s/odds", strings.TrimSuffix(c.baseURL, "/"), sport)
+
+	resp, err := c.httpClient.Get(endpoint + "?" + query.Encode())
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = &url.Error{Op: urlErr.Op, URL: endpoint, Err: urlErr.Err}
+		}
+		return nil, fmt.Errorf("odds API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading odds API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("odds API returned HTTP %d for %s: %s", resp.StatusCode, sport, strings.TrimSpace(string(data)))
+	}
+
+	var events []oddsAPIEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("decoding odds API response: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,98 @@
+//go:build js && wasm
+
+// Command wasm exposes rating optimization and fixture pricing to JavaScript
+//
+// Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o outrights.wasm ./cmd/wasm
+//
+// Registers two global functions, each taking and returning a JSON string:
+//
+//	optimizeRatings(requestJSON)  // MLERequest -> MLEParams
+//	priceFixtures(pricingJSON)    // {"params", "sim_params", "fixtures", "league"} -> []MatchOdds
+//
+// Errors are returned as {"error": "..."}
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	outrightsmle "github.com/jhw/go-outrights-mle/pkg/outrights-mle"
+)
+
+// PricingRequest is the input for priceFixtures
+type PricingRequest struct {
+	Params    outrightsmle.MLEParams  `json:"params"`
+	SimParams *outrightsmle.SimParams `json:"sim_params,omitempty"`
+	Fixtures  []string                `json:"fixtures"` // "{Home} vs {Away}"
+	League    string                  `json:"league,omitempty"`
+	Neutral   bool                    `json:"neutral,omitempty"` // Price every fixture without home advantage
+}
+
+func main() {
+	js.Global().Set("optimizeRatings", js.FuncOf(optimizeRatings))
+	js.Global().Set("priceFixtures", js.FuncOf(priceFixtures))
+
+	// Keep the Go runtime alive for JavaScript callbacks
+	select {}
+}
+
+// optimizeRatings decodes an MLERequest and returns fitted MLEParams
+func optimizeRatings(this js.Value, args []js.Value) interface{} {
+	var request outrightsmle.MLERequest
+	if err := decodeArg(args, &request); err != nil {
+		return errorJSON(err)
+	}
+
+	params, err := outrightsmle.OptimizeRatings(request)
+	if err != nil {
+		return errorJSON(err)
+	}
+	return encodeResult(params)
+}
+
+// priceFixtures decodes a PricingRequest and returns 1X2 probabilities per fixture
+func priceFixtures(this js.Value, args []js.Value) interface{} {
+	var request PricingRequest
+	if err := decodeArg(args, &request); err != nil {
+		return errorJSON(err)
+	}
+
+	price := outrightsmle.PriceFixtures
+	if request.Neutral {
+		price = outrightsmle.PriceNeutralFixtures
+	}
+	matchOdds, err := price(request.Params, request.SimParams, request.Fixtures, request.League)
+	if err != nil {
+		return errorJSON(err)
+	}
+	return encodeResult(matchOdds)
+}
+
+// decodeArg unmarshals the first JavaScript argument as JSON
+func decodeArg(args []js.Value, target interface{}) error {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return fmt.Errorf("expected a single JSON string argument")
+	}
+	if err := json.Unmarshal([]byte(args[0].String()), target); err != nil {
+		return fmt.Errorf("decoding JSON: %w", err)
+	}
+	return nil
+}
+
+// encodeResult marshals a result to a JSON string
+func encodeResult(result interface{}) interface{} {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorJSON(fmt.Errorf("encoding JSON: %w", err))
+	}
+	return string(data)
+}
+
+// errorJSON returns an error as a JSON string
+func errorJSON(err error) interface{} {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(data)
+}
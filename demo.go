@@ -1,17 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	outrightsmle "github.com/jhw/go-outrights-mle/pkg/outrights-mle"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
@@ -26,6 +33,86 @@ func main() {
 		dataFile    = flag.String("data", "", "Path to historical match data JSON file")
 		fetchEvents = flag.Bool("fetch-events", false, "Fetch events data from football-data.co.uk and save to fixtures/events.json")
 		runModel    = flag.Bool("run-model", false, "Run MLE model on all leagues using events data")
+		configFile  = flag.String("config", "", "Path to YAML/JSON run config file (CLI flags override config values)")
+		marketsFile = flag.String("markets", "fixtures/markets.json", "Path to markets JSON file for -run-model")
+		leagues     = flag.String("leagues", "", "Comma-separated leagues to include in -run-model (default: all)")
+		seasons     = flag.String("seasons", "", "Comma-separated seasons to include in -run-model, e.g. a five-season training window (default: all)")
+		profile     = flag.Bool("profile", false, "Show phase-level timing breakdown for -run-model")
+		fitSeasonHomeAdvantage = flag.Bool("fit-season-home-advantage", false, "Estimate home advantage per season (shrunk toward -home-advantage)")
+		seasonHomeAdvantage    = flag.String("season-home-advantage", "", "Comma-separated fixed home advantage per season, e.g. \"2021=0.05\"")
+		fitSeasonRho           = flag.Bool("fit-season-rho", false, "Estimate Dixon-Coles rho per season (shrunk toward -rho)")
+		seasonRho              = flag.String("season-rho", "", "Comma-separated fixed Dixon-Coles rho per season, e.g. \"2016=-0.05\"")
+		decimalOdds   = flag.Bool("decimal-odds", false, "Show mark values as fair decimal odds (1/p) in -run-model mark tables")
+		saveResult    = flag.String("save-result", "", "Write the -run-model result as JSON to this file, for a later -compare")
+		saveParams    = flag.String("save-params", "", "Fit ratings on the -run-model events, write the MLEParams as JSON to this file and exit")
+		matchOdds     = flag.Bool("match-odds", false, "Include remaining-fixture odds in the -run-model result and print the first few per league")
+		ratingsOnly   = flag.Bool("ratings-only", false, "Fit ratings and build tables in -run-model without simulating the season or evaluating markets")
+		deterministic = flag.Bool("deterministic", false, "Seed -run-model simulations when -seed is unset, zero timings and write -save-result byte-stable")
+		paramsFile    = flag.String("params", "", "Skip the -run-model fit and predict the season from MLEParams written by -save-params")
+		compareFiles  = flag.String("compare", "", "Compare two saved results, e.g. \"yesterday.json,today.json\", and report moved teams")
+		compareThresholds = flag.String("compare-thresholds", "", "Thresholds for -compare, e.g. \"mark=0.02,points=1,rating=0.05\" (defaults as shown)")
+		snapshotStore = flag.String("snapshot-store", "", "JSON lines file that each -run-model appends its marks to (with a timestamp and data fingerprint)")
+		validateMarkets = flag.Bool("validate-markets", false, "Check the -markets file against the core-data league groups without running the model")
+		markHistory   = flag.String("mark-history", "", "Print one team's mark history from -snapshot-store, e.g. \"ENG1/Winner/Arsenal\"")
+		checkFixtures = flag.Bool("check-fixtures", false, "Check each league season in fixtures/events.json has a full season of matches for its format")
+		fetchOdds     = flag.Bool("fetch-odds", false, "Fetch bookmaker 1X2 and outright prices from The Odds API (moved to go run ./cmd/fetch-events -odds)")
+		oddsFile      = flag.String("odds-file", "fixtures/odds.json", "Bookmaker odds file written by go run ./cmd/fetch-events -odds")
+		oddsPrices    = flag.Bool("odds-prices", false, "Price -run-model markets from the best bookmaker outright prices in -odds-file")
+		betfairMarkets = flag.String("betfair-markets", "", "Comma-separated exchange prices for -run-model markets, e.g. \"ENG1/Winner=1.234567890\" (needs BETFAIR_APP_KEY and BETFAIR_SESSION_TOKEN)")
+		cupDrawFile   = flag.String("cup-draw", "", "Path to JSON cup draw (teams, pots, rounds) to simulate with ratings fitted on the -run-model events")
+		checkGradients = flag.Int("check-gradients", 0, "Fit on the -run-model events and check analytic gradients against finite differences on N matches (0 disables)")
+		impliedRatings = flag.Bool("implied-ratings", false, "Compare ratings implied by the 1X2 prices in -odds-file with ratings fitted on the -run-model events")
+		headToHead    = flag.String("head-to-head", "", "Print two teams' meetings and current prices both ways, e.g. \"Arsenal,Chelsea\", with ratings fitted on the -run-model events")
+		scoreGrid     = flag.String("score-grid", "", "Print the correct-score grid for one fixture, e.g. \"Arsenal vs Chelsea\", with ratings fitted on the -run-model events")
+		scoreGridFormat = flag.String("score-grid-format", "json", "Output format for -score-grid: json or csv")
+		asianHandicap = flag.String("asian-handicap", "", "Price home Asian handicap lines for one fixture, e.g. \"Arsenal vs Chelsea=-1.25,-0.5,0\", with ratings fitted on the -run-model events")
+		tournamentFile = flag.String("tournament", "", "Path to JSON tournament (groups, qualifiers, bracket) to simulate with ratings fitted on the -run-model events")
+		teamRenames   = flag.String("team-renames", "", "Path to JSON list of team renames ({\"from\", \"to\", \"season\"}) applied to -run-model events before fitting")
+		managerChanges = flag.String("manager-changes", "", "Path to JSON list of manager changes ({\"team\", \"date\", \"manager\"}); those teams' -run-model ratings learn faster from the change")
+		ratingOverrides = flag.String("rating-overrides", "", "Path to JSON map of team to rating overrides ({\"attack\", \"defense\", \"attack_offset\", \"defense_offset\", \"reason\"}) applied to -run-model ratings after the fit")
+		lambdaMultipliers = flag.String("lambda-multipliers", "", "Path to JSON map of team to expected goals multipliers ({\"attack\", \"defense\"}) applied to -run-model prices and simulations, not the fit")
+		teamAliases   = flag.String("team-aliases", "", "Path to JSON map of external team names (e.g. exchange runners) to canonical team names")
+		stream        = flag.Bool("stream", false, "Print each league's mark table as soon as its simulation completes in -run-model")
+		overround     = flag.Float64("overround", 0, "Outright overround for a book prices table next to the mark tables, e.g. 0.2 for a 120% book (0 disables)")
+		marginMethod  = flag.String("margin-method", "proportional", "How -overround is spread: proportional or favourite_weighted")
+		priceLadder   = flag.String("price-ladder", "", "Snap fair prices in edge reports to a bookmaker ladder: tick or fractional (empty disables)")
+		oddsFormat    = flag.String("odds-format", "decimal", "Price format for mark tables, edge reports and Kelly stakes: decimal, fractional or american")
+		tiebreaks     = flag.String("tiebreaks", "", "Comma-separated tiebreak chain after points for every league in -run-model (goal_difference, goals_for, head_to_head, alphabetical)")
+		asOfDate      = flag.String("as-of", "", "Only use results on or before this date (YYYY-MM-DD) in -run-model, for historical runs")
+		cupData       = flag.String("cup-data", "", "Path to cup match results JSON (each tagged with a competition) to add to the -run-model fit")
+		cupWeight     = flag.Float64("cup-weight", 0.5, "Likelihood weight for cup matches without a configured competition weight")
+		independentLeagues = flag.Bool("independent-leagues", false, "Fit each league on its own matches only (no cross-league pooling) in -run-model")
+		vectorizedFit = flag.Bool("vectorized-fit", false, "Evaluate the -run-model likelihood and gradients with dense vector kernels (gonum-backed when built with -tags gonum)")
+		ratingModel   = flag.String("rating-model", "static", "Rating model: static (time-decayed MLE) or dynamic (random-walk filter)")
+		countModel    = flag.String("count-model", "poisson", "Goal count model: poisson, cmp (Conway-Maxwell-Poisson) or weibull (Weibull count), with a fitted shape")
+		noDeadHeat    = flag.Bool("no-dead-heat", false, "Resolve exact points/GD ties by sort order instead of dead-heating payoffs")
+		positionsFile = flag.String("positions", "", "Path to JSON positions file (league, market, team, stake, price) for book exposure")
+		standardMarkets = flag.Bool("standard-markets", false, "Generate standard outright markets per league instead of loading a markets file")
+		validationGameweeks = flag.Int("validation-gameweeks", 0, "Hold out the most recent N gameweeks and stop fitting when validation loss stops improving (0 disables)")
+		formCovariates = flag.String("form-covariates", "", "Comma-separated form covariates to fit with the ratings and report: recent_ppg, unbeaten_streak, short_rest")
+		minTeamMatches = flag.Int("min-team-matches", 0, "Leave teams with fewer matches out of the fit, unless they play in a latest-season league (0 disables)")
+		fitIntercept = flag.Bool("fit-intercept", false, "Estimate the average log goal rate as an intercept instead of fixing it at zero")
+		leagueIntercepts = flag.Bool("league-intercepts", false, "Estimate a log goal rate per league, so each league's ratings are relative to its own scoring level")
+		transferWindowLearningRate = flag.Float64("transfer-window-learning-rate", 1.0, "Enhancement multiplier for matches in the 30 days after a transfer window closes (1 disables)")
+		seed          = flag.Int64("seed", 0, "Simulation random seed for reproducible marks (0 = random)")
+		rho           = flag.Float64("rho", -0.1, "Dixon-Coles low-score correlation")
+		tune          = flag.Bool("tune", false, "Search hyperparameters against walk-forward 1X2 log loss instead of running the model")
+		tuneSamples   = flag.Int("tune-samples", 12, "Random candidates for -tune (0 = full grid)")
+		tuneFolds     = flag.Int("tune-folds", 3, "Walk-forward seasons for -tune")
+		whatIf        = flag.String("what-if", "", "Comma-separated fixed results for a what-if re-simulation, e.g. \"Man City vs Liverpool=away,Arsenal vs Chelsea=2-1\"")
+		importance    = flag.Int("importance", 0, "Show the N most important remaining fixtures per league (0 disables)")
+		pointsBand    = flag.String("points-band", "", "Comma-separated points band queries, e.g. \"Leeds:90+,Luton:40-50\"")
+		positionRange = flag.String("position-range", "", "Comma-separated position range queries, e.g. \"Leeds:1-2,Luton:18-20\"")
+		exportPaths   = flag.String("export-paths", "", "Write sampled -run-model simulation paths per league, e.g. \"ENG1=eng1.csv,ENG2=eng2.json\" (CSV or JSON by extension)")
+		exportPathsSample = flag.Int("export-paths-sample", 1000, "Paths per league for -export-paths (0 = all)")
+		postmortemSeason = flag.String("postmortem", "", "Score what the model predicted for this completed season (e.g. 2324) against its final tables, instead of running the model")
+		postmortemCheckpoints = flag.String("postmortem-checkpoints", "pre-season", "Comma-separated -postmortem checkpoints, e.g. \"pre-season,christmas=2023-12-25\" (a bare name is the day before the season)")
+		postmortemLeagues = flag.String("postmortem-leagues", "", "Comma-separated leagues to score in -postmortem (default: every league in the season)")
+		trajectoryDates = flag.String("trajectory", "", "Comma-separated as-of dates (YYYY-MM-DD) for a rating trajectory refit in -run-model")
+		trajectoryTeams = flag.String("trajectory-teams", "", "Comma-separated teams to show in the rating trajectory (default: all)")
+		kellyBankroll = flag.Float64("kelly-bankroll", 0, "Bankroll for Kelly stakes on priced markets (0 disables)")
+		kellyFraction = flag.Float64("kelly-fraction", 0.5, "Kelly multiplier (1 = full Kelly)")
+		kellyCap      = flag.Float64("kelly-cap", 0.05, "Maximum stake per bet as a fraction of bankroll (0 = no cap)")
 		
 		// Simulation parameters
 		timeDecayBase          = flag.Float64("time-decay-base", 0.85, "Time decay base factor")
@@ -38,12 +125,103 @@ func main() {
 	)
 	flag.Parse()
 
+	// Load config file and apply its values to any flags not set on the command line
+	var config *RunConfig
+	if *configFile != "" {
+		var err error
+		config, err = loadRunConfig(*configFile)
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+
+		applyConfigString("league", league, config.League)
+		applyConfigString("season", season, config.Season)
+		applyConfigString("data", dataFile, config.EventsFile)
+		applyConfigString("markets", marketsFile, config.MarketsFile)
+		applyConfigString("leagues", leagues, strings.Join(config.Leagues, ","))
+		applyConfigString("seasons", seasons, strings.Join(config.Seasons, ","))
+		applyConfigBool("run-model", runModel, config.RunModel)
+		applyConfigBool("verbose", verbose, config.Verbose)
+		applyConfigBool("debug", debug, config.Debug)
+		applyConfigBool("independent-leagues", independentLeagues, config.IndependentLeagues)
+		applyConfigString("as-of", asOfDate, config.AsOfDate)
+
+		if config.SimParams != nil {
+			sp := config.SimParams
+			applyConfigInt("maxiter", maxiter, sp.MaxIterations)
+			applyConfigFloat("tolerance", tolerance, sp.Tolerance)
+			applyConfigFloat("time-decay-base", timeDecayBase, sp.TimeDecayBase)
+			applyConfigFloat("time-decay-factor", timeDecayFactor, sp.TimeDecayPower)
+			applyConfigFloat("learning-rate-base", learningRateBase, sp.BaseLearningRate)
+			applyConfigFloat("league-change-learning-rate", leagueChangeLearningRate, sp.LeagueChangeLearningRate)
+			applyConfigInt("simulation-paths", simulationPaths, sp.SimulationPaths)
+			applyConfigFloat("home-advantage", homeAdvantage, sp.HomeAdvantage)
+			applyConfigBool("no-dead-heat", noDeadHeat, sp.DisableDeadHeat)
+			applyConfigBool("decimal-odds", decimalOdds, sp.DecimalOdds)
+			applyConfigString("rating-model", ratingModel, sp.RatingModel)
+			applyConfigString("count-model", countModel, sp.CountModel)
+			applyConfigInt("validation-gameweeks", validationGameweeks, sp.ValidationGameweeks)
+			applyConfigInt("min-team-matches", minTeamMatches, sp.MinMatchesPerTeam)
+			applyConfigFloat("transfer-window-learning-rate", transferWindowLearningRate, sp.TransferWindowLearningRate)
+			applyConfigBool("fit-intercept", fitIntercept, sp.FitIntercept)
+			applyConfigBool("league-intercepts", leagueIntercepts, sp.FitLeagueIntercepts)
+			applyConfigString("form-covariates", formCovariates, strings.Join(sp.FormCovariates, ","))
+			if sp.Seed != 0 && !isFlagSet("seed") {
+				*seed = sp.Seed
+			}
+			applyConfigFloat("rho", rho, sp.Rho)
+		}
+	}
+
 	fmt.Printf("🏈 Go Outrights MLE Demo\n")
 	fmt.Printf("========================\n\n")
+	if config != nil {
+		fmt.Printf("✓ Loaded run config from %s\n", *configFile)
+	}
 
 	// Handle fetch-events flag
 	if *fetchEvents {
-		fmt.Printf("🌐 Fetch events feature temporarily disabled\n")
+		fmt.Printf("🌐 Fetching has its own command: go run ./cmd/fetch-events\n")
+		return
+	}
+
+	// Handle check-fixtures flag
+	if *checkFixtures {
+		events, err := loadEventsFromFile("fixtures/events.json")
+		if err != nil {
+			log.Fatalf("Failed to load events: %v", err)
+		}
+		displayCompleteness(outrightsmle.CheckCompleteness(events, outrightsmle.StandardLeagueFormats()))
+		return
+	}
+
+	// Handle fetch-odds flag
+	if *fetchOdds {
+		fmt.Printf("🌐 Fetching has its own command: ODDS_API_KEY=... go run ./cmd/fetch-events -odds %s -odds-only\n", *oddsFile)
+		return
+	}
+
+	// Handle compare flag
+	if *compareFiles != "" {
+		if err := runCompare(*compareFiles, *compareThresholds); err != nil {
+			log.Fatalf("Compare failed: %v", err)
+		}
+		return
+	}
+
+	// Handle mark-history flag
+	if *markHistory != "" {
+		if err := displayMarkHistory(*snapshotStore, *markHistory); err != nil {
+			log.Fatalf("Mark history failed: %v", err)
+		}
+		return
+	}
+
+	// Handle validate-markets flag
+	if *validateMarkets {
+		if err := runValidateMarkets(*marketsFile); err != nil {
+			log.Fatalf("Market validation failed: %v", err)
+		}
 		return
 	}
 
@@ -52,44 +230,400 @@ func main() {
 		fmt.Printf("🧮 Running MLE model on all leagues...\n")
 		
 		// Load events data
-		events, err := loadEventsFromFile("fixtures/events.json")
+		loadStart := time.Now()
+		eventsFile := *dataFile
+		if eventsFile == "" {
+			eventsFile = "fixtures/events.json"
+		}
+		events, err := loadEventsFromFile(eventsFile)
 		if err != nil {
 			log.Fatalf("Failed to load events data: %v", err)
 		}
 		
+		// Restrict to selected leagues and seasons if requested
+		processor := outrightsmle.NewEventProcessor(events, false)
+		if *leagues != "" {
+			processor = processor.FilterByLeagues(splitList(*leagues)...)
+		}
+		if *seasons != "" {
+			processor = processor.FilterBySeasons(splitList(*seasons)...)
+		}
+		events = processor.Events()
+		
 		// Log events statistics
 		logEventsStatistics(events)
 
-		// Load markets data
-		markets, err := loadMarketsFromFile("fixtures/markets.json")
-		if err != nil {
-			fmt.Printf("⚠️  Could not load markets file (%v), proceeding without markets\n", err)
-			markets = []outrightsmle.Market{} // Empty markets
+		// Cup results only inform ratings, so they are added after league selection
+		if *cupData != "" {
+			cupEvents, err := loadCupMatchesFromFile(*cupData)
+			if err != nil {
+				log.Fatalf("Failed to load cup data: %v", err)
+			}
+			events = append(events, cupEvents...)
+			fmt.Printf("✓ Loaded %d cup matches from %s\n", len(cupEvents), *cupData)
+		}
+
+		// Load markets data (inline config markets take precedence over the markets file)
+		var markets []outrightsmle.Market
+		if *standardMarkets {
+			var formats map[string]outrightsmle.LeagueFormat
+			if config != nil {
+				formats = config.Formats
+			}
+			markets, err = generateStandardMarkets(events, formats)
+			if err != nil {
+				log.Fatalf("Failed to generate standard markets: %v", err)
+			}
+			fmt.Printf("✓ Generated %d standard markets\n", len(markets))
+		} else if config != nil && len(config.Markets) > 0 && !isFlagSet("markets") {
+			markets = config.Markets
+			fmt.Printf("✓ Loaded %d markets from %s\n", len(markets), *configFile)
 		} else {
-			fmt.Printf("✓ Loaded %d markets from fixtures/markets.json\n", len(markets))
+			markets, err = loadMarketsFromFile(*marketsFile)
+			if err != nil {
+				fmt.Printf("⚠️  Could not load markets file (%v), proceeding without markets\n", err)
+				markets = []outrightsmle.Market{} // Empty markets
+			} else {
+				fmt.Printf("✓ Loaded %d markets from %s\n", len(markets), *marketsFile)
+			}
+		}
+
+		if *oddsPrices {
+			if err := applyBookmakerPrices(*oddsFile, markets); err != nil {
+				log.Fatalf("Failed to load bookmaker prices: %v", err)
+			}
+		}
+
+		// Exchange prices replace any file prices, so the edge report measures against the exchange
+		var exchangeMarkets map[string]*outrightsmle.ExchangeMarket
+		if *betfairMarkets != "" {
+			exchangeMarkets, err = fetchBetfairPrices(*betfairMarkets, *teamAliases, events, markets)
+			if err != nil {
+				log.Fatalf("Failed to fetch Betfair prices: %v", err)
+			}
 		}
 
-		// Parse handicaps from JSON string
+		// Parse handicaps from JSON string, falling back to config handicaps
 		handicapsMap, err := parseHandicaps(*handicaps)
 		if err != nil {
 			log.Fatalf("Failed to parse handicaps: %v", err)
 		}
+		if config != nil && len(config.Handicaps) > 0 && !isFlagSet("handicaps") {
+			handicapsMap = config.Handicaps
+		}
 
 		// Create SimParams with flag overrides
-		simParams := createSimParamsFromFlags(*maxiter, *tolerance, *timeDecayBase, *timeDecayFactor, *learningRateBase, *leagueChangeLearningRate, *simulationPaths, *homeAdvantage)
+		simParams := createSimParamsFromFlags(configSimParams(config), *maxiter, *tolerance, *timeDecayBase, *timeDecayFactor, *learningRateBase, *leagueChangeLearningRate, *simulationPaths, *homeAdvantage)
+		simParams.DisableDeadHeat = *noDeadHeat
+		simParams.DecimalOdds = *decimalOdds || isFlagSet("odds-format")
+		if _, err := outrightsmle.FormatOdds(2.0, *oddsFormat); err != nil {
+			log.Fatalf("Invalid -odds-format: %v", err)
+		}
+		if isFlagSet("overround") {
+			simParams.OutrightOverround = *overround
+		}
+		if isFlagSet("margin-method") {
+			simParams.MarginMethod = *marginMethod
+		}
+		switch *priceLadder {
+		case "":
+		case "tick":
+			simParams.PriceLadder = outrightsmle.StandardTickLadder()
+		case "fractional":
+			simParams.PriceLadder = outrightsmle.FractionalPriceLadder()
+		default:
+			log.Fatalf("Invalid -price-ladder %q: expected tick or fractional", *priceLadder)
+		}
+		simParams.RatingModel = *ratingModel
+		simParams.CountModel = *countModel
+		simParams.Rho = *rho
+		simParams.ValidationGameweeks = *validationGameweeks
+		simParams.MinMatchesPerTeam = *minTeamMatches
+		simParams.TransferWindowLearningRate = *transferWindowLearningRate
+		simParams.FitIntercept = *fitIntercept
+		simParams.FitLeagueIntercepts = *leagueIntercepts
+		if *formCovariates != "" {
+			simParams.FormCovariates = splitList(*formCovariates)
+		}
+		simParams.Seed = *seed
+		if isFlagSet("cup-weight") {
+			simParams.CupMatchWeight = *cupWeight
+		}
+		if err := applySeasonHomeAdvantageFlags(simParams, *fitSeasonHomeAdvantage, *seasonHomeAdvantage); err != nil {
+			log.Fatalf("Invalid -season-home-advantage: %v", err)
+		}
+		if err := applySeasonRhoFlags(simParams, *fitSeasonRho, *seasonRho); err != nil {
+			log.Fatalf("Invalid -season-rho: %v", err)
+		}
+		if *tiebreaks != "" {
+			simParams.Tiebreaks = make(map[string][]string)
+			for _, league := range outrightsmle.ExtractLeagues(events) {
+				simParams.Tiebreaks[league] = strings.Split(*tiebreaks, ",")
+			}
+		}
+		fileLoadTime := time.Since(loadStart)
+		
+		renames, err := loadTeamRenames(*teamRenames)
+		if err != nil {
+			log.Fatalf("Failed to load team renames: %v", err)
+		}
+		if config != nil && !isFlagSet("team-renames") && len(config.TeamRenames) > 0 {
+			renames = config.TeamRenames
+		}
+		changes, err := loadManagerChanges(*managerChanges)
+		if err != nil {
+			log.Fatalf("Failed to load manager changes: %v", err)
+		}
+		if config != nil && !isFlagSet("manager-changes") && len(config.ManagerChanges) > 0 {
+			changes = config.ManagerChanges
+		}
+		overrides, err := loadRatingOverrides(*ratingOverrides)
+		if err != nil {
+			log.Fatalf("Failed to load rating overrides: %v", err)
+		}
+		if config != nil && !isFlagSet("rating-overrides") && len(config.RatingOverrides) > 0 {
+			overrides = config.RatingOverrides
+		}
+		multipliers, err := loadLambdaMultipliers(*lambdaMultipliers)
+		if err != nil {
+			log.Fatalf("Failed to load lambda multipliers: %v", err)
+		}
+		if config != nil && !isFlagSet("lambda-multipliers") && len(config.LambdaMultipliers) > 0 {
+			multipliers = config.LambdaMultipliers
+		}
+		
+		if *tune {
+			renamed, err := outrightsmle.ApplyTeamRenames(events, renames)
+			if err != nil {
+				log.Fatalf("Invalid team renames: %v", err)
+			}
+			runTuner(renamed, simParams, *tuneSamples, *tuneFolds)
+			return
+		}
+		
+		if *checkGradients > 0 {
+			check, err := outrightsmle.CheckGradients(outrightsmle.MLERequest{
+				HistoricalData: events,
+				Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+			}, *checkGradients)
+			if err != nil {
+				log.Fatalf("Gradient check failed: %v", err)
+			}
+			fmt.Printf("\n🔬 Gradient check: %d ratings on %d matches (step %g)\n", check.Parameters, check.Matches, check.Step)
+			fmt.Printf("   Max relative error %.2e at %s (analytic %.6f, numeric %.6f)\n",
+				check.MaxRelativeError, check.WorstParameter, check.Analytic, check.Numeric)
+			return
+		}
+		
+		if *impliedRatings {
+			if err := runImpliedRatings(events, simParams, renames, *oddsFile); err != nil {
+				log.Fatalf("Implied ratings failed: %v", err)
+			}
+			return
+		}
+		
+		if *headToHead != "" {
+			if err := runHeadToHead(events, simParams, renames, *headToHead); err != nil {
+				log.Fatalf("Head to head failed: %v", err)
+			}
+			return
+		}
+		
+		if *scoreGrid != "" {
+			if err := runScoreGrid(events, simParams, renames, *scoreGrid, *scoreGridFormat); err != nil {
+				log.Fatalf("Score grid failed: %v", err)
+			}
+			return
+		}
+		
+		if *asianHandicap != "" {
+			if err := runAsianHandicap(events, simParams, renames, *asianHandicap); err != nil {
+				log.Fatalf("Asian handicap failed: %v", err)
+			}
+			return
+		}
+		
+		if *cupDrawFile != "" {
+			if err := runCupDraw(events, simParams, renames, *cupDrawFile); err != nil {
+				log.Fatalf("Cup draw failed: %v", err)
+			}
+			return
+		}
+		
+		if *tournamentFile != "" {
+			if err := runTournament(events, simParams, renames, *tournamentFile); err != nil {
+				log.Fatalf("Tournament failed: %v", err)
+			}
+			return
+		}
 		
 		// Run model and get teams by league
-		teamsByLeague, result, err := runMLEModel(events, markets, *debug, simParams, handicapsMap)
+		options := outrightsmle.MLEOptions{
+			SimParams:          simParams,
+			Debug:              *debug,
+			IndependentLeagues: *independentLeagues,
+			AsOfDate:           *asOfDate,
+			TeamRenames:        renames,
+			ManagerChanges:     changes,
+			LambdaMultipliers:  multipliers,
+			RatingOverrides:    overrides,
+			Deterministic:      *deterministic,
+			IncludeMatchOdds:   *matchOdds,
+			RatingsOnly:        *ratingsOnly,
+			VectorizedFit:      *vectorizedFit,
+		}
+		if len(changes) > 0 {
+			fmt.Printf("✓ Enhanced learning for %d manager changes\n", len(changes))
+		}
+		if len(multipliers) > 0 {
+			fmt.Printf("✓ Availability multipliers for %d teams\n", len(multipliers))
+		}
+		if config != nil && config.RatingsBlend != nil {
+			options.RatingsBlend = config.RatingsBlend
+			fmt.Printf("✓ Blending fitted ratings with %d external rating sets\n", len(config.RatingsBlend.Sources))
+		}
+		if config != nil && config.SuppliedRatings != nil {
+			options.SuppliedRatings = config.SuppliedRatings
+			fmt.Printf("✓ Simulating from %d supplied team ratings (no fit)\n", len(config.SuppliedRatings.AttackRatings))
+		}
+		if *stream {
+			options.OnLeagueResult = func(league outrightsmle.LeagueResult) {
+				fmt.Printf("\n⏱️  %s finished after %v\n", league.League, time.Since(loadStart).Round(time.Millisecond))
+				displayMarkTables(leagueOnlyResult(league), *oddsFormat)
+			}
+		}
+		if *saveParams != "" {
+			if err := saveParamsToFile(events, options, *saveParams); err != nil {
+				log.Fatalf("Saving params failed: %v", err)
+			}
+			fmt.Printf("\n💾 Saved fitted params to %s\n", *saveParams)
+			return
+		}
+		if *postmortemSeason != "" {
+			if err := runPostmortem(events, markets, options, handicapsMap, *postmortemSeason, *postmortemCheckpoints, *postmortemLeagues, *verbose); err != nil {
+				log.Fatalf("Postmortem failed: %v", err)
+			}
+			return
+		}
+		saved, err := loadParamsFromFile(*paramsFile)
+		if err != nil {
+			log.Fatalf("Failed to load params: %v", err)
+		}
+		if saved != nil {
+			fmt.Printf("✓ Predicting from saved params in %s (no fit)\n", *paramsFile)
+		}
+		teamsByLeague, result, err := runMLEModel(events, markets, options, handicapsMap, saved)
 		if err != nil {
 			log.Fatalf("MLE model failed: %v", err)
 		}
 
 		// Display results for latest season - teams first
 		displayTeamsByLeague(teamsByLeague, *verbose)
+		displayLeagueChanges(result.LeagueChanges, *verbose)
+		displayRatingOverrides(result.RatingOverrides)
+		displayMatchOdds(result.MatchOdds, *verbose)
 		
-		// Display mark tables second if markets were provided  
-		if len(result.MarkValues) > 0 {
-			displayMarkTables(result)
+		// Display mark tables second if markets were provided (streamed runs have shown them already)
+		if len(result.MarkValues) > 0 && !*stream {
+			displayMarkTables(result, *oddsFormat)
+		}
+
+		if len(result.EdgeReports) > 0 {
+			displayEdgeReports(result.EdgeReports, *oddsFormat)
+		}
+
+		if len(exchangeMarkets) > 0 {
+			displayExchangeCalibration(result, exchangeMarkets)
+		}
+
+		if *positionsFile != "" {
+			positions, err := loadPositionsFromFile(*positionsFile)
+			if err != nil {
+				log.Fatalf("Failed to load positions: %v", err)
+			}
+			reports, err := outrightsmle.CalculateExposure(result, positions)
+			if err != nil {
+				log.Fatalf("Exposure calculation failed: %v", err)
+			}
+			displayExposure(reports)
+		}
+
+		if *kellyBankroll > 0 {
+			kellyOptions := outrightsmle.KellyOptions{Bankroll: *kellyBankroll, Fraction: *kellyFraction, MaxStake: *kellyCap}
+			stakes, err := outrightsmle.CalculateKellyStakes(result, kellyOptions)
+			if err != nil {
+				log.Fatalf("Kelly staking failed: %v", err)
+			}
+			displayKellyStakes(stakes, *oddsFormat)
+		}
+
+		if *whatIf != "" {
+			conditioning, err := parseWhatIf(*whatIf)
+			if err != nil {
+				log.Fatalf("Invalid -what-if: %v", err)
+			}
+			conditional, err := outrightsmle.RunConditionalSimulation(result, conditioning)
+			if err != nil {
+				log.Fatalf("What-if simulation failed: %v", err)
+			}
+			displayWhatIf(result, conditional, conditioning, *oddsFormat)
+		}
+
+		if *importance > 0 {
+			displayMatchImportance(result, *importance)
+		}
+
+		if *pointsBand != "" || *positionRange != "" {
+			displayRangeQueries(result, *pointsBand, *positionRange)
+		}
+
+		if *exportPaths != "" {
+			if err := exportPathSamples(result, *exportPaths, *exportPathsSample); err != nil {
+				log.Fatalf("Exporting paths failed: %v", err)
+			}
+		}
+
+		if *trajectoryDates != "" {
+			request := outrightsmle.MLERequest{
+				HistoricalData: events,
+				Options:        outrightsmle.MLEOptions{SimParams: simParams},
+			}
+			trajectoryOptions := outrightsmle.TrajectoryOptions{
+				AsOfDates: strings.Split(*trajectoryDates, ","),
+				WarmStart: true,
+			}
+			trajectory, err := outrightsmle.CalculateRatingTrajectory(request, trajectoryOptions)
+			if err != nil {
+				log.Fatalf("Rating trajectory failed: %v", err)
+			}
+			var teams []string
+			if *trajectoryTeams != "" {
+				teams = strings.Split(*trajectoryTeams, ",")
+			}
+			displayRatingTrajectory(trajectory, teams)
+		}
+
+		if *profile {
+			displayTimings(result, fileLoadTime)
+			displayDiagnostics(result)
+		}
+
+		if *snapshotStore != "" {
+			snapshot, err := outrightsmle.NewMarkSnapshot(result, events, options, time.Now())
+			if err == nil {
+				err = appendSnapshotToFile(snapshot, *snapshotStore)
+			}
+			if err != nil {
+				log.Fatalf("Failed to store mark snapshot: %v", err)
+			}
+			fmt.Printf("\n🗂️  Appended marks to %s\n", *snapshotStore)
+		}
+
+		if *saveResult != "" {
+			if err := saveResultToFile(result, *saveResult, *deterministic); err != nil {
+				log.Fatalf("Failed to save result: %v", err)
+			}
+			fmt.Printf("\n💾 Saved result to %s\n", *saveResult)
 		}
 		return
 	}
@@ -131,7 +665,25 @@ func main() {
 	fmt.Printf("✓ Loaded %d matches from %s\n", len(historicalData), *dataFile)
 
 	// Create SimParams with flag overrides
-	simParams := createSimParamsFromFlags(*maxiter, *tolerance, *timeDecayBase, *timeDecayFactor, *learningRateBase, *leagueChangeLearningRate, *simulationPaths, *homeAdvantage)
+	simParams := createSimParamsFromFlags(configSimParams(config), *maxiter, *tolerance, *timeDecayBase, *timeDecayFactor, *learningRateBase, *leagueChangeLearningRate, *simulationPaths, *homeAdvantage)
+	simParams.RatingModel = *ratingModel
+	simParams.CountModel = *countModel
+	simParams.Rho = *rho
+	simParams.ValidationGameweeks = *validationGameweeks
+	simParams.MinMatchesPerTeam = *minTeamMatches
+	simParams.TransferWindowLearningRate = *transferWindowLearningRate
+	simParams.FitIntercept = *fitIntercept
+	simParams.FitLeagueIntercepts = *leagueIntercepts
+	if *formCovariates != "" {
+		simParams.FormCovariates = splitList(*formCovariates)
+	}
+	simParams.Seed = *seed
+	if err := applySeasonHomeAdvantageFlags(simParams, *fitSeasonHomeAdvantage, *seasonHomeAdvantage); err != nil {
+		log.Fatalf("Invalid -season-home-advantage: %v", err)
+	}
+	if err := applySeasonRhoFlags(simParams, *fitSeasonRho, *seasonRho); err != nil {
+		log.Fatalf("Invalid -season-rho: %v", err)
+	}
 	
 	options := outrightsmle.MLEOptions{
 		SimParams: simParams,
@@ -156,8 +708,27 @@ func main() {
 
 	fmt.Printf("\n✓ MLE optimization completed in %v\n", result.ProcessingTime)
 	fmt.Printf("✓ Converged: %v (iterations: %d)\n", result.MLEParams.Converged, result.MLEParams.Iterations)
+	for _, name := range simParams.FormCovariates {
+		coefficient, standardError := result.MLEParams.CovariateCoefficients[name], result.MLEParams.CovariateStandardErrors[name]
+		fmt.Printf("✓ Form covariate %s: %.4f (standard error %.4f)\n", name, coefficient, standardError)
+	}
+	for _, dropped := range result.MLEParams.DroppedTeams {
+		fmt.Printf("✓ Dropped %s: %d matches (last season %s)\n", dropped.Team, dropped.Matches, dropped.LastSeason)
+	}
 	fmt.Printf("✓ Log likelihood: %.2f\n", result.MLEParams.LogLikelihood)
 	fmt.Printf("✓ Home advantage: %.3f\n", result.MLEParams.HomeAdvantage)
+	if simParams.FitIntercept {
+		fmt.Printf("✓ Intercept: %.3f (%.2f goals per match for an average team away)\n", result.MLEParams.Intercept, math.Exp(result.MLEParams.Intercept))
+	}
+	var interceptLeagues []string
+	for league := range result.MLEParams.LeagueIntercepts {
+		interceptLeagues = append(interceptLeagues, league)
+	}
+	sort.Strings(interceptLeagues)
+	for _, league := range interceptLeagues {
+		intercept := result.MLEParams.Intercept + result.MLEParams.LeagueIntercepts[league]
+		fmt.Printf("✓ League intercept %s: %+.3f (%.2f goals per match for an average team away)\n", league, result.MLEParams.LeagueIntercepts[league], math.Exp(intercept))
+	}
 
 	// Sort teams by expected season points for league table order
 	sort.Slice(result.Teams, func(i, j int) bool {
@@ -277,9 +848,12 @@ func logEventsStatistics(events []outrightsmle.MatchResult) {
 	}
 }
 
-// createSimParamsFromFlags creates SimParams with defaults, overriding with provided flag values
-func createSimParamsFromFlags(maxiter int, tolerance, timeDecayBase, timeDecayFactor, learningRateBase, leagueChangeLearningRate float64, simulationPaths int, homeAdvantage float64) *outrightsmle.SimParams {
+// createSimParamsFromFlags creates SimParams from base (defaults if nil), overriding with provided flag values
+func createSimParamsFromFlags(base *outrightsmle.SimParams, maxiter int, tolerance, timeDecayBase, timeDecayFactor, learningRateBase, leagueChangeLearningRate float64, simulationPaths int, homeAdvantage float64) *outrightsmle.SimParams {
 	simParams := outrightsmle.DefaultSimParams()
+	if base != nil {
+		*simParams = *base
+	}
 	
 	// Override with flag values
 	simParams.MaxIterations = maxiter
@@ -309,31 +883,170 @@ func parseHandicaps(handicapsStr string) (map[string]int, error) {
 	return handicapsMap, nil
 }
 
+// RunConfig holds the settings for a repeatable run, loaded from a YAML or JSON file via -config
+// Field names match the JSON tags used elsewhere in the package (e.g., sim_params.home_advantage)
+type RunConfig struct {
+	League      string                  `json:"league,omitempty"`       // League for single-league mode
+	Season      string                  `json:"season,omitempty"`       // Season identifier
+	Leagues     []string                `json:"leagues,omitempty"`      // Leagues to include in -run-model (default: all)
+	Seasons     []string                `json:"seasons,omitempty"`      // Seasons to include in -run-model (default: all)
+	RunModel    bool                    `json:"run_model"`              // Equivalent to -run-model
+	Verbose     bool                    `json:"verbose"`                // Equivalent to -verbose
+	Debug       bool                    `json:"debug"`                  // Equivalent to -debug
+	IndependentLeagues bool             `json:"independent_leagues"`    // Equivalent to -independent-leagues
+	AsOfDate    string                  `json:"as_of_date,omitempty"`   // Equivalent to -as-of
+	EventsFile  string                  `json:"events_file,omitempty"`  // Historical match data file
+	MarketsFile string                  `json:"markets_file,omitempty"` // Markets file for -run-model
+	Markets     []outrightsmle.Market   `json:"markets,omitempty"`      // Inline markets (take precedence over markets_file)
+	Handicaps   map[string]int          `json:"handicaps,omitempty"`    // Initial points for teams
+	SimParams   *outrightsmle.SimParams `json:"sim_params,omitempty"`   // Missing fields keep their defaults
+	Formats     map[string]outrightsmle.LeagueFormat `json:"formats,omitempty"` // League formats for -standard-markets (override the built-in ones)
+	TeamRenames []outrightsmle.TeamRename `json:"team_renames,omitempty"` // Equivalent to -team-renames, inline
+	ManagerChanges []outrightsmle.ManagerChange `json:"manager_changes,omitempty"` // Equivalent to -manager-changes, inline
+	LambdaMultipliers map[string]outrightsmle.LambdaMultiplier `json:"lambda_multipliers,omitempty"` // Equivalent to -lambda-multipliers, inline
+	RatingOverrides map[string]outrightsmle.RatingOverride `json:"rating_overrides,omitempty"` // Equivalent to -rating-overrides, inline
+	RatingsBlend *outrightsmle.RatingsBlend `json:"ratings_blend,omitempty"` // External rating sets mixed into the -run-model fit
+	SuppliedRatings *outrightsmle.RatingSet `json:"supplied_ratings,omitempty"` // Ratings -run-model simulates from in place of a fit
+}
 
-// saveEventsToFile saves events to a JSON file
-func saveEventsToFile(events []outrightsmle.MatchResult, filename string) error {
-	// Create directories if they don't exist
-	dir := filepath.Dir(filename)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", dir, err)
+// loadRunConfig loads a run config from a .yaml/.yml or .json file
+func loadRunConfig(filename string) (*RunConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", filename, err)
 	}
 
-	// Open file for writing
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("creating file %s: %w", filename, err)
+	// YAML is decoded generically and re-encoded as JSON so the json tags are the single source of field names
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		var raw interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("decoding YAML from %s: %w", filename, err)
+		}
+		data, err = json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("converting YAML from %s: %w", filename, err)
+		}
+	case ".json":
+	default:
+		return nil, fmt.Errorf("unsupported config format %q (use .yaml, .yml or .json)", filepath.Ext(filename))
 	}
-	defer file.Close()
 
-	// Encode JSON with indentation for readability
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	config := &RunConfig{SimParams: outrightsmle.DefaultSimParams()}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("decoding config from %s: %w", filename, err)
+	}
+	return config, nil
+}
 
-	if err := encoder.Encode(events); err != nil {
-		return fmt.Errorf("encoding JSON: %w", err)
+// configSimParams returns the config's SimParams, or nil if no config was loaded
+func configSimParams(config *RunConfig) *outrightsmle.SimParams {
+	if config == nil {
+		return nil
 	}
+	return config.SimParams
+}
 
-	return nil
+// isFlagSet reports whether a flag was explicitly set on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// applyConfigString sets a string flag from config unless it was given on the command line
+func applyConfigString(name string, target *string, value string) {
+	if value != "" && !isFlagSet(name) {
+		*target = value
+	}
+}
+
+// applyConfigBool sets a bool flag from config unless it was given on the command line
+func applyConfigBool(name string, target *bool, value bool) {
+	if !isFlagSet(name) {
+		*target = value
+	}
+}
+
+// applyConfigInt sets an int flag from config unless it was given on the command line
+// The config's SimParams start from the defaults, so a zero there was given explicitly
+func applyConfigInt(name string, target *int, value int) {
+	if !isFlagSet(name) {
+		*target = value
+	}
+}
+
+// applyConfigFloat sets a float flag from config unless it was given on the command line
+// The config's SimParams start from the defaults, so a zero there was given explicitly
+func applyConfigFloat(name string, target *float64, value float64) {
+	if !isFlagSet(name) {
+		*target = value
+	}
+}
+
+// generateStandardMarkets builds standard outright markets for every league in the events with a known format
+// Formats from the run config override the built-in ones
+func generateStandardMarkets(events []outrightsmle.MatchResult, overrides map[string]outrightsmle.LeagueFormat) ([]outrightsmle.Market, error) {
+	formats := outrightsmle.StandardLeagueFormats()
+	for league, format := range overrides {
+		formats[league] = format
+	}
+	leagues := outrightsmle.ExtractLeagues(events)
+	sort.Strings(leagues)
+
+	var markets []outrightsmle.Market
+	for _, league := range leagues {
+		format, exists := formats[league]
+		if !exists {
+			fmt.Printf("⚠️  No standard format for %s, skipping market generation\n", league)
+			continue
+		}
+		leagueMarkets, err := outrightsmle.StandardMarkets(league, format)
+		if err != nil {
+			return nil, err
+		}
+		markets = append(markets, leagueMarkets...)
+	}
+	return markets, nil
+}
+
+// splitList splits a comma-separated flag value into trimmed entries
+func splitList(value string) []string {
+	entries := strings.Split(value, ",")
+	for i := range entries {
+		entries[i] = strings.TrimSpace(entries[i])
+	}
+	return entries
+}
+
+// displayCompleteness prints each league season's match count, with the teams short of games
+func displayCompleteness(reports []outrightsmle.SeasonCompleteness) {
+	fmt.Printf("📋 Fixture completeness (%d league seasons)\n", len(reports))
+	fmt.Printf("%-6s %-6s %6s %8s %8s %5s\n", "League", "Season", "Teams", "Played", "Expected", "Dupes")
+
+	partial := 0
+	for _, report := range reports {
+		status := "✓"
+		if !report.Complete {
+			status = "⚠️"
+			partial++
+		}
+		fmt.Printf("%-6s %-6s %6d %8d %8d %5d %s\n", report.League, report.Season, report.Teams, report.Played, report.Expected, report.Duplicates, status)
+		teams := make([]string, 0, len(report.MissingGames))
+		for team := range report.MissingGames {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+		for _, team := range teams {
+			fmt.Printf("       %s: %d games missing\n", team, report.MissingGames[team])
+		}
+	}
+
+	fmt.Printf("\n%d of %d league seasons complete\n", len(reports)-partial, len(reports))
 }
 
 // loadEventsFromFile loads events from a JSON file
@@ -354,6 +1067,71 @@ func loadEventsFromFile(filename string) ([]outrightsmle.MatchResult, error) {
 	return events, nil
 }
 
+// applySeasonHomeAdvantageFlags applies -fit-season-home-advantage and a "season=value,..." schedule
+func applySeasonHomeAdvantageFlags(simParams *outrightsmle.SimParams, fit bool, schedule string) error {
+	if isFlagSet("fit-season-home-advantage") {
+		simParams.FitSeasonHomeAdvantage = fit
+	}
+	if schedule == "" {
+		return nil
+	}
+
+	values, err := parseSeasonSchedule(schedule, "home advantage")
+	if err != nil {
+		return err
+	}
+	simParams.SeasonHomeAdvantage = values
+	return nil
+}
+
+// applySeasonRhoFlags applies -fit-season-rho and a "season=value,..." schedule
+func applySeasonRhoFlags(simParams *outrightsmle.SimParams, fit bool, schedule string) error {
+	if isFlagSet("fit-season-rho") {
+		simParams.FitSeasonRho = fit
+	}
+	if schedule == "" {
+		return nil
+	}
+
+	values, err := parseSeasonSchedule(schedule, "rho")
+	if err != nil {
+		return err
+	}
+	simParams.SeasonRho = values
+	return nil
+}
+
+// parseSeasonSchedule parses a "season=value,..." schedule of the named parameter
+func parseSeasonSchedule(schedule, name string) (map[string]float64, error) {
+	values := make(map[string]float64)
+	for _, entry := range strings.Split(schedule, ",") {
+		season, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			return nil, fmt.Errorf("expected season=value, got %q", entry)
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in %q: %w", name, entry, err)
+		}
+		values[season] = parsed
+	}
+	return values, nil
+}
+
+// loadCupMatchesFromFile loads cup match results, each of which must name its competition
+func loadCupMatchesFromFile(filename string) ([]outrightsmle.MatchResult, error) {
+	matches, err := loadEventsFromFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	for i, match := range matches {
+		if match.Competition == "" {
+			return nil, fmt.Errorf("cup match %d (%s vs %s) has no competition", i, match.HomeTeam, match.AwayTeam)
+		}
+	}
+	return matches, nil
+}
+
 // loadMarketsFromFile loads markets from a JSON file
 func loadMarketsFromFile(filename string) ([]outrightsmle.Market, error) {
 	file, err := os.Open(filename)
@@ -372,36 +1150,697 @@ func loadMarketsFromFile(filename string) ([]outrightsmle.Market, error) {
 	return markets, nil
 }
 
-// TeamResult holds team data with league information
-type TeamResult struct {
-	League string
-	Season string
-	Team   outrightsmle.Team
-}
-
-
-// runMLEModel processes all events using the API and returns teams grouped by league
-func runMLEModel(events []outrightsmle.MatchResult, markets []outrightsmle.Market, debug bool, simParams *outrightsmle.SimParams, handicaps map[string]int) (map[string][]TeamResult, *outrightsmle.MultiLeagueResult, error) {
-	// Set up MLE options with provided SimParams
-	options := outrightsmle.MLEOptions{
-		SimParams: simParams,
-		Debug:     debug,
+// loadPositionsFromFile loads book positions from a JSON file
+func loadPositionsFromFile(filename string) ([]outrightsmle.Position, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
 	}
 
-	// Use the high-level API to run MLE optimization across all leagues
-	result, err := outrightsmle.RunMLESolver(events, markets, options, handicaps)
-	if err != nil {
-		// Check if this is a wrapped validation error and provide helpful message
-		if strings.Contains(err.Error(), "league groups validation failed") {
-			fmt.Printf("❌ League groups configuration validation failed:\n")
-			fmt.Printf("   Teams in core-data/*-teams.json files must exist in the event data.\n")
-			fmt.Printf("   Error: %v\n", err)
-			return nil, nil, fmt.Errorf("invalid league groups configuration")
-		}
-		return nil, nil, fmt.Errorf("MLE solver failed: %w", err)
+	var positions []outrightsmle.Position
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
 	}
 
-	// Convert API result to demo format for display compatibility
+	return positions, nil
+}
+
+// fetchBetfairPrices reads "league/market=marketID" exchange markets and sets each matching
+// market's prices to the best back prices; returns the exchange markets by "league/market"
+func fetchBetfairPrices(spec, aliasesFile string, events []outrightsmle.MatchResult, markets []outrightsmle.Market) (map[string]*outrightsmle.ExchangeMarket, error) {
+	appKey, sessionToken := os.Getenv("BETFAIR_APP_KEY"), os.Getenv("BETFAIR_SESSION_TOKEN")
+	if appKey == "" || sessionToken == "" {
+		return nil, fmt.Errorf("BETFAIR_APP_KEY and BETFAIR_SESSION_TOKEN must be set")
+	}
+	aliases, err := loadTeamAliases(aliasesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	client := newBetfairClient(appKey, sessionToken)
+	teams := outrightsmle.ExtractGlobalEntities(events).Teams
+	exchangeMarkets := make(map[string]*outrightsmle.ExchangeMarket)
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		names := strings.SplitN(parts[0], "/", 2)
+		if len(parts) != 2 || len(names) != 2 {
+			return nil, fmt.Errorf("invalid entry %q: expected league/market=marketID", entry)
+		}
+		league, marketName, marketID := names[0], names[1], parts[1]
+
+		index := -1
+		for i := range markets {
+			if markets[i].League == league && markets[i].Name == marketName {
+				index = i
+			}
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("no %s market named %q", league, marketName)
+		}
+
+		exchange, err := client.fetchMarket(marketID, teams, aliases)
+		if err != nil {
+			return nil, err
+		}
+		if unmatched := exchange.Unmatched(); len(unmatched) > 0 {
+			fmt.Printf("⚠️  %s: unmatched runners %s (add them to -team-aliases)\n", parts[0], strings.Join(unmatched, ", "))
+		}
+		markets[index].Prices = exchange.Prices()
+		exchangeMarkets[parts[0]] = exchange
+		fmt.Printf("✓ Loaded %d exchange prices for %s from market %s\n", len(markets[index].Prices), parts[0], marketID)
+	}
+	return exchangeMarkets, nil
+}
+
+// betfairBaseURL is the Betfair Exchange betting API (REST) endpoint
+const betfairBaseURL = "https://api.betfair.com/exchange/betting/rest/v1.0"
+
+// betfairClient reads outright markets from the Betfair Exchange betting API
+// appKey and sessionToken come from a Betfair developer account and login session
+type betfairClient struct {
+	appKey       string
+	sessionToken string
+	baseURL      string
+	httpClient   *http.Client
+}
+
+// newBetfairClient returns a client for the global exchange
+func newBetfairClient(appKey, sessionToken string) *betfairClient {
+	return &betfairClient{
+		appKey:       appKey,
+		sessionToken: sessionToken,
+		baseURL:      betfairBaseURL,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// fetchMarket reads a market's runners and best prices, matching runner names to teams
+// aliases maps exchange runner names to canonical team names where the names differ
+func (c *betfairClient) fetchMarket(marketID string, teams []string, aliases map[string]string) (*outrightsmle.ExchangeMarket, error) {
+	var catalogue []struct {
+		MarketName string `json:"marketName"`
+		Runners    []struct {
+			SelectionID int64  `json:"selectionId"`
+			RunnerName  string `json:"runnerName"`
+		} `json:"runners"`
+	}
+	err := c.call("listMarketCatalogue", map[string]interface{}{
+		"filter":           map[string]interface{}{"marketIds": []string{marketID}},
+		"marketProjection": []string{"RUNNER_DESCRIPTION"},
+		"maxResults":       1,
+	}, &catalogue)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalogue) == 0 {
+		return nil, fmt.Errorf("betfair market %s not found", marketID)
+	}
+
+	var books []struct {
+		Runners []struct {
+			SelectionID int64 `json:"selectionId"`
+			Ex          struct {
+				AvailableToBack []struct {
+					Price float64 `json:"price"`
+				} `json:"availableToBack"`
+				AvailableToLay []struct {
+					Price float64 `json:"price"`
+				} `json:"availableToLay"`
+			} `json:"ex"`
+		} `json:"runners"`
+	}
+	err = c.call("listMarketBook", map[string]interface{}{
+		"marketIds":       []string{marketID},
+		"priceProjection": map[string]interface{}{"priceData": []string{"EX_BEST_OFFERS"}},
+	}, &books)
+	if err != nil {
+		return nil, err
+	}
+
+	// Best offers come first in each ladder
+	backPrices := make(map[int64]float64)
+	layPrices := make(map[int64]float64)
+	for _, book := range books {
+		for _, runner := range book.Runners {
+			if len(runner.Ex.AvailableToBack) > 0 {
+				backPrices[runner.SelectionID] = runner.Ex.AvailableToBack[0].Price
+			}
+			if len(runner.Ex.AvailableToLay) > 0 {
+				layPrices[runner.SelectionID] = runner.Ex.AvailableToLay[0].Price
+			}
+		}
+	}
+
+	market := &outrightsmle.ExchangeMarket{MarketID: marketID, MarketName: catalogue[0].MarketName}
+	for _, runner := range catalogue[0].Runners {
+		market.Runners = append(market.Runners, outrightsmle.ExchangeRunner{
+			Runner:    runner.RunnerName,
+			Team:      outrightsmle.MatchTeamName(runner.RunnerName, teams, aliases),
+			BackPrice: backPrices[runner.SelectionID],
+			LayPrice:  layPrices[runner.SelectionID],
+		})
+	}
+	return market, nil
+}
+
+// call posts one betting API operation and decodes its JSON response into out
+func (c *betfairClient) call(operation string, params interface{}, out interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encoding %s request: %w", operation, err)
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(c.baseURL, "/")+"/"+operation+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating %s request: %w", operation, err)
+	}
+	req.Header.Set("X-Application", c.appKey)
+	req.Header.Set("X-Authentication", c.sessionToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("betfair %s failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading betfair %s response: %w", operation, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("betfair %s returned HTTP %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding betfair %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// applyBookmakerPrices sets each market's prices to the best bookmaker prices in the odds file
+func applyBookmakerPrices(filename string, markets []outrightsmle.Market) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading file %s: %w", filename, err)
+	}
+	var snapshot outrightsmle.OddsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	for i := range markets {
+		if prices := outrightsmle.BestOutrightPrices(snapshot.Outrights, markets[i].League, markets[i].Name); len(prices) > 0 {
+			markets[i].Prices = prices
+			fmt.Printf("✓ Loaded %d bookmaker prices for %s/%s\n", len(prices), markets[i].League, markets[i].Name)
+		}
+	}
+	return nil
+}
+
+// loadTeamAliases reads an external name -> canonical team JSON map (no file means no aliases)
+func loadTeamAliases(filename string) (map[string]string, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return aliases, nil
+}
+
+// loadTeamRenames reads a JSON list of team renames ("" loads none)
+func loadTeamRenames(filename string) ([]outrightsmle.TeamRename, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var renames []outrightsmle.TeamRename
+	if err := json.Unmarshal(data, &renames); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return renames, nil
+}
+
+// loadManagerChanges reads a JSON list of manager changes ("" loads none)
+func loadManagerChanges(filename string) ([]outrightsmle.ManagerChange, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var changes []outrightsmle.ManagerChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return changes, nil
+}
+
+// loadLambdaMultipliers reads a JSON map of team to lambda multipliers ("" loads none)
+func loadLambdaMultipliers(filename string) (map[string]outrightsmle.LambdaMultiplier, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var multipliers map[string]outrightsmle.LambdaMultiplier
+	if err := json.Unmarshal(data, &multipliers); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return multipliers, nil
+}
+
+// loadRatingOverrides reads a JSON map of team to rating overrides ("" loads none)
+func loadRatingOverrides(filename string) (map[string]outrightsmle.RatingOverride, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var overrides map[string]outrightsmle.RatingOverride
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return overrides, nil
+}
+
+// displayExchangeCalibration prints how far each exchange market's marks sit from its prices
+func displayExchangeCalibration(result *outrightsmle.MultiLeagueResult, exchangeMarkets map[string]*outrightsmle.ExchangeMarket) {
+	var keys []string
+	for key := range exchangeMarkets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		names := strings.SplitN(key, "/", 2)
+		report := outrightsmle.ExchangeCalibration(result.MarkValues[names[0]][names[1]], exchangeMarkets[key])
+		fmt.Printf("\n⚖️  EXCHANGE CALIBRATION - %s (MAE %.3f, RMS %.3f)\n", key, report.MeanAbsoluteError, report.RootMeanSquare)
+		fmt.Printf("%-20s %7s %9s %7s\n", "Team", "Mark", "Exchange", "Diff")
+		for _, entry := range report.Entries {
+			fmt.Printf("%-20s %7.3f %9.3f %+7.3f\n", truncateString(entry.Team, 20), entry.Mark, entry.ExchangeProbability, entry.Difference)
+		}
+	}
+}
+
+// saveResultToFile writes a run's result as JSON for a later -compare, byte-stable when deterministic
+func saveResultToFile(result *outrightsmle.MultiLeagueResult, filename string, deterministic bool) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if deterministic {
+		data, err = outrightsmle.MarshalDeterministicJSON(result)
+	}
+	if err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("writing file %s: %w", filename, err)
+	}
+	return nil
+}
+
+// exportPathSamples writes a sample of each listed league's simulation paths to its file, as CSV
+// for a .csv file and JSON otherwise; spec is "LEAGUE=file" pairs separated by commas
+func exportPathSamples(result *outrightsmle.MultiLeagueResult, spec string, n int) error {
+	for _, entry := range strings.Split(spec, ",") {
+		league, filename, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || league == "" || filename == "" {
+			return fmt.Errorf("invalid path export %q (expected LEAGUE=file)", entry)
+		}
+		sample, err := outrightsmle.SamplePaths(result, league, n)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(strings.ToLower(filename), ".csv") {
+			file, err := os.Create(filename)
+			if err != nil {
+				return fmt.Errorf("writing file %s: %w", filename, err)
+			}
+			err = errors.Join(sample.WriteCSV(file), file.Close())
+			if err != nil {
+				return fmt.Errorf("writing file %s: %w", filename, err)
+			}
+		} else {
+			data, err := json.MarshalIndent(sample, "", "  ")
+			if err != nil {
+				return fmt.Errorf("encoding JSON: %w", err)
+			}
+			if err := os.WriteFile(filename, data, 0644); err != nil {
+				return fmt.Errorf("writing file %s: %w", filename, err)
+			}
+		}
+		fmt.Printf("💾 Wrote %d %s simulation paths to %s\n", len(sample.Paths), league, filename)
+	}
+	return nil
+}
+
+// runPostmortem scores the model's predictions at each checkpoint of a completed season and prints
+// the report card; verbose adds every team's predicted and actual finish
+func runPostmortem(events []outrightsmle.MatchResult, marketList []outrightsmle.Market, options outrightsmle.MLEOptions, handicaps map[string]int, season, checkpointSpec, leagues string, verbose bool) error {
+	postmortem := outrightsmle.PostmortemOptions{Season: season, Leagues: splitList(leagues)}
+	for _, entry := range strings.Split(checkpointSpec, ",") {
+		name, date, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		if name == "" {
+			return fmt.Errorf("invalid checkpoint %q (expected NAME or NAME=YYYY-MM-DD)", entry)
+		}
+		postmortem.Checkpoints = append(postmortem.Checkpoints, outrightsmle.Checkpoint{Name: name, Date: date})
+	}
+	report, err := outrightsmle.RunPostmortem(events, marketList, options, handicaps, postmortem)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📋 Postmortem for season %s\n", report.Season)
+	for _, checkpoint := range report.Checkpoints {
+		var leagues []string
+		for league := range checkpoint.Leagues {
+			leagues = append(leagues, league)
+		}
+		sort.Strings(leagues)
+
+		fmt.Printf("\n%s (as of %s)\n", checkpoint.Name, checkpoint.Date)
+		fmt.Printf("   %-6s %8s %7s %8s %8s %8s  %s\n", "League", "Log loss", "RPS", "Pts MAE", "Pos MAE", "Rank ρ", "Champion")
+		for _, league := range leagues {
+			score := checkpoint.Leagues[league]
+			fmt.Printf("   %-6s %8.3f %7.4f %8.2f %8.2f %8.3f  %s (%.1f%%)\n", league, score.LogLoss, score.RankedProbabilityScore,
+				score.PointsMAE, score.PositionMAE, score.RankCorrelation, score.Champion, 100*score.ChampionProbability)
+		}
+		for _, league := range leagues {
+			score := checkpoint.Leagues[league]
+			var markets []string
+			for market := range score.Markets {
+				markets = append(markets, market)
+			}
+			sort.Strings(markets)
+			for i, market := range markets {
+				markets[i] = fmt.Sprintf("%s %.4f", market, score.Markets[market].Brier)
+			}
+			if len(markets) > 0 {
+				fmt.Printf("   %s market Brier: %s\n", league, strings.Join(markets, ", "))
+			}
+			if !verbose {
+				continue
+			}
+			fmt.Printf("   %s: %-20s %6s %8s %6s %8s %8s\n", league, "Team", "Actual", "Expected", "Points", "Expected", "P(actual)")
+			for _, team := range score.Teams {
+				fmt.Printf("        %-20s %6d %8.2f %6d %8.1f %8.3f\n", team.Team, team.ActualPosition, team.ExpectedPosition,
+					team.ActualPoints, team.ExpectedPoints, team.PositionProbability)
+			}
+		}
+	}
+	return nil
+}
+
+// saveParamsToFile fits ratings on events and writes the MLEParams as JSON for a later -params run
+func saveParamsToFile(events []outrightsmle.MatchResult, options outrightsmle.MLEOptions, filename string) error {
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{HistoricalData: events, Options: options})
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("writing file %s: %w", filename, err)
+	}
+	return nil
+}
+
+// loadParamsFromFile reads MLEParams written by saveParamsToFile ("" loads none)
+func loadParamsFromFile(filename string) (*outrightsmle.MLEParams, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var params outrightsmle.MLEParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return &params, nil
+}
+
+// loadResultFromFile reads a result written by saveResultToFile
+func loadResultFromFile(filename string) (*outrightsmle.MultiLeagueResult, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	var result outrightsmle.MultiLeagueResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return &result, nil
+}
+
+// runCompare diffs two saved results ("before.json,after.json") and prints the moved teams
+func runCompare(files, thresholds string) error {
+	paths := strings.Split(files, ",")
+	if len(paths) != 2 {
+		return fmt.Errorf("expected two comma-separated result files, got %q", files)
+	}
+	options, err := parseCompareThresholds(thresholds)
+	if err != nil {
+		return err
+	}
+
+	before, err := loadResultFromFile(paths[0])
+	if err != nil {
+		return err
+	}
+	after, err := loadResultFromFile(paths[1])
+	if err != nil {
+		return err
+	}
+
+	displayComparison(outrightsmle.Compare(before, after, options), paths[0], paths[1])
+	return nil
+}
+
+// parseCompareThresholds overrides the default thresholds from "mark=0.02,points=1,rating=0.05"
+func parseCompareThresholds(spec string) (outrightsmle.CompareOptions, error) {
+	options := outrightsmle.DefaultCompareOptions()
+	if spec == "" {
+		return options, nil
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return options, fmt.Errorf("invalid threshold %q: expected name=value", entry)
+		}
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid threshold %q: %w", entry, err)
+		}
+		switch parts[0] {
+		case "mark":
+			options.MarkThreshold = value
+		case "points":
+			options.PointsThreshold = value
+		case "rating":
+			options.RatingThreshold = value
+		default:
+			return options, fmt.Errorf("unknown threshold %q: expected mark, points or rating", parts[0])
+		}
+	}
+	return options, nil
+}
+
+// displayComparison prints the moves between two results, grouped by league
+func displayComparison(comparison outrightsmle.Comparison, beforeFile, afterFile string) {
+	fmt.Printf("🔀 Comparing %s -> %s\n", beforeFile, afterFile)
+	if len(comparison.Moves) == 0 {
+		fmt.Printf("No teams moved by more than the thresholds\n")
+	}
+
+	league := ""
+	for _, move := range comparison.Moves {
+		if move.League != league {
+			league = move.League
+			fmt.Printf("\n%s\n", league)
+			fmt.Printf("%-20s %-16s %-20s %9s %9s %9s\n", "Team", "Field", "Market", "Before", "After", "Change")
+		}
+		fmt.Printf("%-20s %-16s %-20s %9.3f %9.3f %+9.3f\n",
+			truncateString(move.Team, 20), move.Field, truncateString(move.Market, 20), move.Before, move.After, move.Change)
+	}
+
+	for _, team := range comparison.OnlyBefore {
+		fmt.Printf("Only in %s: %s\n", beforeFile, team)
+	}
+	for _, team := range comparison.OnlyAfter {
+		fmt.Printf("Only in %s: %s\n", afterFile, team)
+	}
+}
+
+// appendSnapshotToFile appends a snapshot to a JSON lines store, creating the file on first use
+func appendSnapshotToFile(snapshot outrightsmle.MarkSnapshot, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening snapshot store %s: %w", filename, err)
+	}
+	if err := snapshot.WriteJSONLine(file); err != nil {
+		file.Close()
+		return fmt.Errorf("snapshot store %s: %w", filename, err)
+	}
+	return file.Close()
+}
+
+// loadSnapshotsFromFile reads every snapshot in a JSON lines store; a missing store holds none
+func loadSnapshotsFromFile(filename string) ([]outrightsmle.MarkSnapshot, error) {
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot store %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	snapshots, err := outrightsmle.ReadMarkSnapshots(file)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot store %s: %w", filename, err)
+	}
+	return snapshots, nil
+}
+
+// displayMarkHistory prints a "league/market/team" mark series from a snapshot store
+func displayMarkHistory(storePath, selection string) error {
+	if storePath == "" {
+		return fmt.Errorf("-mark-history needs -snapshot-store")
+	}
+	parts := strings.SplitN(selection, "/", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid selection %q: expected league/market/team", selection)
+	}
+
+	snapshots, err := loadSnapshotsFromFile(storePath)
+	if err != nil {
+		return err
+	}
+	series := outrightsmle.TeamSeries(snapshots, parts[0], parts[1], parts[2])
+	fmt.Printf("📉 %s - %s - %s (%d snapshots)\n", parts[0], parts[1], parts[2], len(series))
+	fmt.Printf("%-20s %-12s %7s %8s\n", "Timestamp", "Data", "Mark", "Change")
+	for i, point := range series {
+		change := ""
+		if i > 0 {
+			change = fmt.Sprintf("%+8.3f", point.Value-series[i-1].Value)
+		}
+		fmt.Printf("%-20s %-12s %7.3f %8s\n", point.Timestamp.Format("2006-01-02 15:04:05"), point.Fingerprint[:12], point.Value, change)
+	}
+	return nil
+}
+
+// runValidateMarkets checks a markets file against the league groups in core-data and lists every problem
+func runValidateMarkets(marketsFile string) error {
+	markets, err := loadMarketsFromFile(marketsFile)
+	if err != nil {
+		return err
+	}
+	leagueGroups, err := outrightsmle.ReadLeagueGroups(os.DirFS("core-data"), nil)
+	if err != nil {
+		return fmt.Errorf("failed to load league groups: %w", err)
+	}
+
+	var validationErrors outrightsmle.ValidationErrors
+	switch err := outrightsmle.ValidateMarkets(markets, leagueGroups); {
+	case err == nil:
+		fmt.Printf("✅ %d markets in %s are valid for %d leagues\n", len(markets), marketsFile, len(leagueGroups))
+		return nil
+	case errors.As(err, &validationErrors):
+		fmt.Printf("❌ %d of %d markets in %s are invalid:\n", len(validationErrors.Errors), len(markets), marketsFile)
+		for _, problem := range validationErrors.Errors {
+			fmt.Printf("   %s: %s\n", problem.Field, problem.Message)
+		}
+		return fmt.Errorf("%d invalid markets", len(validationErrors.Errors))
+	default:
+		return err
+	}
+}
+
+// TeamResult holds team data with league information
+type TeamResult struct {
+	League string
+	Season string
+	Team   outrightsmle.Team
+}
+
+
+// runMLEModel processes all events using the API and returns teams grouped by league
+// With saved params the fit is skipped and the season is predicted from them
+func runMLEModel(events []outrightsmle.MatchResult, markets []outrightsmle.Market, options outrightsmle.MLEOptions, handicaps map[string]int, saved *outrightsmle.MLEParams) (map[string][]TeamResult, *outrightsmle.MultiLeagueResult, error) {
+	debug := options.Debug
+
+	// Load league groups (team configurations) from core-data
+	// They list next season's teams, so as-of runs use the teams of the season in progress instead
+	var leagueGroups map[string][]string
+	if options.AsOfDate != "" {
+		if debug {
+			fmt.Printf("📅 As of %s: using latest season teams instead of league groups\n", options.AsOfDate)
+		}
+	} else if groups, err := outrightsmle.ReadLeagueGroups(os.DirFS("core-data"), outrightsmle.ExtractLeagues(events)); err != nil {
+		if debug {
+			fmt.Printf("⚠️  Could not load league groups: %v (will use latest season teams)\n", err)
+		}
+	} else {
+		leagueGroups = groups
+		if debug && len(leagueGroups) > 0 {
+			fmt.Printf("📂 Loaded league groups for %d leagues from core-data\n", len(leagueGroups))
+		}
+		if debug {
+			// Teams files for leagues with no events are skipped rather than rejected
+			discovered, _ := outrightsmle.TeamsFileLeagues(os.DirFS("core-data"))
+			for _, league := range discovered {
+				if _, loaded := leagueGroups[league]; !loaded {
+					fmt.Printf("⚠️  core-data/%s-teams.json skipped: no %s events\n", league, league)
+				}
+			}
+		}
+	}
+
+	// Use the high-level API to run MLE optimization across all leagues, or predict from saved params
+	var result *outrightsmle.MultiLeagueResult
+	var err error
+	if saved != nil {
+		result, err = outrightsmle.PredictSeason(*saved, events, leagueGroups, markets, options, handicaps)
+	} else {
+		result, err = outrightsmle.RunMLESolver(events, markets, options, handicaps, leagueGroups)
+	}
+	if err != nil {
+		// Check if this is a league groups validation error and provide helpful message
+		if errors.Is(err, outrightsmle.ErrLeagueGroupMismatch) {
+			fmt.Printf("❌ League groups configuration validation failed:\n")
+			fmt.Printf("   Teams in core-data/*-teams.json files must exist in the event data.\n")
+			fmt.Printf("   Error: %v\n", err)
+			return nil, nil, fmt.Errorf("invalid league groups configuration")
+		}
+		return nil, nil, fmt.Errorf("MLE solver failed: %w", err)
+	}
+
+	// Convert API result to demo format for display compatibility
 	teamsByLeague := make(map[string][]TeamResult)
 	
 	for league, teams := range result.Leagues {
@@ -442,90 +1881,331 @@ func displayTeamsByLeague(teamsByLeague map[string][]TeamResult, verbose bool) {
 		})
 
 		fmt.Printf("\n🏆 %s (%d teams):\n", league, len(teams))
-		fmt.Printf("%3s %-20s %5s %5s %5s %8s %8s %8s %8s %8s\n", 
-			"Pos", "Team", "Pts", "GD", "Pld", "Attack", "Defense", "λ_Home", "λ_Away", "SeasonPts")
-		fmt.Printf("%3s %-20s %5s %5s %5s %8s %8s %8s %8s %8s\n", 
-			"---", "----", "---", "--", "---", "------", "-------", "------", "------", "---------")
+		fmt.Printf("%3s %-20s %4s %3s %3s %3s %4s %4s %5s %5s %8s %8s %8s %8s %8s %6s %6s %6s %6s %4s %4s %8s\n", 
+			"Pos", "Team", "Pld", "W", "D", "L", "GF", "GA", "GD", "Pts", "Attack", "Defense", "λ_Home", "λ_Away", "SeasonPts", "ExpGF", "ExpGA", "ExpCS", "ExpPos", "Med", "Mode", "Form")
+		fmt.Printf("%3s %-20s %4s %3s %3s %3s %4s %4s %5s %5s %8s %8s %8s %8s %8s %6s %6s %6s %6s %4s %4s %8s\n", 
+			"---", "----", "---", "-", "-", "-", "--", "--", "--", "---", "------", "-------", "------", "------", "---------", "-----", "-----", "-----", "------", "---", "----", "----")
+
+		for i, teamResult := range teams {
+			team := teamResult.Team
+			form := ""
+			if team.Form != nil {
+				form = team.Form.Results
+			}
+			cleanSheets := 0.0
+			if team.CleanSheets != nil {
+				cleanSheets = team.CleanSheets.Expected
+			}
+			fmt.Printf("%3d %-20s %4d %3d %3d %3d %4d %4d %5d %5d %8.3f %8.3f %8.2f %8.2f %8.1f %6.1f %6.1f %6.1f %6.1f %4d %4d %8s\n",
+				i+1, // Position index starting from 1
+				team.Name,
+				team.Played,
+				team.Won,
+				team.Drawn,
+				team.Lost,
+				team.GoalsFor,
+				team.GoalsAgainst,
+				team.GoalDifference,
+				team.Points,
+				team.AttackRating,
+				team.DefenseRating,
+				team.LambdaHome,
+				team.LambdaAway,
+				team.ExpectedSeasonPoints,
+				team.ExpectedSeasonGoalsFor,
+				team.ExpectedSeasonGoalsAgainst,
+				cleanSheets,
+				team.ExpectedPosition,
+				team.MedianPosition,
+				team.ModalPosition,
+				form,
+			)
+		}
+
+		if verbose {
+			displayGoalsVsAverage(teams)
+			displayScenarios(teams)
+		}
+	}
+}
+
+// displayScenarios prints each team's best, median and worst simulated season
+func displayScenarios(teams []TeamResult) {
+	fmt.Printf("\n🎲 Season scenarios (points, position):\n")
+	fmt.Printf("%-20s", "Team")
+	if len(teams) > 0 {
+		for _, scenario := range teams[0].Team.Scenarios {
+			fmt.Printf(" %16s", fmt.Sprintf("%dth pct", scenario.Percentile))
+		}
+	}
+	fmt.Println()
+	for _, teamResult := range teams {
+		if len(teamResult.Team.Scenarios) == 0 {
+			continue
+		}
+		fmt.Printf("%-20s", teamResult.Team.Name)
+		for _, scenario := range teamResult.Team.Scenarios {
+			fmt.Printf(" %16s", fmt.Sprintf("%d pts, %s", scenario.Points, ordinal(scenario.Position)))
+		}
+		fmt.Println()
+	}
+}
+
+// ordinal formats a position as 1st, 2nd, 3rd, 4th, ...
+func ordinal(position int) string {
+	suffix := "th"
+	switch {
+	case position%100 >= 11 && position%100 <= 13:
+	case position%10 == 1:
+		suffix = "st"
+	case position%10 == 2:
+		suffix = "nd"
+	case position%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(position) + suffix
+}
+
+// displayGoalsVsAverage prints each team's expected goals per match against a league-average opponent
+func displayGoalsVsAverage(teams []TeamResult) {
+	fmt.Printf("\n⚽ Expected goals per match vs an average opponent:\n")
+	fmt.Printf("%-20s %7s %7s %7s %7s %7s\n", "Team", "HomeGF", "HomeGA", "AwayGF", "AwayGA", "Net")
+	for _, teamResult := range teams {
+		goals := teamResult.Team.VsAverage
+		if goals == nil {
+			continue
+		}
+		fmt.Printf("%-20s %7.2f %7.2f %7.2f %7.2f %+7.2f\n", teamResult.Team.Name,
+			goals.HomeFor, goals.HomeAgainst, goals.AwayFor, goals.AwayAgainst, goals.GoalsAboveAverage)
+	}
+}
+
+// displayMarkTables outputs mark value tables to console, sorted by expected season points
+// Shows fair odds in oddsFormat instead of probabilities when the result carries them
+func displayMarkTables(result *outrightsmle.MultiLeagueResult, oddsFormat string) {
+	// Get leagues dynamically from the results
+	var leagues []string
+	for league := range result.Leagues {
+		leagues = append(leagues, league)
+	}
+	// Sort for consistent output order
+	sort.Strings(leagues)
+	
+	for _, league := range leagues {
+		teams, hasTeams := result.Leagues[league]
+		markValues, hasMarkValues := result.MarkValues[league]
+		
+		if !hasTeams || !hasMarkValues || len(markValues) == 0 {
+			continue
+		}
+		formatValue := func(value float64) string { return fmt.Sprintf(" %6.3f", value) }
+		if markOdds, hasOdds := result.MarkOdds[league]; hasOdds {
+			markValues = markOdds
+			formatValue = func(value float64) string { return fmt.Sprintf(" %6s", formatPrice(value, oddsFormat, "%.4g")) }
+		}
+		
+		fmt.Printf("\n📊 MARK VALUES TABLE - %s\n", league)
+		fmt.Printf("═══════════════════════════════════════════════════════════════\n")
+		displayValueTable(teams, markValues, formatValue)
+		
+		// Report the largest Monte Carlo standard error so small marks can be judged against noise
+		maxStdError := 0.0
+		for _, teamErrors := range result.MarkStdErrors[league] {
+			for _, stdError := range teamErrors {
+				maxStdError = math.Max(maxStdError, stdError)
+			}
+		}
+		fmt.Printf("Max Monte Carlo std error: %.4f\n", maxStdError)
+		fmt.Printf("═══════════════════════════════════════════════════════════════\n")
+		
+		// Bookable prices sit alongside the fair marks, market for market
+		if bookPrices, hasBook := result.BookPrices[league]; hasBook {
+			fmt.Printf("\n📗 BOOK PRICES TABLE - %s\n", league)
+			fmt.Printf("═══════════════════════════════════════════════════════════════\n")
+			displayValueTable(teams, bookPrices, func(value float64) string {
+				if value == 0 {
+					return fmt.Sprintf(" %6s", "-") // Not offered: the model gives it no chance
+				}
+				return fmt.Sprintf(" %6s", formatPrice(value, oddsFormat, "%.4g"))
+			})
+			fmt.Printf("═══════════════════════════════════════════════════════════════\n")
+		}
+	}
+}
+
+// leagueOnlyResult wraps a streamed league result so the mark table display can render it
+func leagueOnlyResult(league outrightsmle.LeagueResult) *outrightsmle.MultiLeagueResult {
+	result := &outrightsmle.MultiLeagueResult{
+		Leagues:       map[string][]outrightsmle.Team{league.League: league.Teams},
+		MarkValues:    map[string]map[string]map[string]float64{league.League: league.MarkValues},
+		MarkStdErrors: map[string]map[string]map[string]float64{league.League: league.MarkStdErrors},
+	}
+	if league.MarkOdds != nil {
+		result.MarkOdds = map[string]map[string]map[string]float64{league.League: league.MarkOdds}
+	}
+	if league.BookPrices != nil {
+		result.BookPrices = map[string]map[string]map[string]float64{league.League: league.BookPrices}
+	}
+	return result
+}
+
+// displayValueTable prints one row per team and one column per market
+func displayValueTable(teams []outrightsmle.Team, values map[string]map[string]float64, formatValue func(float64) string) {
+	// Get market names for table headers
+	var markets []string
+	for marketName := range values {
+		markets = append(markets, marketName)
+	}
+	sort.Strings(markets)
+	
+	// Print header row
+	fmt.Printf("%-13s %7s", "Team", "ExpPts")
+	for _, market := range markets {
+		fmt.Printf(" %6s", compactMarketName(market))
+	}
+	fmt.Printf("\n")
+	
+	// Print separator
+	fmt.Printf("%-13s %7s", "─────────────", "───────")
+	for range markets {
+		fmt.Printf(" %6s", "──────")
+	}
+	fmt.Printf("\n")
+	
+	// Print data rows (teams already sorted by expected season points)
+	for _, team := range teams {
+		fmt.Printf("%-13s %7.1f", truncateString(team.Name, 13), team.ExpectedSeasonPoints)
+		
+		for _, market := range markets {
+			if teamValues, exists := values[market]; exists {
+				if value, exists := teamValues[team.Name]; exists {
+					fmt.Print(formatValue(value))
+				} else {
+					fmt.Printf(" %6s", "")  // Blank for teams not in this market
+				}
+			} else {
+				fmt.Printf(" %6s", "")  // Blank if market doesn't exist
+			}
+		}
+		fmt.Printf("\n")
+	}
+}
+
+// displayEdgeReports prints model marks against offered prices per league, best edge first
+func displayEdgeReports(reports map[string]outrightsmle.EdgeReport, oddsFormat string) {
+	var leagues []string
+	for league := range reports {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
+
+	for _, league := range leagues {
+		fmt.Printf("\n📈 EDGE REPORT - %s\n", league)
+		fmt.Printf("%-20s %-20s %7s %7s %7s %7s %7s %7s %7s\n", "Market", "Team", "Prob", "Fair", "Ladder", "Offer", "Edge", "Place", "EWEdge")
+		for _, entry := range reports[league].Entries {
+			ladder := ""
+			if entry.LadderPrice > 0 {
+				ladder = formatPrice(entry.LadderPrice, oddsFormat, "%.2f")
+			}
+			fmt.Printf("%-20s %-20s %7.3f %7s %7s %7s %+6.1f%%",
+				truncateString(entry.Market, 20), truncateString(entry.Team, 20), entry.Probability,
+				formatPrice(entry.FairPrice, oddsFormat, "%.2f"), ladder,
+				formatPrice(entry.OfferedPrice, oddsFormat, "%.2f"), entry.Edge*100)
+			if entry.PlaceProbability > 0 {
+				fmt.Printf(" %7.3f %+6.1f%%", entry.PlaceProbability, entry.EachWayEdge*100)
+			}
+			fmt.Printf("\n")
+		}
+	}
+}
+
+// displayExposure prints the book's simulated P&L and worst scenarios per league
+func displayExposure(reports map[string]outrightsmle.ExposureReport) {
+	var leagues []string
+	for league := range reports {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
+
+	for _, league := range leagues {
+		report := reports[league]
+		fmt.Printf("\n🧾 BOOK EXPOSURE - %s (%d positions, staked %.2f)\n", league, report.Positions, report.TotalStake)
+		fmt.Printf("Expected P&L: %+.2f  StdDev: %.2f  VaR95: %+.2f  Worst: %+.2f\n",
+			report.ExpectedPnL, report.StdDev, report.ValueAtRisk95, report.WorstCase)
+		for _, scenario := range report.WorstScenarios {
+			fmt.Printf("  %+10.2f  %s\n", scenario.PnL, scenario.FormatPayouts())
+		}
+	}
+}
+
+// displayKellyStakes prints recommended Kelly stakes for priced markets
+func displayKellyStakes(stakes []outrightsmle.KellyStake, oddsFormat string) {
+	fmt.Printf("\n💰 Kelly Stakes\n")
+	fmt.Printf("==============\n")
+	if len(stakes) == 0 {
+		fmt.Printf("No positive-edge bets (markets need \"prices\" to be staked)\n")
+		return
+	}
+
+	fmt.Printf("%-5s %-20s %-20s %7s %7s %7s %9s\n", "Lg", "Market", "Team", "Prob", "Price", "Kelly", "Stake")
+	for _, stake := range stakes {
+		fmt.Printf("%-5s %-20s %-20s %7.3f %7s %7.3f %9.2f\n",
+			stake.League, truncateString(stake.Market, 20), truncateString(stake.Team, 20),
+			stake.Probability, formatPrice(stake.Price, oddsFormat, "%.2f"), stake.KellyFraction, stake.Stake)
+	}
+}
+
+// formatPrice renders decimal odds in oddsFormat, using decimalFormat for decimal odds so
+// tables keep their column widths
+func formatPrice(decimal float64, oddsFormat, decimalFormat string) string {
+	if oddsFormat == outrightsmle.OddsFormatDecimal {
+		return fmt.Sprintf(decimalFormat, decimal)
+	}
+	price, err := outrightsmle.FormatOdds(decimal, oddsFormat)
+	if err != nil {
+		return "?"
+	}
+	return price
+}
+
+// displayTimings prints the phase-level timing breakdown of a run
+func displayTimings(result *outrightsmle.MultiLeagueResult, fileLoadTime time.Duration) {
+	timings := result.Timings
+
+	var leagues []string
+	for league := range timings.Simulation {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
 
-		for i, teamResult := range teams {
-			team := teamResult.Team
-			fmt.Printf("%3d %-20s %5d %5d %5d %8.3f %8.3f %8.2f %8.2f %8.1f\n",
-				i+1, // Position index starting from 1
-				team.Name,
-				team.Points,
-				team.GoalDifference,
-				team.Played,
-				team.AttackRating,
-				team.DefenseRating,
-				team.LambdaHome,
-				team.LambdaAway,
-				team.ExpectedSeasonPoints,
-			)
+	fmt.Printf("\n⏱️  Timing Breakdown\n")
+	fmt.Printf("==================\n")
+	fmt.Printf("%-22s %12v\n", "File load", fileLoadTime.Round(time.Microsecond))
+	fmt.Printf("%-22s %12v\n", "Validation/processing", timings.Load.Round(time.Microsecond))
+	fmt.Printf("%-22s %12v\n", "Optimization", timings.Optimize.Round(time.Microsecond))
+	for _, league := range leagues {
+		fmt.Printf("%-22s %12v\n", "Simulation "+league, timings.Simulation[league].Round(time.Microsecond))
+		if marks, exists := timings.Markets[league]; exists {
+			fmt.Printf("%-22s %12v\n", "Markets "+league, marks.Round(time.Microsecond))
 		}
 	}
+	fmt.Printf("%-22s %12v\n", "Total (solver)", result.ProcessingTime.Round(time.Microsecond))
 }
 
-// displayMarkTables outputs mark value tables to console, sorted by expected season points
-func displayMarkTables(result *outrightsmle.MultiLeagueResult) {
-	// Get leagues dynamically from the results
+// displayDiagnostics prints what went into each league's simulation
+func displayDiagnostics(result *outrightsmle.MultiLeagueResult) {
 	var leagues []string
-	for league := range result.Leagues {
+	for league := range result.Diagnostics {
 		leagues = append(leagues, league)
 	}
-	// Sort for consistent output order
 	sort.Strings(leagues)
-	
+
+	fmt.Printf("\n🔬 League Diagnostics\n")
+	fmt.Printf("=====================\n")
+	fmt.Printf("%-8s %5s %7s %7s %8s %7s %6s %9s %14s\n", "League", "Teams", "Events", "Played", "Fixtures", "Paths", "Iters", "Converged", "LogLikelihood")
 	for _, league := range leagues {
-		teams, hasTeams := result.Leagues[league]
-		markValues, hasMarkValues := result.MarkValues[league]
-		
-		if !hasTeams || !hasMarkValues || len(markValues) == 0 {
-			continue
-		}
-		
-		fmt.Printf("\n📊 MARK VALUES TABLE - %s\n", league)
-		fmt.Printf("═══════════════════════════════════════════════════════════════\n")
-		
-		// Get market names for table headers
-		var markets []string
-		for marketName := range markValues {
-			markets = append(markets, marketName)
-		}
-		sort.Strings(markets)
-		
-		// Print header row
-		fmt.Printf("%-13s %7s", "Team", "ExpPts")
-		for _, market := range markets {
-			fmt.Printf(" %6s", compactMarketName(market))
-		}
-		fmt.Printf("\n")
-		
-		// Print separator
-		fmt.Printf("%-13s %7s", "─────────────", "───────")
-		for range markets {
-			fmt.Printf(" %6s", "──────")
-		}
-		fmt.Printf("\n")
-		
-		// Print data rows (teams already sorted by expected season points)
-		for _, team := range teams {
-			fmt.Printf("%-13s %7.1f", truncateString(team.Name, 13), team.ExpectedSeasonPoints)
-			
-			for _, market := range markets {
-				if teamMarks, exists := markValues[market]; exists {
-					if markValue, exists := teamMarks[team.Name]; exists {
-						fmt.Printf(" %6.3f", markValue)
-					} else {
-						fmt.Printf(" %6s", "")  // Blank for teams not in this market
-					}
-				} else {
-					fmt.Printf(" %6s", "")  // Blank if market doesn't exist
-				}
-			}
-			fmt.Printf("\n")
-		}
-		
-		fmt.Printf("═══════════════════════════════════════════════════════════════\n")
+		d := result.Diagnostics[league]
+		fmt.Printf("%-8s %5d %7d %7d %8d %7d %6d %9v %14.2f\n", league, d.Teams, d.Events, d.SeasonEvents, d.Fixtures, d.SimulationPaths, d.Iterations, d.Converged, d.LogLikelihood)
 	}
 }
 
@@ -580,3 +2260,527 @@ func truncateString(s string, maxLen int) string {
 }
 
 // getMapKeys returns the keys of a string map
+
+// displayRatingTrajectory shows attack/defense ratings for each team at each as-of date
+func displayRatingTrajectory(trajectory *outrightsmle.RatingTrajectory, teams []string) {
+	if len(teams) == 0 {
+		for team := range trajectory.Teams {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+	}
+
+	fmt.Printf("\n📈 Rating Trajectory (attack/defense):\n")
+	fmt.Printf("%-20s", "Team")
+	for _, date := range trajectory.Dates {
+		fmt.Printf(" %13s", date)
+	}
+	fmt.Println()
+
+	for _, team := range teams {
+		points, exists := trajectory.Teams[team]
+		if !exists {
+			fmt.Printf("%-20s (no matches)\n", team)
+			continue
+		}
+		byDate := make(map[string]outrightsmle.RatingPoint)
+		for _, point := range points {
+			byDate[point.Date] = point
+		}
+		fmt.Printf("%-20s", team)
+		for _, date := range trajectory.Dates {
+			if point, ok := byDate[date]; ok {
+				fmt.Printf(" %6.2f/%-6.2f", point.Attack, point.Defense)
+			} else {
+				fmt.Printf(" %13s", "-")
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// displayLeagueChanges lists promotions and relegations (latest season only unless verbose)
+func displayLeagueChanges(changes []outrightsmle.LeagueChange, verbose bool) {
+	var shown []outrightsmle.LeagueChange
+	for _, change := range changes {
+		if verbose || change.IntoLatestSeason {
+			shown = append(shown, change)
+		}
+	}
+	if len(shown) == 0 {
+		return
+	}
+
+	fmt.Printf("\n🔄 League Changes:\n")
+	fmt.Printf("%-8s %-20s %-6s %-6s %s\n", "Season", "Team", "From", "To", "Direction")
+	for _, change := range shown {
+		icon := "📉"
+		if change.Direction == "promoted" {
+			icon = "📈"
+		}
+		fmt.Printf("%-8s %-20s %-6s %-6s %s %s\n", change.Season, change.Team, change.FromLeague, change.ToLeague, icon, change.Direction)
+	}
+}
+
+// displayRatingOverrides lists the manual rating overrides with the fitted ratings they replaced
+func displayRatingOverrides(applied []outrightsmle.AppliedRatingOverride) {
+	if len(applied) == 0 {
+		return
+	}
+
+	fmt.Printf("\n✏️  Rating Overrides:\n")
+	fmt.Printf("%-20s %15s %15s %s\n", "Team", "Attack", "Defense", "Reason")
+	for _, override := range applied {
+		fmt.Printf("%-20s %6.3f → %6.3f %6.3f → %6.3f %s\n", override.Team,
+			override.FittedAttack, override.Attack, override.FittedDefense, override.Defense, override.Reason)
+	}
+}
+
+// displayMatchOdds prints each league's remaining-fixture odds, the first 10 unless verbose
+func displayMatchOdds(matchOdds map[string][]outrightsmle.MatchOdds, verbose bool) {
+	var leagues []string
+	for league := range matchOdds {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
+
+	for _, league := range leagues {
+		fixtures := matchOdds[league]
+		fmt.Printf("\n🎯 %s remaining fixtures (%d):\n", league, len(fixtures))
+		for i, odds := range fixtures {
+			if i == 10 && !verbose {
+				fmt.Printf("   ... %d more (use -verbose to list all)\n", len(fixtures)-i)
+				break
+			}
+			fmt.Printf("%-40s H %.3f  D %.3f  A %.3f\n", odds.Fixture, odds.Probabilities[0], odds.Probabilities[1], odds.Probabilities[2])
+		}
+	}
+}
+
+// parseRangeQuery parses "Team:a-b" or "Team:a+" into a team and inclusive bounds
+func parseRangeQuery(query string) (string, int, int, error) {
+	separator := strings.LastIndex(query, ":")
+	if separator < 0 {
+		return "", 0, 0, fmt.Errorf("query %q must be Team:a-b or Team:a+", query)
+	}
+	team, bounds := strings.TrimSpace(query[:separator]), strings.TrimSpace(query[separator+1:])
+
+	if strings.HasSuffix(bounds, "+") {
+		lower, err := strconv.Atoi(strings.TrimSuffix(bounds, "+"))
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("query %q: %w", query, err)
+		}
+		return team, lower, outrightsmle.NoUpperBound, nil
+	}
+
+	parts := strings.SplitN(bounds, "-", 2)
+	if len(parts) != 2 {
+		return "", 0, 0, fmt.Errorf("query %q must be Team:a-b or Team:a+", query)
+	}
+	lower, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("query %q: %w", query, err)
+	}
+	upper, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("query %q: %w", query, err)
+	}
+	return team, lower, upper, nil
+}
+
+// findTeamLeague returns the league a team is simulated in
+func findTeamLeague(result *outrightsmle.MultiLeagueResult, team string) string {
+	for league, teams := range result.Leagues {
+		for _, t := range teams {
+			if t.Name == team {
+				return league
+			}
+		}
+	}
+	return ""
+}
+
+// displayRangeQueries answers points band and position range queries from the retained simulations
+func displayRangeQueries(result *outrightsmle.MultiLeagueResult, pointsQueries, positionQueries string) {
+	fmt.Printf("\n🔎 Range Queries:\n")
+	run := func(queries, label string, query func(league, team string, lower, upper int) (float64, error)) {
+		if queries == "" {
+			return
+		}
+		for _, q := range strings.Split(queries, ",") {
+			team, lower, upper, err := parseRangeQuery(q)
+			if err != nil {
+				fmt.Printf("  ⚠️  %v\n", err)
+				continue
+			}
+			probability, err := query(findTeamLeague(result, team), team, lower, upper)
+			if err != nil {
+				fmt.Printf("  ⚠️  %s: %v\n", q, err)
+				continue
+			}
+			bounds := fmt.Sprintf("%d-%d", lower, upper)
+			if upper == outrightsmle.NoUpperBound {
+				bounds = fmt.Sprintf("%d+", lower)
+			}
+			fmt.Printf("  P(%s %s %s) = %.3f\n", team, label, bounds, probability)
+		}
+	}
+	run(pointsQueries, "points", func(league, team string, lower, upper int) (float64, error) {
+		return outrightsmle.PointsBandProbability(result, league, team, lower, upper)
+	})
+	run(positionQueries, "position", func(league, team string, lower, upper int) (float64, error) {
+		return outrightsmle.PositionRangeProbability(result, league, team, lower, upper)
+	})
+}
+
+// displayMatchImportance lists the fixtures whose result most swings title/promotion/relegation chances
+func displayMatchImportance(result *outrightsmle.MultiLeagueResult, limit int) {
+	leagues := make([]string, 0, len(result.Simulations))
+	for league := range result.Simulations {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
+
+	for _, league := range leagues {
+		fixtures, err := outrightsmle.CalculateMatchImportance(result, league, nil)
+		if err != nil {
+			fmt.Printf("⚠️  Match importance for %s failed: %v\n", league, err)
+			continue
+		}
+		if len(fixtures) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n⚡ Most Important Fixtures - %s:\n", league)
+		fmt.Printf("%-40s %10s  %s\n", "Fixture", "Importance", "Biggest swing (H/D/A)")
+		for i, fixture := range fixtures {
+			if i >= limit {
+				break
+			}
+			swing := ""
+			if len(fixture.Swings) > 0 {
+				top := fixture.Swings[0]
+				swing = fmt.Sprintf("%s %s %.2f/%.2f/%.2f", top.Team, top.Target, top.Home, top.Draw, top.Away)
+			}
+			fmt.Printf("%-40s %10.3f  %s\n", fixture.Fixture, fixture.Importance, swing)
+		}
+	}
+}
+
+// parseWhatIf parses "Home vs Away=home|draw|away|H-A" entries into a conditioning
+func parseWhatIf(spec string) (outrightsmle.Conditioning, error) {
+	var conditioning outrightsmle.Conditioning
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return conditioning, fmt.Errorf("entry %q must be \"Home vs Away=result\"", entry)
+		}
+		fixed := outrightsmle.FixedResult{Fixture: strings.TrimSpace(parts[0])}
+		result := strings.TrimSpace(parts[1])
+
+		if goals := strings.SplitN(result, "-", 2); len(goals) == 2 {
+			homeGoals, err := strconv.Atoi(goals[0])
+			if err != nil {
+				return conditioning, fmt.Errorf("entry %q: %w", entry, err)
+			}
+			awayGoals, err := strconv.Atoi(goals[1])
+			if err != nil {
+				return conditioning, fmt.Errorf("entry %q: %w", entry, err)
+			}
+			fixed.HomeGoals, fixed.AwayGoals = &homeGoals, &awayGoals
+		} else {
+			fixed.Outcome = result
+		}
+		conditioning.Results = append(conditioning.Results, fixed)
+	}
+	return conditioning, nil
+}
+
+// displayWhatIf shows mark tables for the leagues re-simulated under the fixed results
+func displayWhatIf(base, conditional *outrightsmle.MultiLeagueResult, conditioning outrightsmle.Conditioning, oddsFormat string) {
+	fmt.Printf("\n🔮 What-If Scenario:\n")
+	for _, fixed := range conditioning.Results {
+		result := fixed.Outcome
+		if fixed.HomeGoals != nil && fixed.AwayGoals != nil {
+			result = fmt.Sprintf("%d-%d", *fixed.HomeGoals, *fixed.AwayGoals)
+		}
+		fmt.Printf("  %s = %s\n", fixed.Fixture, result)
+	}
+
+	// Show only the leagues that were re-simulated
+	changed := *conditional
+	changed.Leagues = make(map[string][]outrightsmle.Team)
+	for league, simPoints := range conditional.Simulations {
+		if simPoints != base.Simulations[league] {
+			changed.Leagues[league] = conditional.Leagues[league]
+		}
+	}
+	displayMarkTables(&changed, oddsFormat)
+}
+
+// runTournament fits ratings on events and simulates the tournament in filename
+func runTournament(events []outrightsmle.MatchResult, simParams *outrightsmle.SimParams, renames []outrightsmle.TeamRename, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading file %s: %w", filename, err)
+	}
+	var tournament outrightsmle.Tournament
+	if err := json.Unmarshal(data, &tournament); err != nil {
+		return fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	fmt.Printf("\n🌍 Simulating %s (%d groups, %d paths)...\n", tournament.Name, len(tournament.Groups), simParams.SimulationPaths)
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{
+		HistoricalData: events,
+		Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+	})
+	if err != nil {
+		return err
+	}
+	result, err := outrightsmle.SimulateTournament(*params, simParams, tournament)
+	if err != nil {
+		return err
+	}
+
+	groups := make([]string, 0, len(result.GroupPosition))
+	for group := range result.GroupPosition {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		positions := result.GroupPosition[group]
+		teams := make([]string, 0, len(positions))
+		for team := range positions {
+			teams = append(teams, team)
+		}
+		sort.Slice(teams, func(i, j int) bool { return result.GroupWinner[group][teams[i]] > result.GroupWinner[group][teams[j]] })
+
+		fmt.Printf("\n📊 Group %s\n%-25s", group, "Team")
+		for place := range positions[teams[0]] {
+			fmt.Printf(" %6d", place+1)
+		}
+		fmt.Printf(" %7s\n", "Qualify")
+		for _, team := range teams {
+			fmt.Printf("%-25s", truncateString(team, 25))
+			for _, probability := range positions[team] {
+				fmt.Printf(" %6.3f", probability)
+			}
+			fmt.Printf(" %7.3f\n", result.Qualify[team])
+		}
+	}
+
+	fmt.Printf("\n🏆 Knockout\n")
+	displayReachTable(result.Rounds, result.Reach, result.Winner)
+	return nil
+}
+
+// runImpliedRatings fits ratings on events, backs ratings out of the fixture prices in the odds
+// file with the fit as prior, and prints both side by side for the priced teams
+func runImpliedRatings(events []outrightsmle.MatchResult, simParams *outrightsmle.SimParams, renames []outrightsmle.TeamRename, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading file %s: %w", filename, err)
+	}
+	var snapshot outrightsmle.OddsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{
+		HistoricalData: events,
+		Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+	})
+	if err != nil {
+		return err
+	}
+	market, err := outrightsmle.ImpliedRatings(snapshot.Fixtures, params, simParams)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📉 Market-Implied Ratings (%d fixtures, RMSE %.3f)\n", len(market.Fixtures), market.RMSE)
+	fmt.Printf("%-20s %8s %8s %8s %8s\n", "Team", "Attack", "Mkt Att", "Defense", "Mkt Def")
+	teams := make([]string, 0, len(market.Params.AttackRatings))
+	for team := range market.Params.AttackRatings {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	for _, team := range teams {
+		fmt.Printf("%-20s %8.3f %8.3f %8.3f %8.3f\n", truncateString(team, 20),
+			params.AttackRatings[team], market.Params.AttackRatings[team],
+			params.DefenseRatings[team], market.Params.DefenseRatings[team])
+	}
+	return nil
+}
+
+// runHeadToHead fits ratings on events and prints two teams' meetings and current prices
+func runHeadToHead(events []outrightsmle.MatchResult, simParams *outrightsmle.SimParams, renames []outrightsmle.TeamRename, teams string) error {
+	names := splitList(teams)
+	if len(names) != 2 {
+		return fmt.Errorf("invalid teams %q: expected \"TeamA,TeamB\"", teams)
+	}
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{
+		HistoricalData: events,
+		Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+	})
+	if err != nil {
+		return err
+	}
+	matches, err := outrightsmle.ApplyTeamRenames(events, renames)
+	if err != nil {
+		return err
+	}
+	record, err := outrightsmle.HeadToHead(matches, *params, simParams, names[0], names[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n⚔️  %s v %s: %d meetings\n", record.TeamA, record.TeamB, record.Stats.Matches)
+	for _, match := range record.Matches {
+		fmt.Printf("   %s %-6s %s %d-%d %s\n", match.Date, match.League, match.HomeTeam, match.HomeGoals, match.AwayGoals, match.AwayTeam)
+	}
+	fmt.Printf("%s W%d D%d L%d, goals per meeting %.2f-%.2f\n", record.TeamA,
+		record.Stats.Wins, record.Stats.Draws, record.Stats.Losses, record.AverageGoalsA, record.AverageGoalsB)
+	for _, odds := range []outrightsmle.MatchOdds{record.TeamAHome, record.TeamBHome} {
+		fmt.Printf("%-32s H %.3f  D %.3f  A %.3f\n", odds.Fixture, odds.Probabilities[0], odds.Probabilities[1], odds.Probabilities[2])
+	}
+	return nil
+}
+
+// runScoreGrid fits ratings on events and writes one fixture's correct-score grid to stdout
+func runScoreGrid(events []outrightsmle.MatchResult, simParams *outrightsmle.SimParams, renames []outrightsmle.TeamRename, fixture, format string) error {
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown score grid format %q (expected json or csv)", format)
+	}
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{
+		HistoricalData: events,
+		Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+	})
+	if err != nil {
+		return err
+	}
+	grid, err := outrightsmle.PriceScoreGrid(*params, simParams, fixture, "", false)
+	if err != nil {
+		return err
+	}
+	if format == "csv" {
+		return grid.WriteCSV(os.Stdout)
+	}
+	data, err := json.MarshalIndent(grid, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// runAsianHandicap fits ratings on events and prints the home Asian handicap prices of spec, a
+// "{Home} vs {Away}=LINE[,LINE...]" fixture and its lines
+func runAsianHandicap(events []outrightsmle.MatchResult, simParams *outrightsmle.SimParams, renames []outrightsmle.TeamRename, spec string) error {
+	fixture, lines, found := strings.Cut(spec, "=")
+	if !found || lines == "" {
+		return fmt.Errorf("invalid asian handicap %q (expected \"Home vs Away=LINE[,LINE...]\")", spec)
+	}
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{
+		HistoricalData: events,
+		Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n⚖️  Asian handicap: %s (home line)\n", fixture)
+	fmt.Printf("   %6s %7s %8s %7s %9s %7s %9s %9s\n", "Line", "Win", "Half win", "Push", "Half loss", "Loss", "Home odds", "Away odds")
+	for _, entry := range strings.Split(lines, ",") {
+		line, err := strconv.ParseFloat(strings.TrimSpace(entry), 64)
+		if err != nil {
+			return fmt.Errorf("invalid line %q: %w", entry, err)
+		}
+		handicap, err := outrightsmle.PriceAsianHandicap(*params, simParams, fixture, "", line, false)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("   %+6.2f %7.4f %8.4f %7.4f %9.4f %7.4f %9.3f %9.3f\n", handicap.Line, handicap.Win, handicap.HalfWin,
+			handicap.Push, handicap.HalfLoss, handicap.Loss, handicap.HomeOdds, handicap.AwayOdds)
+	}
+	return nil
+}
+
+// runCupDraw fits ratings on events and simulates the remaining draws of the cup in filename
+func runCupDraw(events []outrightsmle.MatchResult, simParams *outrightsmle.SimParams, renames []outrightsmle.TeamRename, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading file %s: %w", filename, err)
+	}
+	var cup outrightsmle.CupDraw
+	if err := json.Unmarshal(data, &cup); err != nil {
+		return fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	fmt.Printf("\n🏆 Simulating %s draws (%d teams, %d paths)...\n", cup.Name, len(cup.Teams), simParams.SimulationPaths)
+	params, err := outrightsmle.OptimizeRatings(outrightsmle.MLERequest{
+		HistoricalData: events,
+		Options:        outrightsmle.MLEOptions{SimParams: simParams, TeamRenames: renames},
+	})
+	if err != nil {
+		return err
+	}
+	result, err := outrightsmle.SimulateCupDraw(*params, simParams, cup)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	displayReachTable(result.Rounds, result.Reach, result.Winner)
+	return nil
+}
+
+// displayReachTable prints each team's chance of reaching every knockout round and winning,
+// strongest first
+func displayReachTable(rounds []string, reach map[string]map[string]float64, winner map[string]float64) {
+	teams := make([]string, 0, len(winner))
+	for team := range winner {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return winner[teams[i]] > winner[teams[j]] })
+	fmt.Printf("%-25s", "Team")
+	for _, round := range rounds {
+		fmt.Printf(" %14s", truncateString(round, 14))
+	}
+	fmt.Printf(" %7s\n", "Winner")
+	for _, team := range teams {
+		fmt.Printf("%-25s", truncateString(team, 25))
+		for _, round := range rounds {
+			fmt.Printf(" %14.3f", reach[round][team])
+		}
+		fmt.Printf(" %7.3f\n", winner[team])
+	}
+}
+
+// runTuner searches the default tuning space and prints the best candidates
+func runTuner(events []outrightsmle.MatchResult, base *outrightsmle.SimParams, samples, folds int) {
+	fmt.Printf("\n🎛️  Tuning hyperparameters (%d walk-forward folds)...\n", folds)
+	start := time.Now()
+	result, err := outrightsmle.TuneHyperparameters(events, base, outrightsmle.TuneOptions{
+		Space:         outrightsmle.DefaultTuningSpace(),
+		RandomSamples: samples,
+		Folds:         folds,
+	})
+	if err != nil {
+		log.Fatalf("Tuning failed: %v", err)
+	}
+	fmt.Printf("✓ Scored %d candidates in %v\n\n", len(result.Candidates), time.Since(start).Round(time.Millisecond))
+
+	fmt.Printf("%4s %9s %9s %9s %9s %9s %9s\n", "Rank", "LogLoss", "DecayBase", "DecayPow", "LR", "Rho", "HomeAdv")
+	for i, candidate := range result.Candidates {
+		if i >= 10 {
+			break
+		}
+		p := candidate.Params
+		fmt.Printf("%4d %9.5f %9.2f %9.2f %9.4f %9.2f %9.2f\n", i+1, candidate.LogLoss,
+			p.TimeDecayBase, p.TimeDecayPower, p.BaseLearningRate, p.Rho, p.HomeAdvantage)
+	}
+
+	best := result.Best
+	fmt.Printf("\n💡 Best: -time-decay-base %g -time-decay-factor %g -learning-rate-base %g -rho %g -home-advantage %g\n",
+		best.TimeDecayBase, best.TimeDecayPower, best.BaseLearningRate, best.Rho, best.HomeAdvantage)
+}
@@ -1,3 +1,7 @@
 module github.com/jhw/go-outrights-mle
 
 go 1.24.5
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require gonum.org/v1/gonum v0.16.0
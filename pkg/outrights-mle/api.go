@@ -3,8 +3,10 @@ package outrightsmle
 import (
 	"fmt"
 	"math"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,6 +14,14 @@ import (
 // This is the main entry point for the outrights-mle package
 func RunSimulation(request MLERequest) (*MLEResult, error) {
 	startTime := time.Now()
+	fingerprint := newDataFingerprint(request.HistoricalData, nil, request.Handicaps)
+
+	// Truncate to the as-of date and apply renames before anything is derived from the data
+	matches, err := ingestMatches(request.HistoricalData, request.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	request.HistoricalData = matches
 
 	// Validate input
 	if err := validateRequest(request); err != nil {
@@ -19,8 +29,8 @@ func RunSimulation(request MLERequest) (*MLEResult, error) {
 	}
 
 	// Apply defaults if not provided
-	if request.Options == (MLEOptions{}) {
-		request.Options = DefaultMLEOptions()
+	if request.Options.SimParams == nil {
+		request.Options.SimParams = DefaultSimParams()
 	}
 
 	// Initialize MLE solver with historical data
@@ -31,24 +41,30 @@ func RunSimulation(request MLERequest) (*MLEResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("MLE optimization failed: %w", err)
 	}
-
-	// Extract team ratings into Team objects (with empty league table fields)
-	teams := make([]Team, 0, len(params.AttackRatings))
-	for teamName := range params.AttackRatings {
-		team := Team{
-			Name:                 teamName,
-			Points:               0,  // No league table data at this level
-			GoalDifference:       0,  // No league table data at this level  
-			Played:               0,  // No league table data at this level
-			AttackRating:         params.AttackRatings[teamName],
-			DefenseRating:        params.DefenseRatings[teamName],
-			LambdaHome:           math.Exp(params.AttackRatings[teamName] + params.HomeAdvantage),  // attack + home advantage
-			LambdaAway:           math.Exp(params.AttackRatings[teamName]),                         // just attack
-			ExpectedSeasonPoints: 0,  // Will be calculated later at league level
+	if err := checkConvergence(params, request.Options); err != nil {
+		return nil, err
+	}
+	
+	// Mix in any external ratings, so fixtures and simulations use the blended set
+	if request.Options.RatingsBlend != nil {
+		params, err = request.Options.RatingsBlend.apply(params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
 		}
-		teams = append(teams, team)
+		solver.params = params
 	}
 
+	// Manual overrides, then availability multipliers, shift the ratings used from here on,
+	// leaving the fit untouched
+	if len(request.Options.RatingOverrides) > 0 || len(request.Options.LambdaMultipliers) > 0 {
+		params = applyRatingOverrides(params, request.Options.RatingOverrides)
+		params = applyLambdaMultipliers(params, request.Options.LambdaMultipliers)
+		solver.params = params
+	}
+
+	// Extract team ratings into Team objects (with empty league table fields)
+	teams := teamsFromParams(params)
+
 	// Generate match odds per league for current season teams only
 	matchOdds := generateFixturesPerLeague(teams, solver, request)
 
@@ -58,11 +74,35 @@ func RunSimulation(request MLERequest) (*MLEResult, error) {
 		MLEParams:        *params,
 		ProcessingTime:   time.Since(startTime),
 		MatchesProcessed: len(request.HistoricalData),
+		Fingerprint:      fingerprint,
+	}
+	if request.Options.Deterministic {
+		result.ProcessingTime = 0
 	}
 
 	return result, nil
 }
 
+// teamsFromParams builds Team objects from fitted ratings (league table fields are left empty)
+func teamsFromParams(params *MLEParams) []Team {
+	teams := make([]Team, 0, len(params.AttackRatings))
+	for _, teamName := range sortedKeys(params.AttackRatings) { // By name, so downstream sums run in a fixed order
+		team := Team{
+			Name:                 teamName,
+			Points:               0,  // No league table data at this level
+			GoalDifference:       0,  // No league table data at this level  
+			Played:               0,  // No league table data at this level
+			AttackRating:         params.AttackRatings[teamName],
+			DefenseRating:        params.DefenseRatings[teamName],
+			LambdaHome:           math.Exp(params.teamIntercept(teamName) + params.AttackRatings[teamName] + params.HomeAdvantage),  // intercept + attack + home advantage
+			LambdaAway:           math.Exp(params.teamIntercept(teamName) + params.AttackRatings[teamName]),                         // intercept + attack
+			ExpectedSeasonPoints: 0,  // Will be calculated later at league level
+		}
+		teams = append(teams, team)
+	}
+	return teams
+}
+
 
 
 
@@ -71,20 +111,171 @@ type MultiLeagueResult struct {
 	Leagues       map[string][]Team                          `json:"leagues"`        // league -> teams with all data
 	Markets       []Market                                   `json:"markets"`        // validated and initialized markets
 	MarkValues    map[string]map[string]map[string]float64   `json:"mark_values"`    // league -> market -> team -> mark_value
+	MarkStdErrors map[string]map[string]map[string]float64   `json:"mark_std_errors"` // league -> market -> team -> Monte Carlo standard error
+	PlaceValues   map[string]map[string]map[string]float64   `json:"place_values,omitempty"` // league -> each-way market -> team -> place probability
+	MarkOdds      map[string]map[string]map[string]float64   `json:"mark_odds,omitempty"` // league -> market -> team -> fair decimal odds (SimParams.DecimalOdds)
+	BookPrices    map[string]map[string]map[string]float64   `json:"book_prices,omitempty"` // league -> market -> team -> bookable price (SimParams.OutrightOverround)
+	EdgeReports   map[string]EdgeReport                      `json:"edge_reports,omitempty"` // league -> marks vs offered prices (priced markets only)
+	LeagueChanges []LeagueChange                             `json:"league_changes"` // promotions/relegations detected in the event data
+	RatingOverrides []AppliedRatingOverride                  `json:"rating_overrides,omitempty"` // manual overrides applied to the fitted ratings (MLEOptions.RatingOverrides)
+	Fingerprint   DataFingerprint                            `json:"fingerprint"`    // digests of the events, markets and handicaps the run was given
+	MatchOdds     map[string][]MatchOdds                     `json:"match_odds,omitempty"` // league -> remaining-fixture odds (MLEOptions.IncludeMatchOdds)
+	MLEParams     MLEParams                                  `json:"mle_params"`     // fitted (or saved) parameters the leagues were simulated with
+	Diagnostics   map[string]LeagueDiagnostics               `json:"diagnostics"`    // league -> what went into its simulation
 	LatestSeason  string                                     `json:"latest_season"`  
 	TotalMatches  int                                        `json:"total_matches"`
 	ProcessingTime time.Duration                             `json:"processing_time"`
+	Timings       TimingBreakdown                            `json:"timings"`        // phase-level timing diagnostics
+	Simulations   map[string]*SimPoints                      `json:"-"`              // league -> season simulation, for post-hoc analysis
+	
+	simInputs *leagueSimInputs // Fitted inputs retained so the simulation stage can be re-run
+}
+
+// TimingBreakdown records where a RunMLESolver call spent its time
+type TimingBreakdown struct {
+	Load       time.Duration            `json:"load"`       // Validation, event processing and market initialization
+	Optimize   time.Duration            `json:"optimize"`   // MLE fit and fixture pricing
+	Simulation map[string]time.Duration `json:"simulation"` // league -> season simulation
+	Markets    map[string]time.Duration `json:"markets"`    // league -> mark value calculation
+}
+
+// LeagueDiagnostics records what one league's simulation was built from
+// The fit fields come from the league's own fit when MLEOptions.IndependentLeagues is set, and
+// from the shared fit otherwise
+type LeagueDiagnostics struct {
+	Teams           int           `json:"teams"`            // Teams simulated
+	Events          int           `json:"events"`           // League matches in the data, all seasons
+	SeasonEvents    int           `json:"season_events"`    // Current season matches already played
+	Fixtures        int           `json:"fixtures"`         // Remaining fixtures simulated
+	SimulationPaths int           `json:"simulation_paths"`
+	SimulationTime  time.Duration `json:"simulation_time"`
+	Iterations      int           `json:"iterations"`
+	Converged       bool          `json:"converged"`
+	LogLikelihood   float64       `json:"log_likelihood"`
 }
 
 // RunMLESolver runs MLE optimization across all leagues and returns organized results
+// Safe for concurrent use: each call works on its own copies of events and markets, and every
+// solver and simulation owns its state and random source
 // This is the main high-level API for cross-league MLE optimization
-func RunMLESolver(events []MatchResult, markets []Market, options MLEOptions, handicaps map[string]int) (*MultiLeagueResult, error) {
+// leagueGroups (league -> teams) is optional; when nil, latest season teams are used per league
+func RunMLESolver(events []MatchResult, markets []Market, options MLEOptions, handicaps map[string]int, leagueGroups map[string][]string) (*MultiLeagueResult, error) {
+	run, err := prepareLeagueRun(events, markets, options, handicaps, leagueGroups)
+	if err != nil {
+		return nil, err
+	}
+	options, events, cupEvents, leagues, currentTeams, result := run.options, run.events, run.cupEvents, run.leagues, run.currentTeams, run.result
+	
+	// Supplied ratings take the place of the fit
+	if options.SuppliedRatings != nil {
+		params, err := suppliedParams(options.SuppliedRatings, options.SimParams)
+		if err != nil {
+			return nil, err
+		}
+		return run.predict(params, "supplied ratings")
+	}
+	
+	if options.Debug && !options.IndependentLeagues {
+		fmt.Printf("\n🏈 Running single MLE optimization across ALL leagues (%d total events)...\n", len(events))
+	}
+	
+	// Cup matches join the fit alongside the league matches, weighted by competition
+	fitEvents := events
+	if len(cupEvents) > 0 {
+		fitEvents = append(append([]MatchResult(nil), events...), cupEvents...)
+		sort.SliceStable(fitEvents, func(i, j int) bool {
+			return fitEvents[i].Date < fitEvents[j].Date
+		})
+	}
+	
+	// Create single MLE request for ALL events across ALL leagues  
+	request := MLERequest{
+		HistoricalData: fitEvents,
+		LeagueChangeTeams: run.leagueChangeTeams,
+		LeagueGroups:   leagueGroups,
+		Handicaps:      handicaps,
+		Options:        options,
+	}
+	
+	// Run single MLE optimization across all leagues, or one isolated fit per league
+	optimizeStart := time.Now()
+	var mlResult *MLEResult
+	var leagueFits map[string]*MLEResult
+	if options.IndependentLeagues {
+		leagueFits, err = fitLeaguesIndependently(leagues, request, currentTeams)
+		if err == nil {
+			mlResult = mergeLeagueFits(leagues, leagueFits, currentTeams)
+		}
+	} else {
+		mlResult, err = RunSimulation(request)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("MLE optimization failed: %w", err)
+	}
+	result.Timings.Optimize = time.Since(optimizeStart)
+	result.RatingOverrides = mlResult.MLEParams.RatingOverrides
+	if options.IncludeMatchOdds {
+		result.MatchOdds = matchOddsByLeague(mlResult.MatchOdds)
+	}
+	
+	if options.Debug {
+		fmt.Printf("✅ Single MLE optimization complete: %d iterations, converged=%v\n", 
+			mlResult.MLEParams.Iterations, mlResult.MLEParams.Converged)
+	}
+	
+	return run.simulate(mlResult.Teams, mlResult.MLEParams, leagueFits)
+}
+
+// matchOddsByLeague groups fixture odds by league, keeping their order within each league
+func matchOddsByLeague(odds []MatchOdds) map[string][]MatchOdds {
+	byLeague := make(map[string][]MatchOdds)
+	for _, fixture := range odds {
+		byLeague[fixture.League] = append(byLeague[fixture.League], fixture)
+	}
+	return byLeague
+}
+
+// leagueRun holds the validated inputs that fitting and season prediction share
+type leagueRun struct {
+	startTime         time.Time
+	options           MLEOptions
+	events            []MatchResult // League matches, sorted by date
+	cupEvents         []MatchResult // Cup matches, for the fit only
+	leagues           []string
+	eventsByLeague    map[string][]MatchResult
+	leagueChangeTeams map[string]bool
+	leagueGroups      map[string][]string
+	currentTeams      map[string][]string
+	markets           []Market
+	handicaps         map[string]int
+	latestSeason      string // Latest season in the data (for team selection)
+	currentSeason     string // Season used for tables and simulation ("" when using league groups)
+	result            *MultiLeagueResult
+}
+
+// prepareLeagueRun ingests and validates the events, league groups and markets, and starts the result
+func prepareLeagueRun(events []MatchResult, markets []Market, options MLEOptions, handicaps map[string]int, leagueGroups map[string][]string) (*leagueRun, error) {
 	startTime := time.Now()
+	fingerprint := newDataFingerprint(events, markets, handicaps)
 	
 	if len(events) == 0 {
 		return nil, fmt.Errorf("no events data provided")
 	}
 	
+	// Results after the as-of date are unknown: training data, tables and remaining fixtures all
+	// come from the truncated events, with former team names mapped to canonical ones
+	events, err := ingestMatches(events, options)
+	if err != nil {
+		return nil, err
+	}
+	totalMatches := len(events)
+	
+	// Cup matches only inform the fit; tables, simulations and league changes use league matches
+	events, cupEvents := splitCupMatches(events)
+	if options.Debug && len(cupEvents) > 0 {
+		fmt.Printf("🏆 Found %d cup matches (used for ratings only)\n", len(cupEvents))
+	}
+	
 	// Extract global entities for validation
 	globalEntities := ExtractGlobalEntities(events)
 	if options.Debug {
@@ -95,17 +286,18 @@ func RunMLESolver(events []MatchResult, markets []Market, options MLEOptions, ha
 	// Initialize event processor
 	processor := NewEventProcessor(events, options.Debug)
 	
-	// Load league groups (team configurations)
-	if err := processor.LoadLeagueGroups(); err != nil {
-		if options.Debug {
-			fmt.Printf("⚠️  Could not load league groups: %v (will use latest season teams)\n", err)
-		}
-	}
-	
-	// Validate league groups if they were loaded
-	leagueGroups := processor.GetLeagueGroups()
+	// Validate league groups if they were supplied
 	if err := ValidateLeagueGroups(leagueGroups, globalEntities); err != nil {
-		return nil, fmt.Errorf("league groups validation failed: %w", err)
+		return nil, fmt.Errorf("league groups validation failed: %w: %w", ErrLeagueGroupMismatch, err)
+	}
+	if err := validateLambdaMultipliers(options.LambdaMultipliers, globalEntities.Teams); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if err := validateRatingOverrides(options.RatingOverrides, globalEntities.Teams); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if options.RatingsOnly && options.Conditioning != nil {
+		return nil, fmt.Errorf("invalid request: conditioning needs the season simulation, which RatingsOnly skips")
 	}
 	
 	// Process events using the events module
@@ -125,9 +317,26 @@ func RunMLESolver(events []MatchResult, markets []Market, options MLEOptions, ha
 	// Get current teams for market validation using our helper function
 	currentTeams := GetCurrentTeams(leagueGroups, eventsByLeague, latestSeason)
 	
-	// Validate and initialize markets
+	if options.SimParams == nil {
+		options.SimParams = DefaultSimParams()
+	}
+	if options.Deterministic {
+		options.SimParams = deterministicSimParams(options.SimParams)
+	}
+	if err := validateTiebreaks(options.SimParams.Tiebreaks); err != nil {
+		return nil, fmt.Errorf("invalid tiebreaks: %w", err)
+	}
+	if err := options.SimParams.PriceLadder.validate(); err != nil {
+		return nil, fmt.Errorf("invalid price ladder: %w", err)
+	}
+	if err := validateMargins(options.SimParams); err != nil {
+		return nil, fmt.Errorf("invalid margins: %w", err)
+	}
+	
+	// Validate and initialize copies of the markets, leaving the caller's slice untouched
+	markets = append([]Market(nil), markets...)
 	if len(markets) > 0 {
-		err := validateAndInitializeMarkets(markets, currentTeams, eventsByLeague, effectiveLatestSeason)
+		err := validateAndInitializeMarkets(markets, currentTeams, eventsByLeague, effectiveLatestSeason, options.SimParams.Tiebreaks)
 		if err != nil {
 			return nil, fmt.Errorf("market validation failed: %w", err)
 		}
@@ -140,145 +349,396 @@ func RunMLESolver(events []MatchResult, markets []Market, options MLEOptions, ha
 		Leagues:        make(map[string][]Team),
 		Markets:        markets,
 		MarkValues:     make(map[string]map[string]map[string]float64),
+		MarkStdErrors:  make(map[string]map[string]map[string]float64),
+		PlaceValues:    make(map[string]map[string]map[string]float64),
+		Simulations:    make(map[string]*SimPoints),
+		Diagnostics:    make(map[string]LeagueDiagnostics),
 		LatestSeason:   effectiveLatestSeason,
-		TotalMatches:   len(events),
+		LeagueChanges:  processor.DetectLeagueChanges(),
+		TotalMatches:   totalMatches,
+		Fingerprint:    fingerprint,
 		ProcessingTime: time.Since(startTime),
+		Timings: TimingBreakdown{
+			Load:       time.Since(startTime),
+			Simulation: make(map[string]time.Duration),
+			Markets:    make(map[string]time.Duration),
+		},
 	}
 	
-	// Sort all events by date for consistent processing order
+	// Sort a copy of the events by date for consistent processing order (the caller's slice is
+	// not modified, so concurrent calls may share it)
+	events = append([]MatchResult(nil), events...)
 	sort.Slice(events, func(i, j int) bool {
 		return events[i].Date < events[j].Date
 	})
 	
-	if options.Debug {
-		fmt.Printf("\n🏈 Running single MLE optimization across ALL leagues (%d total events)...\n", len(events))
-	}
+	leagues := ExtractLeagues(events)
+	sort.Strings(leagues)
 	
-	// Create single MLE request for ALL events across ALL leagues  
-	request := MLERequest{
-		HistoricalData: events,
-		LeagueChangeTeams: leagueChangeTeams,
-		LeagueGroups:   leagueGroups,
-		Handicaps:      handicaps,
-		Options:        options,
-	}
+	return &leagueRun{
+		startTime:         startTime,
+		options:           options,
+		events:            events,
+		cupEvents:         cupEvents,
+		leagues:           leagues,
+		eventsByLeague:    eventsByLeague,
+		leagueChangeTeams: leagueChangeTeams,
+		leagueGroups:      leagueGroups,
+		currentTeams:      currentTeams,
+		markets:           markets,
+		handicaps:         handicaps,
+		latestSeason:      latestSeason,
+		currentSeason:     effectiveLatestSeason,
+		result:            result,
+	}, nil
+}
+
+// simulate runs tables, season simulations and marks for every league from the given ratings
+func (run *leagueRun) simulate(teams []Team, params MLEParams, leagueFits map[string]*MLEResult) (*MultiLeagueResult, error) {
+	options, result := run.options, run.result
 	
-	// Run single MLE optimization across all leagues
-	mlResult, err := RunSimulation(request)
-	if err != nil {
-		return nil, fmt.Errorf("MLE optimization failed: %w", err)
+	// Resolve rating-based exclude rules now that ratings are known
+	if err := resolveRatingExcludeRules(run.markets, run.currentTeams, params); err != nil {
+		return nil, fmt.Errorf("market validation failed: %w", err)
 	}
 	
-	if options.Debug {
-		fmt.Printf("✅ Single MLE optimization complete: %d iterations, converged=%v\n", 
-			mlResult.MLEParams.Iterations, mlResult.MLEParams.Converged)
+	// Now filter and organize results by league - use leagues found in events
+	// League simulations are independent, so they run in a bounded worker pool
+	inputs := &leagueSimInputs{
+		teams:          teams,
+		params:         params,
+		leagueFits:     leagueFits,
+		options:        options,
+		events:         run.events,
+		eventsByLeague: run.eventsByLeague,
+		leagueGroups:   run.leagueGroups,
+		markets:        run.markets,
+		handicaps:      run.handicaps,
+		latestSeason:   run.latestSeason,
+		currentSeason:  run.currentSeason,
 	}
+	if err := options.Conditioning.validate(inputs.remainingFixtures(run.leagues)); err != nil {
+		return nil, fmt.Errorf("conditioning failed: %w", err)
+	}
+	outcomes := simulateLeagues(run.leagues, inputs)
+	result.MLEParams = params
+	result.simInputs = inputs
+	result.applyOutcomes(outcomes)
 	
-	// Now filter and organize results by league - use leagues found in events
-	leagues := ExtractLeagues(events)
-	for _, league := range leagues {
-		if options.Debug {
-			fmt.Printf("\n📊 Filtering results for %s...\n", league)
+	result.ProcessingTime = time.Since(run.startTime)
+	if options.Deterministic {
+		result.clearTimings()
+	}
+	return result, nil
+}
+
+// ReleaseSimulations hands the result's season simulations back for reuse by later runs, for
+// callers that re-run often and have finished with the post-hoc queries. Afterwards the queries
+// and RunConditionalSimulation fail as for a result without simulations. A conditional result
+// shares the simulations of leagues it did not re-simulate with the result it came from; once
+// either is released, the other's queries on those leagues return ErrSimulationReleased.
+// Releasing both is safe
+func (result *MultiLeagueResult) ReleaseSimulations() {
+	for _, simPoints := range result.Simulations {
+		if simPoints != nil {
+			simPoints.release()
 		}
-		
-		var targetTeams map[string]bool
-		
-		// Use league groups if available, otherwise fall back to latest season teams
-		if leagueGroups != nil && len(leagueGroups[league]) > 0 {
-			targetTeams = make(map[string]bool)
-			for _, team := range leagueGroups[league] {
-				targetTeams[team] = true
+	}
+	result.Simulations = make(map[string]*SimPoints)
+	result.simInputs = nil
+}
+
+// applyOutcomes stores per-league simulation outcomes on the result and rebuilds edge reports
+func (result *MultiLeagueResult) applyOutcomes(outcomes []*leagueOutcome) {
+	for _, outcome := range outcomes {
+		result.Leagues[outcome.League] = outcome.Teams
+		result.Simulations[outcome.League] = outcome.SimPoints
+		result.Timings.Simulation[outcome.League] = outcome.SimulationTime
+		result.Diagnostics[outcome.League] = outcome.Diagnostics
+		if outcome.MarketsEvaluated {
+			result.Timings.Markets[outcome.League] = outcome.MarketsTime
+		}
+		if league := outcome.Result; len(league.MarkValues) > 0 {
+			result.MarkValues[outcome.League] = league.MarkValues
+			result.MarkStdErrors[outcome.League] = league.MarkStdErrors
+			if league.PlaceValues != nil {
+				result.PlaceValues[outcome.League] = league.PlaceValues
 			}
-			if options.Debug {
-				fmt.Printf("🎯 Using league groups: %d teams for %s\n", len(leagueGroups[league]), league)
+			if league.MarkOdds != nil {
+				if result.MarkOdds == nil {
+					result.MarkOdds = make(map[string]map[string]map[string]float64)
+				}
+				result.MarkOdds[outcome.League] = league.MarkOdds
 			}
-		} else {
-			// Get teams from latest season for this league
-			leagueEvents := eventsByLeague[league]
-			if leagueEvents != nil {
-				targetTeams = GetTeamsInSeason(leagueEvents, latestSeason)
-				if options.Debug {
-					fmt.Printf("📅 Using latest season teams: %d teams for %s\n", len(targetTeams), league)
+			if league.BookPrices != nil {
+				if result.BookPrices == nil {
+					result.BookPrices = make(map[string]map[string]map[string]float64)
 				}
+				result.BookPrices[outcome.League] = league.BookPrices
 			}
 		}
-		
-		// Filter teams for this league and collect team names
-		var leagueTeams []string
-		teamDataMap := make(map[string]Team)
-		for _, team := range mlResult.Teams {
-			if _, isTargetTeam := targetTeams[team.Name]; isTargetTeam {
-				leagueTeams = append(leagueTeams, team.Name)
-				teamDataMap[team.Name] = team
-			}
+	}
+	
+	// Compare marks against offered prices where markets carry them
+	result.EdgeReports = BuildEdgeReports(result.Markets, result.MarkValues, result.PlaceValues)
+	if ladder := result.simInputs.options.SimParams.PriceLadder; len(ladder) > 0 {
+		snapEdgeReports(result.EdgeReports, ladder)
+	}
+}
+
+
+// leagueSimInputs holds the shared read-only inputs for per-league simulation
+type leagueSimInputs struct {
+	teams          []Team
+	params         MLEParams
+	leagueFits     map[string]*MLEResult // league -> isolated fit (IndependentLeagues only)
+	options        MLEOptions
+	events         []MatchResult
+	eventsByLeague map[string][]MatchResult
+	leagueGroups   map[string][]string
+	markets        []Market
+	handicaps      map[string]int
+	latestSeason   string // Latest season in the data (for team selection)
+	currentSeason  string // Season used for tables and simulation ("" when using league groups)
+}
+
+// leagueOutcome holds the simulation results for a single league
+type leagueOutcome struct {
+	League           string
+	Teams            []Team
+	Marks            *leagueMarks
+	SimPoints        *SimPoints
+	SimulationTime   time.Duration
+	MarketsTime      time.Duration
+	MarketsEvaluated bool
+	Diagnostics      LeagueDiagnostics
+	Result           LeagueResult // Published outputs, set once the league completes
+}
+
+// runLeagueWorkers calls fn for each league with at most workers concurrent calls (0 = runtime.NumCPU())
+// Outcomes are returned in the same order as leagues
+func runLeagueWorkers[T any](leagues []string, workers int, fn func(league string) T) []T {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	
+	outcomes := make([]T, len(leagues))
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	
+	for i, league := range leagues {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, league string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			outcomes[i] = fn(league)
+		}(i, league)
+	}
+	
+	wg.Wait()
+	return outcomes
+}
+
+// leagueTeams returns the fitted teams simulated in a league, from its league group or else its
+// latest-season teams, with the params their ratings come from
+func (in *leagueSimInputs) leagueTeams(league string, debug bool) ([]Team, MLEParams) {
+	var targetTeams map[string]bool
+	
+	// Use league groups if available, otherwise fall back to latest season teams
+	if in.leagueGroups != nil && len(in.leagueGroups[league]) > 0 {
+		targetTeams = make(map[string]bool)
+		for _, team := range in.leagueGroups[league] {
+			targetTeams[team] = true
 		}
-		
-		// Calculate expected season points for teams in this league (with simulation reuse)
-		seasonResult := calculateLeagueSeasonPointsWithSim(leagueTeams, mlResult.MLEParams, options.SimParams, 
-			events, league, effectiveLatestSeason, request.Handicaps)
-		expectedSeasonPoints := seasonResult.ExpectedPoints
-		
-		// Get current season matches for this league to build proper league table
-		var leagueEvents []MatchResult
-		for _, event := range events {
-			if event.League == league && event.Season == effectiveLatestSeason {
-				leagueEvents = append(leagueEvents, event)
+		if debug {
+			fmt.Printf("🎯 Using league groups: %d teams for %s\n", len(in.leagueGroups[league]), league)
+		}
+	} else {
+		// Get teams from latest season for this league
+		leagueEvents := in.eventsByLeague[league]
+		if leagueEvents != nil {
+			targetTeams = GetTeamsInSeason(leagueEvents, in.latestSeason)
+			if debug {
+				fmt.Printf("📅 Using latest season teams: %d teams for %s\n", len(targetTeams), league)
 			}
 		}
-		
-		// Convert to Event format and calculate league table
-		currentSeasonEvents := convertMatchResultsToEvents(leagueEvents, effectiveLatestSeason)
-		leagueTable := calcLeagueTable(leagueTeams, currentSeasonEvents, request.Handicaps)
-		
-		// Create unified Team objects with all data
-		var teams []Team
-		for _, tableTeam := range leagueTable {
-			if teamData, exists := teamDataMap[tableTeam.Name]; exists {
-				team := Team{
-					Name:           tableTeam.Name,
-					Points:         tableTeam.Points,
-					GoalDifference: tableTeam.GoalDifference,
-					Played:         tableTeam.Played,
-					AttackRating:   teamData.AttackRating,
-					DefenseRating:  teamData.DefenseRating,
-					LambdaHome:     teamData.LambdaHome,
-					LambdaAway:     teamData.LambdaAway,
-				}
-				
-				// Add expected season points
-				if points, exists := expectedSeasonPoints[team.Name]; exists {
-					team.ExpectedSeasonPoints = points
-				}
-				
-				teams = append(teams, team)
+	}
+	
+	// Ratings come from the league's own fit when leagues are fitted independently
+	fittedTeams, params := in.teams, in.params
+	if fit, exists := in.leagueFits[league]; exists {
+		fittedTeams, params = fit.Teams, fit.MLEParams
+	}
+	
+	var teams []Team
+	for _, team := range fittedTeams {
+		if _, isTargetTeam := targetTeams[team.Name]; isTargetTeam {
+			teams = append(teams, team)
+		}
+	}
+	return teams, params
+}
+
+// remainingFixtures returns the fixtures the season simulation of leagues will play, before running it
+func (in *leagueSimInputs) remainingFixtures(leagues []string) map[string]bool {
+	remaining := make(map[string]bool)
+	if in.options.RatingsOnly {
+		return remaining
+	}
+	for _, league := range leagues {
+		teams, _ := in.leagueTeams(league, false)
+		var teamNames []string
+		for _, team := range teams {
+			teamNames = append(teamNames, team.Name)
+		}
+		events := leagueSeasonEvents(in.events, league, in.currentSeason)
+		for _, fixture := range calcRemainingFixtures(teamNames, events, getRounds(league)) {
+			remaining[fixture] = true
+		}
+	}
+	return remaining
+}
+
+// simulateLeague builds the table, runs the season simulation and calculates mark values for one league
+func simulateLeague(league string, in *leagueSimInputs) *leagueOutcome {
+	options := in.options
+	outcome := &leagueOutcome{League: league}
+	
+	if options.Debug {
+		fmt.Printf("\n📊 Filtering results for %s...\n", league)
+	}
+	
+	// Filter teams for this league and collect team names
+	fittedTeams, params := in.leagueTeams(league, options.Debug)
+	var leagueTeams []string
+	teamDataMap := make(map[string]Team)
+	for _, team := range fittedTeams {
+		leagueTeams = append(leagueTeams, team.Name)
+		teamDataMap[team.Name] = team
+	}
+	
+	// Calculate expected season points for teams in this league (with simulation reuse)
+	// A ratings-only run leaves them empty, which also skips the marks below
+	seasonResult := &SeasonPointsResult{}
+	if !options.RatingsOnly {
+		simStart := time.Now()
+		seasonResult = calculateLeagueSeasonPointsWithSim(leagueTeams, params, options.SimParams, 
+			in.events, league, in.currentSeason, in.handicaps, options.Conditioning.byFixture())
+		outcome.SimulationTime = time.Since(simStart)
+		outcome.SimPoints = seasonResult.SimPoints
+		options.observeSimulation(league, options.SimParams.SimulationPaths, seasonResult.Fixtures, outcome.SimulationTime)
+	}
+	expectedSeasonPoints := seasonResult.ExpectedPoints
+	
+	// Get current season matches for this league to build proper league table
+	var leagueEvents []MatchResult
+	for _, event := range in.events {
+		if event.League == league && event.Season == in.currentSeason {
+			leagueEvents = append(leagueEvents, event)
+		}
+	}
+	outcome.Diagnostics = LeagueDiagnostics{
+		Teams:           len(leagueTeams),
+		Events:          len(in.eventsByLeague[league]),
+		SeasonEvents:    len(leagueEvents),
+		Fixtures:        seasonResult.Fixtures,
+		SimulationTime:  outcome.SimulationTime,
+		Iterations:      params.Iterations,
+		Converged:       params.Converged,
+		LogLikelihood:   params.LogLikelihood,
+	}
+	if !options.RatingsOnly {
+		outcome.Diagnostics.SimulationPaths = options.SimParams.SimulationPaths
+	}
+	
+	// Convert to Event format and calculate league table
+	currentSeasonEvents := convertMatchResultsToEvents(leagueEvents, in.currentSeason)
+	leagueTable := calcLeagueTable(leagueTeams, currentSeasonEvents, in.handicaps, leagueTiebreaks(options.SimParams.Tiebreaks, league))
+	
+	// Finishing position distribution across the whole league, ranked in the same pass as the
+	// markets' teams so the marks below read them from the cache
+	var positionProbs map[string][]float64
+	var scenarios map[string][]Scenario
+	if seasonResult.SimPoints != nil {
+		teamSets := append([][]string{nil}, positionMarketTeams(in.markets, league)...)
+		positionProbs = seasonResult.SimPoints.positionProbabilitiesFor(teamSets)[0]
+		scenarios = seasonResult.SimPoints.scenarios(scenarioPercentiles)
+	}
+	
+	// Create unified Team objects with all data
+	var teams []Team
+	for _, tableTeam := range leagueTable {
+		if teamData, exists := teamDataMap[tableTeam.Name]; exists {
+			team := Team{
+				Name:           tableTeam.Name,
+				Points:         tableTeam.Points,
+				GoalDifference: tableTeam.GoalDifference,
+				GoalsFor:       tableTeam.GoalsFor,
+				GoalsAgainst:   tableTeam.GoalsAgainst,
+				Played:         tableTeam.Played,
+				Won:            tableTeam.Won,
+				Drawn:          tableTeam.Drawn,
+				Lost:           tableTeam.Lost,
+				AttackRating:   teamData.AttackRating,
+				DefenseRating:  teamData.DefenseRating,
+				LambdaHome:     teamData.LambdaHome,
+				LambdaAway:     teamData.LambdaAway,
+			}
+			
+			// Add expected season points
+			if points, exists := expectedSeasonPoints[team.Name]; exists {
+				team.ExpectedSeasonPoints = points
+			}
+			
+			// Add expected season goal totals
+			team.ExpectedSeasonGoalsFor = seasonResult.ExpectedGoalsFor[team.Name]
+			team.ExpectedSeasonGoalsAgainst = seasonResult.ExpectedGoalsAgainst[team.Name]
+			team.CleanSheets = seasonResult.CleanSheets[team.Name]
+			team.VsAverage = averageOpponentGoals(team.Name, leagueTeams, params.forLeague(league))
+			
+			// Add expected finishing position statistics
+			if probs, exists := positionProbs[team.Name]; exists {
+				team.ExpectedPosition, team.MedianPosition, team.ModalPosition = positionStatistics(probs)
 			}
+			team.Scenarios = scenarios[team.Name]
+			
+			// Add recent form from the latest season in the data
+			team.Form = calculateTeamForm(team.Name, in.events, in.latestSeason, options.SimParams.FormWindow)
+			
+			teams = append(teams, team)
 		}
-		
-		// Sort by expected season points (descending) for league table order
+	}
+	
+	// Sort by expected season points (descending) for league table order
+	// Ties go by name, so the order never depends on the input's; a ratings-only run keeps the
+	// current table's order
+	if !options.RatingsOnly {
 		sort.Slice(teams, func(i, j int) bool {
-			return teams[i].ExpectedSeasonPoints > teams[j].ExpectedSeasonPoints
-		})
-		
-		result.Leagues[league] = teams
-		
-		// Calculate mark values using the same simulation (reuse for performance)
-		if len(markets) > 0 && seasonResult.SimPoints != nil {
-			leagueMarkValues := calculateMarkValues(seasonResult.SimPoints, markets, league)
-			if len(leagueMarkValues) > 0 {
-				result.MarkValues[league] = leagueMarkValues
-				if options.Debug {
-					fmt.Printf("📊 Calculated mark values for %d markets in %s\n", len(leagueMarkValues), league)
-				}
-				
+			if teams[i].ExpectedSeasonPoints != teams[j].ExpectedSeasonPoints {
+				return teams[i].ExpectedSeasonPoints > teams[j].ExpectedSeasonPoints
 			}
+			return teams[i].Name < teams[j].Name
+		})
+	}
+	
+	outcome.Teams = teams
+	
+	// Calculate mark values using the same simulation (reuse for performance)
+	if len(in.markets) > 0 && seasonResult.SimPoints != nil {
+		marksStart := time.Now()
+		outcome.Marks = calculateMarkValues(seasonResult.SimPoints, in.markets, league)
+		outcome.MarketsTime = time.Since(marksStart)
+		outcome.MarketsEvaluated = true
+		options.observeMarketEvaluation(league, len(outcome.Marks.Values), outcome.MarketsTime)
+		if len(outcome.Marks.Values) > 0 && options.Debug {
+			fmt.Printf("📊 Calculated mark values for %d markets in %s\n", len(outcome.Marks.Values), league)
 		}
 	}
 	
-	result.ProcessingTime = time.Since(startTime)
-	return result, nil
+	return outcome
 }
 
-
 // convertMatchResultsToEvents converts MatchResult to Event format
 func convertMatchResultsToEvents(matches []MatchResult, season string) []Event {
 	var events []Event
@@ -317,8 +777,9 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// generateFixturesPerLeague generates match odds for teams within their respective leagues only
-// Uses leagueGroups if available, otherwise falls back to latest season teams
+// generateFixturesPerLeague generates match odds for the fixtures still to be played in each league
+// Uses leagueGroups if available, otherwise falls back to latest season teams; fixtures come from
+// request.Schedule where it covers the league, otherwise from calcRemainingFixtures
 func generateFixturesPerLeague(teams []Team, solver *MLESolver, request MLERequest) []MatchOdds {
 	var matchOdds []MatchOdds
 	
@@ -328,40 +789,110 @@ func generateFixturesPerLeague(teams []Team, solver *MLESolver, request MLEReque
 		teamMap[team.Name] = team
 	}
 	
-	// Determine current teams per league
-	processor := NewEventProcessor(request.HistoricalData, false)
+	// Determine current teams per league (cup matches say nothing about league membership)
+	leagueMatches, _ := splitCupMatches(request.HistoricalData)
+	processor := NewEventProcessor(leagueMatches, false)
 	eventsByLeague := processor.GroupEventsByLeague()
 	latestSeason := processor.FindLatestSeason()
 	currentTeams := GetCurrentTeams(request.LeagueGroups, eventsByLeague, latestSeason)
 	
-	// Generate fixtures for each league separately
-	for league, leagueTeams := range currentTeams {
+	// With league groups the season being priced has not started, so nothing has been played
+	currentSeason := latestSeason
+	if len(request.LeagueGroups) > 0 {
+		currentSeason = ""
+	}
+	
+	scheduleByLeague := make(map[string][]ScheduledFixture)
+	for _, fixture := range request.Schedule {
+		scheduleByLeague[fixture.League] = append(scheduleByLeague[fixture.League], fixture)
+	}
+	
+	// Short-rest fatigue from the fitted coefficient, for dated fixtures only
+	rest := restTerms(*solver.params, request.Schedule)
+	
+	leagues := make([]string, 0, len(currentTeams))
+	for league := range currentTeams {
+		leagues = append(leagues, league)
+	}
+	sort.Strings(leagues)
+	
+	// Generate fixtures for each league separately, each at its league's scoring level
+	for _, league := range leagues {
+		leagueParams := solver.params.forLeague(league)
+		leagueSolver := *solver
+		leagueSolver.params = &leagueParams
+		
 		// Filter teams that exist in our optimized ratings
-		var validTeams []Team
-		for _, teamName := range leagueTeams {
-			if team, exists := teamMap[teamName]; exists {
-				validTeams = append(validTeams, team)
+		var validTeams []string
+		for _, teamName := range currentTeams[league] {
+			if _, exists := teamMap[teamName]; exists {
+				validTeams = append(validTeams, teamName)
 			}
 		}
 		
-		// Generate all combinations within this league
-		for i, homeTeam := range validTeams {
-			for j, awayTeam := range validTeams {
-				if i != j { // Skip same team vs same team
-					fixture := fmt.Sprintf("%s vs %s", homeTeam.Name, awayTeam.Name)
-					probabilities := solver.CalculateMatchProbabilities(homeTeam.Name, awayTeam.Name)
-					
-					matchOdds = append(matchOdds, MatchOdds{
-						Fixture:       fixture,
-						League:        league,
-						Probabilities: probabilities,
-					})
-				}
+		var played []Event
+		if currentSeason != "" {
+			played = convertMatchResultsToEvents(eventsByLeague[league], currentSeason)
+		}
+		remaining := calcRemainingFixtures(validTeams, played, getRounds(league))
+		
+		for _, fixture := range remainingSchedule(league, remaining, scheduleByLeague[league]) {
+			homeAdvantage := leagueSolver.params.HomeAdvantage
+			if fixture.Neutral {
+				homeAdvantage = 0
 			}
+			shift := rest[covariateKey{fixture.Date, fixture.HomeTeam, fixture.AwayTeam}]
+			probabilities := leagueSolver.shiftedScoreMatrix(fixture.HomeTeam, fixture.AwayTeam, homeAdvantage, shift).MatchOdds()
+			
+			matchOdds = append(matchOdds, MatchOdds{
+				Fixture:       fmt.Sprintf("%s vs %s", fixture.HomeTeam, fixture.AwayTeam),
+				League:        league,
+				Probabilities: probabilities,
+				Neutral:       fixture.Neutral,
+				Date:          fixture.Date,
+				Round:         fixture.Round,
+				DecimalOdds:   matchDecimalOdds(probabilities, solver.options.SimParams),
+				BookOdds:      matchBookOdds(probabilities, solver.options.SimParams),
+			})
 		}
 	}
 	
 	return matchOdds
 }
 
+// remainingSchedule returns the scheduled fixtures that are still to be played, in schedule order
+// A scheduled fixture counts against the remaining meetings of its pairing, so a full-season
+// schedule can be supplied as-is; without a schedule the remaining fixtures are returned undated
+func remainingSchedule(league string, remaining []string, schedule []ScheduledFixture) []ScheduledFixture {
+	if len(schedule) == 0 {
+		fixtures := make([]ScheduledFixture, len(remaining))
+		for i, name := range remaining {
+			homeTeam, awayTeam := parseEventName(name)
+			fixtures[i] = ScheduledFixture{League: league, HomeTeam: homeTeam, AwayTeam: awayTeam}
+		}
+		return fixtures
+	}
+	
+	// Matches already played come off the front of a pairing's schedule
+	scheduled := make(map[string]int)
+	for _, fixture := range schedule {
+		scheduled[fixture.HomeTeam+" vs "+fixture.AwayTeam]++
+	}
+	unplayed := make(map[string]int)
+	for _, name := range remaining {
+		unplayed[name]++
+	}
+	
+	var fixtures []ScheduledFixture
+	for _, fixture := range schedule {
+		name := fixture.HomeTeam + " vs " + fixture.AwayTeam
+		if scheduled[name] > unplayed[name] {
+			scheduled[name]--
+			continue
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures
+}
+
 
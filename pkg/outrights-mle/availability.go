@@ -0,0 +1,54 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+)
+
+// LambdaMultiplier scales a team's expected goals for simulation and pricing, e.g. Attack 0.9
+// while its striker is injured; the fit itself is unaffected. Zero leaves a side unchanged
+type LambdaMultiplier struct {
+	Attack  float64 `json:"attack,omitempty"`  // Multiplier on the team's expected goals scored
+	Defense float64 `json:"defense,omitempty"` // Multiplier on the team's expected goals conceded
+}
+
+// validateLambdaMultipliers checks multipliers name known teams and are not negative
+func validateLambdaMultipliers(multipliers map[string]LambdaMultiplier, teams []string) error {
+	for _, team := range sortedKeys(multipliers) {
+		multiplier := multipliers[team]
+		if multiplier.Attack < 0 || multiplier.Defense < 0 {
+			return fmt.Errorf("lambda multipliers for %s must not be negative, got attack %v and defense %v", team, multiplier.Attack, multiplier.Defense)
+		}
+		if !containsString(teams, team) {
+			return &UnknownTeamError{Team: team, Context: "lambda multipliers"}
+		}
+	}
+	return nil
+}
+
+// applyLambdaMultipliers returns a copy of params with each multiplier folded into the team's
+// ratings: log(Attack) on attack, and −log(Defense) on defense, since defense is subtracted from
+// the opponent's log scoring rate. Teams params do not rate are skipped
+func applyLambdaMultipliers(params *MLEParams, multipliers map[string]LambdaMultiplier) *MLEParams {
+	if len(multipliers) == 0 {
+		return params
+	}
+
+	adjusted := *params
+	adjusted.AttackRatings = copyMap(params.AttackRatings)
+	adjusted.DefenseRatings = copyMap(params.DefenseRatings)
+	adjusted.LambdaMultipliers = copyMap(params.LambdaMultipliers)
+	for team, multiplier := range multipliers {
+		if _, exists := adjusted.AttackRatings[team]; !exists {
+			continue
+		}
+		if multiplier.Attack > 0 {
+			adjusted.AttackRatings[team] += math.Log(multiplier.Attack)
+		}
+		if multiplier.Defense > 0 {
+			adjusted.DefenseRatings[team] -= math.Log(multiplier.Defense)
+		}
+		adjusted.LambdaMultipliers[team] = multiplier
+	}
+	return &adjusted
+}
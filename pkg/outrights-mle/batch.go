@@ -0,0 +1,127 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// BatchPricing holds the prices from a PriceAll call
+type BatchPricing struct {
+	Odds   []MatchOdds    `json:"odds"`             // Priced fixtures, in input order
+	Errors []FixtureError `json:"errors,omitempty"` // Fixtures that could not be priced
+}
+
+// FixtureError records why one fixture in a batch was not priced
+type FixtureError struct {
+	Index   int    `json:"index"` // Position in the input fixtures
+	Fixture string `json:"fixture"`
+	League  string `json:"league"`
+	Error   string `json:"error"`
+}
+
+// teamStrength holds a team's exponentiated ratings, so lambdas are two multiplications
+type teamStrength struct {
+	attack  float64 // exp(attack rating)
+	defense float64 // exp(-defense rating)
+}
+
+// PriceAll prices a batch of scheduled fixtures in parallel, e.g. from an upcoming-fixture feed
+// Team strengths are exponentiated once and shared by all workers (0 = runtime.NumCPU()).
+// Each MatchOdds carries 1X2 probabilities, winning margins and any configured decimal or book odds;
+// fixtures with unrated teams are reported in Errors rather than failing the batch
+// Uses DefaultSimParams if simParams is nil
+func PriceAll(params MLEParams, simParams *SimParams, fixtures []ScheduledFixture, workers int) (*BatchPricing, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	if err := validateMargins(simParams); err != nil {
+		return nil, fmt.Errorf("invalid margins: %w", err)
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Fixtures can span leagues, so league intercepts are split into each team's ratings
+	params = params.forLeague("")
+	strengths := make(map[string]teamStrength, len(params.AttackRatings))
+	for team, attack := range params.AttackRatings {
+		strengths[team] = teamStrength{attack: math.Exp(params.Intercept + attack), defense: math.Exp(-params.DefenseRatings[team])}
+	}
+	homeFactor := math.Exp(params.HomeAdvantage)
+	rest := restTerms(params, fixtures) // Short-rest fatigue for dated fixtures, when fitted
+	model := params.countModel()
+
+	odds := make([]*MatchOdds, len(fixtures))
+	failures := make([]string, len(fixtures))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fixture := fixtures[i]
+				home, homeExists := strengths[fixture.HomeTeam]
+				away, awayExists := strengths[fixture.AwayTeam]
+				switch {
+				case fixture.HomeTeam == "" || fixture.AwayTeam == "":
+					failures[i] = "fixture needs a home and an away team"
+					continue
+				case !homeExists:
+					failures[i] = fmt.Sprintf("unknown team %s", fixture.HomeTeam)
+					continue
+				case !awayExists:
+					failures[i] = fmt.Sprintf("unknown team %s", fixture.AwayTeam)
+					continue
+				}
+
+				lambdaHome := home.attack * away.defense
+				if !fixture.Neutral {
+					lambdaHome *= homeFactor
+				}
+				lambdaAway := away.attack * home.defense
+				if shift := rest[covariateKey{fixture.Date, fixture.HomeTeam, fixture.AwayTeam}]; shift != 0 {
+					lambdaHome *= math.Exp(shift)
+					lambdaAway *= math.Exp(-shift)
+				}
+				lambdaHome, lambdaAway = cappedLambda(lambdaHome, simParams), cappedLambda(lambdaAway, simParams)
+				scoreMatrix := NewCountScoreMatrix(model, lambdaHome, lambdaAway, params.Rho, simParams.GoalSimulationBound)
+				probabilities := scoreMatrix.MatchOdds()
+				margins := countWinningMargins(model, lambdaHome, lambdaAway, params.Rho, simParams.GoalSimulationBound)
+				odds[i] = &MatchOdds{
+					Fixture:       fmt.Sprintf("%s vs %s", fixture.HomeTeam, fixture.AwayTeam),
+					League:        fixture.League,
+					Probabilities: probabilities,
+					Neutral:       fixture.Neutral,
+					Date:          fixture.Date,
+					Round:         fixture.Round,
+					DecimalOdds:   matchDecimalOdds(probabilities, simParams),
+					BookOdds:      matchBookOdds(probabilities, simParams),
+					Margins:       &margins,
+				}
+			}
+		}()
+	}
+	for i := range fixtures {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	result := &BatchPricing{Odds: make([]MatchOdds, 0, len(fixtures))}
+	for i, fixture := range fixtures {
+		if odds[i] == nil {
+			result.Errors = append(result.Errors, FixtureError{
+				Index:   i,
+				Fixture: fmt.Sprintf("%s vs %s", fixture.HomeTeam, fixture.AwayTeam),
+				League:  fixture.League,
+				Error:   failures[i],
+			})
+			continue
+		}
+		result.Odds = append(result.Odds, *odds[i])
+	}
+	return result, nil
+}
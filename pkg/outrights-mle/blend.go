@@ -0,0 +1,99 @@
+package outrightsmle
+
+import "fmt"
+
+// RatingSet is an external set of team ratings on the MLE scale: attack and defense are log
+// scoring rates, as in MLEParams. Ratings on another scale, such as Elo, need converting first
+// (e.g. by pricing fixtures from them and passing the prices through ImpliedRatings)
+type RatingSet struct {
+	Name           string             `json:"name"`   // e.g. "clubelo", "market"
+	Weight         float64            `json:"weight"` // Relative weight against MLEWeight and the other sets
+	AttackRatings  map[string]float64 `json:"attack_ratings"`
+	DefenseRatings map[string]float64 `json:"defense_ratings"`
+}
+
+// RatingsBlend mixes the fitted ratings with external rating sets before simulation and pricing
+// Each team's rating is the weighted mean over the fit and the sets that rate it, so a set may
+// cover only some teams; the fit's home advantage and rho are kept
+type RatingsBlend struct {
+	MLEWeight float64     `json:"mle_weight"` // Weight of the fitted ratings (0 = external sets only, where they rate the team)
+	Sources   []RatingSet `json:"sources"`
+}
+
+// RatingSet returns the market-implied ratings as a source for a RatingsBlend
+func (m *MarketRatings) RatingSet(weight float64) RatingSet {
+	return RatingSet{
+		Name:           "market",
+		Weight:         weight,
+		AttackRatings:  m.Params.AttackRatings,
+		DefenseRatings: m.Params.DefenseRatings,
+	}
+}
+
+// validate checks weights are usable and each set rates attack and defense for the same teams
+func (blend *RatingsBlend) validate() error {
+	if blend.MLEWeight < 0 {
+		return fmt.Errorf("ratings blend MLE weight must not be negative, got %v", blend.MLEWeight)
+	}
+	if len(blend.Sources) == 0 {
+		return fmt.Errorf("ratings blend has no sources")
+	}
+	for i, source := range blend.Sources {
+		name := source.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if source.Weight < 0 {
+			return fmt.Errorf("ratings blend source %s weight must not be negative, got %v", name, source.Weight)
+		}
+		if err := source.validateTeams("ratings blend source " + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTeams checks the set rates attack and defense for the same teams; label names the set
+// in errors
+func (set *RatingSet) validateTeams(label string) error {
+	if len(set.AttackRatings) != len(set.DefenseRatings) {
+		return fmt.Errorf("%s rates %d teams' attack but %d teams' defense",
+			label, len(set.AttackRatings), len(set.DefenseRatings))
+	}
+	for _, team := range sortedKeys(set.AttackRatings) {
+		if _, exists := set.DefenseRatings[team]; !exists {
+			return fmt.Errorf("%s has an attack rating but no defense rating for %s", label, team)
+		}
+	}
+	return nil
+}
+
+// apply returns a copy of params with each fitted team's ratings blended with the sources
+// Teams no source rates (or whose total weight is zero) keep their fitted ratings
+func (blend *RatingsBlend) apply(params *MLEParams) (*MLEParams, error) {
+	if err := blend.validate(); err != nil {
+		return nil, err
+	}
+
+	blended := *params
+	blended.AttackRatings = make(map[string]float64, len(params.AttackRatings))
+	blended.DefenseRatings = make(map[string]float64, len(params.DefenseRatings))
+	for team, attack := range params.AttackRatings {
+		defense := params.DefenseRatings[team]
+		weight := blend.MLEWeight
+		attackSum, defenseSum := blend.MLEWeight*attack, blend.MLEWeight*defense
+		for _, source := range blend.Sources {
+			if sourceAttack, exists := source.AttackRatings[team]; exists {
+				attackSum += source.Weight * sourceAttack
+				defenseSum += source.Weight * source.DefenseRatings[team]
+				weight += source.Weight
+			}
+		}
+		if weight > 0 {
+			attack, defense = attackSum/weight, defenseSum/weight
+		}
+		blended.AttackRatings[team] = attack
+		blended.DefenseRatings[team] = defense
+	}
+	return &blended, nil
+}
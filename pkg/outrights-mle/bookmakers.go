@@ -0,0 +1,69 @@
+package outrightsmle
+
+import "time"
+
+// FixtureOdds is one bookmaker's 1X2 prices for an upcoming fixture
+type FixtureOdds struct {
+	League    string     `json:"league"`
+	Date      string     `json:"date"` // Kick-off date (YYYY-MM-DD, UTC)
+	HomeTeam  string     `json:"home_team"`
+	AwayTeam  string     `json:"away_team"`
+	Bookmaker string     `json:"bookmaker"`
+	Prices    [3]float64 `json:"prices"` // Decimal [home, draw, away]
+}
+
+// Fixture returns the "{Home} vs {Away}" name used by MatchOdds and events
+func (odds FixtureOdds) Fixture() string {
+	return odds.HomeTeam + " vs " + odds.AwayTeam
+}
+
+// ImpliedProbabilities returns the prices' implied probabilities with the overround removed
+// An incomplete book, with a price of 1 or less, gives zeros rather than a book over the rest
+func (odds FixtureOdds) ImpliedProbabilities() [3]float64 {
+	var probabilities [3]float64
+	total := 0.0
+	for i, price := range odds.Prices {
+		if price <= 1 {
+			return [3]float64{}
+		}
+		probabilities[i] = 1 / price
+		total += probabilities[i]
+	}
+	for i := range probabilities {
+		probabilities[i] /= total
+	}
+	return probabilities
+}
+
+// OutrightOdds is one bookmaker's prices for a league outright market
+type OutrightOdds struct {
+	League    string             `json:"league"`
+	Market    string             `json:"market"` // e.g. "Winner"
+	Bookmaker string             `json:"bookmaker"`
+	Prices    map[string]float64 `json:"prices"` // Team -> decimal odds, in the Market.Prices form
+}
+
+// OddsSnapshot holds prices fetched at one time, stored alongside the events file by cmd/fetch-events
+type OddsSnapshot struct {
+	FetchedAt time.Time      `json:"fetched_at"`
+	Fixtures  []FixtureOdds  `json:"fixtures"`
+	Outrights []OutrightOdds `json:"outrights,omitempty"`
+	Unmatched []string       `json:"unmatched,omitempty"` // Bookmaker team names not mapped to a team
+}
+
+// BestOutrightPrices returns the best price per team across bookmakers for one league market,
+// in the Market.Prices form
+func BestOutrightPrices(outrights []OutrightOdds, league, market string) map[string]float64 {
+	prices := make(map[string]float64)
+	for _, odds := range outrights {
+		if odds.League != league || odds.Market != market {
+			continue
+		}
+		for team, price := range odds.Prices {
+			if price > prices[team] {
+				prices[team] = price
+			}
+		}
+	}
+	return prices
+}
@@ -0,0 +1,55 @@
+package outrightsmle
+
+import "fmt"
+
+// validateRatingBounds checks the rating clamps sit either side of the zero-sum average and the
+// lambda cap is not negative
+func validateRatingBounds(simParams *SimParams) error {
+	if simParams.MinRating > 0 {
+		return fmt.Errorf("min rating must not be positive, got %v", simParams.MinRating)
+	}
+	if simParams.MaxRating < 0 {
+		return fmt.Errorf("max rating must not be negative, got %v", simParams.MaxRating)
+	}
+	if simParams.MaxLambda < 0 {
+		return fmt.Errorf("max lambda must not be negative, got %v", simParams.MaxLambda)
+	}
+	return nil
+}
+
+// boundRating clamps an attack or defense rating to [MinRating, MaxRating]; a zero bound is off
+func boundRating(rating float64, simParams *SimParams) float64 {
+	if simParams.MinRating < 0 && rating < simParams.MinRating {
+		return simParams.MinRating
+	}
+	if simParams.MaxRating > 0 && rating > simParams.MaxRating {
+		return simParams.MaxRating
+	}
+	return rating
+}
+
+// capLambda limits an expected goals rate to MaxLambda, and reports whether it did
+func capLambda(lambda float64, simParams *SimParams) (float64, bool) {
+	if simParams.MaxLambda > 0 && lambda > simParams.MaxLambda {
+		return simParams.MaxLambda, true
+	}
+	return lambda, false
+}
+
+// cappedLambda is capLambda without the report
+func cappedLambda(lambda float64, simParams *SimParams) float64 {
+	capped, _ := capLambda(lambda, simParams)
+	return capped
+}
+
+// boundRatings clamps every rating to the SimParams bounds
+func (s *MLESolver) boundRatings() {
+	simParams := s.options.SimParams
+	if simParams.MinRating == 0 && simParams.MaxRating == 0 {
+		return
+	}
+	for team := range s.teamNames {
+		s.params.AttackRatings[team] = boundRating(s.params.AttackRatings[team], simParams)
+		s.params.DefenseRatings[team] = boundRating(s.params.DefenseRatings[team], simParams)
+	}
+}
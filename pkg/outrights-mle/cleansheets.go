@@ -0,0 +1,110 @@
+package outrightsmle
+
+import "math"
+
+// CleanSheetForecast is a team's season clean-sheet count: those kept so far plus the chance of
+// keeping one in each remaining fixture
+type CleanSheetForecast struct {
+	Kept         int       `json:"kept"`         // Clean sheets already kept this season
+	Remaining    int       `json:"remaining"`    // Remaining fixtures priced
+	Expected     float64   `json:"expected"`     // Expected season total (kept plus remaining)
+	Distribution []float64 `json:"distribution"` // index k -> P(k clean sheets over the season)
+}
+
+// calculateCleanSheets prices each team's clean sheets in the remaining fixtures from ScoreMatrix
+// probabilities of the opponent scoring zero, convolving the fixtures into a season distribution
+// A pinned score counts as kept or not; a pinned outcome conditions the matrix on that outcome
+func calculateCleanSheets(teamNames []string, params MLEParams, simParams *SimParams, events []Event,
+	remainingFixtures []string, fixed map[string]*FixedResult) map[string]*CleanSheetForecast {
+	kept := make(map[string]int, len(teamNames))
+	for _, event := range events {
+		if len(event.Score) != 2 {
+			continue
+		}
+		homeTeam, awayTeam := parseEventName(event.Name)
+		if event.Score[1] == 0 {
+			kept[homeTeam]++
+		}
+		if event.Score[0] == 0 {
+			kept[awayTeam]++
+		}
+	}
+
+	// Per-fixture clean-sheet probabilities for each team
+	chances := make(map[string][]float64, len(teamNames))
+	model := params.countModel()
+	for _, fixtureName := range remainingFixtures {
+		homeTeam, awayTeam := parseEventName(fixtureName)
+		if homeTeam == "" || awayTeam == "" {
+			continue
+		}
+		homeCleanSheet, awayCleanSheet := fixtureCleanSheets(params, model, simParams, homeTeam, awayTeam, fixed[fixtureName])
+		chances[homeTeam] = append(chances[homeTeam], homeCleanSheet)
+		chances[awayTeam] = append(chances[awayTeam], awayCleanSheet)
+	}
+
+	forecasts := make(map[string]*CleanSheetForecast, len(teamNames))
+	for _, team := range teamNames {
+		// Poisson-binomial distribution of the remaining fixtures, shifted by those already kept
+		remaining := []float64{1}
+		expected := float64(kept[team])
+		for _, p := range chances[team] {
+			next := make([]float64, len(remaining)+1)
+			for k, q := range remaining {
+				next[k] += q * (1 - p)
+				next[k+1] += q * p
+			}
+			remaining = next
+			expected += p
+		}
+		distribution := make([]float64, kept[team]+len(remaining))
+		copy(distribution[kept[team]:], remaining)
+		forecasts[team] = &CleanSheetForecast{
+			Kept:         kept[team],
+			Remaining:    len(chances[team]),
+			Expected:     expected,
+			Distribution: distribution,
+		}
+	}
+	return forecasts
+}
+
+// fixtureCleanSheets returns the probabilities that the home and away sides keep a clean sheet
+// under the params' count model
+func fixtureCleanSheets(params MLEParams, model CountModel, simParams *SimParams, homeTeam, awayTeam string, fixed *FixedResult) (float64, float64) {
+	if fixed != nil && fixed.HomeGoals != nil && fixed.AwayGoals != nil {
+		var home, away float64
+		if *fixed.AwayGoals == 0 {
+			home = 1
+		}
+		if *fixed.HomeGoals == 0 {
+			away = 1
+		}
+		return home, away
+	}
+
+	lambdaHome := cappedLambda(math.Exp(params.Intercept+params.AttackRatings[homeTeam]-params.DefenseRatings[awayTeam]+params.HomeAdvantage), simParams)
+	lambdaAway := cappedLambda(math.Exp(params.Intercept+params.AttackRatings[awayTeam]-params.DefenseRatings[homeTeam]), simParams)
+	m := NewCountScoreMatrix(model, lambdaHome, lambdaAway, params.Rho, simParams.GoalSimulationBound)
+
+	var total, home, away float64
+	for homeGoals := 0; homeGoals <= m.HomeGoals; homeGoals++ {
+		for awayGoals := 0; awayGoals <= m.AwayGoals; awayGoals++ {
+			if fixed != nil && !fixed.matches(homeGoals, awayGoals) {
+				continue
+			}
+			p := m.Matrix[homeGoals][awayGoals]
+			total += p
+			if awayGoals == 0 {
+				home += p
+			}
+			if homeGoals == 0 {
+				away += p
+			}
+		}
+	}
+	if total <= 0 {
+		return 0, 0
+	}
+	return home / total, away / total
+}
@@ -0,0 +1,122 @@
+package outrightsmle
+
+import "math"
+
+// Dispersion bounds for the CMP fit: below 1 goals are overdispersed relative to Poisson, above
+// 1 underdispersed. Far outside these football scores stop looking like either
+const (
+	minDispersion = 0.5
+	maxDispersion = 3.0
+)
+
+// cmpMaxTerms bounds the normalizing series; with the dispersion bounds it only binds for rates
+// far beyond any football score
+const cmpMaxTerms = 512
+
+// cmpRescale is the running total above which the series is scaled down, so rates far beyond
+// football scores cannot overflow it
+const cmpRescale = 1e200
+
+// cmpCountModel is the Conway-Maxwell-Poisson count model, P(k) = λ^k / (k!)^ν / Z(λ, ν). It
+// holds j^−ν for its dispersion, the factors between successive series terms after λ, so the
+// terms cost a multiplication each
+type cmpCountModel struct {
+	nu            float64
+	inversePowers []float64
+}
+
+// newCMPCountModel creates the CMP model with dispersion nu
+func newCMPCountModel(nu float64) CountModel {
+	model := &cmpCountModel{nu: nu, inversePowers: make([]float64, cmpMaxTerms)}
+	model.inversePowers[0] = 1
+	for j := 1; j < cmpMaxTerms; j++ {
+		model.inversePowers[j] = math.Exp(-nu * math.Log(float64(j)))
+	}
+	return model
+}
+
+func (m *cmpCountModel) Name() string   { return CountModelCMP }
+func (m *cmpCountModel) Shape() float64 { return m.nu }
+
+// series sums the normalizing series Z = Σ λ^j/(j!)^ν from the ratios between successive terms,
+// λ·j^−ν, returning log Z and the mean. Terms rise to a peak near j = λ^(1/ν), then fall faster
+// than geometrically; they are summed until they stop changing the total
+func (m *cmpCountModel) series(logLambda float64) (logZ, mean float64) {
+	peak := int(math.Min(math.Exp(logLambda/m.nu), cmpMaxTerms-1))
+	lambda := math.Exp(logLambda)
+
+	var total, first, logScale float64
+	term := 1.0
+	for j := 0; j < cmpMaxTerms; j++ {
+		if j > 0 {
+			term *= lambda * m.inversePowers[j]
+		}
+		total += term
+		first += term * float64(j)
+		if j > peak && term <= total*skellamTolerance {
+			break
+		}
+		if total > cmpRescale {
+			term, total, first = term/cmpRescale, total/cmpRescale, first/cmpRescale
+			logScale += math.Log(cmpRescale)
+		}
+	}
+	return logScale + math.Log(total), first / total
+}
+
+// LogProb returns log P(K = goals) = goals·log λ − ν·log goals! − log Z
+func (m *cmpCountModel) LogProb(logLambda float64, goals int) float64 {
+	logZ, _ := m.series(logLambda)
+	return float64(goals)*logLambda - m.nu*lgammaFactorial(goals) - logZ
+}
+
+// Score returns goals − E[K], as the derivative of log Z in log λ is the mean
+func (m *cmpCountModel) Score(logLambda float64, goals int) float64 {
+	_, mean := m.series(logLambda)
+	return float64(goals) - mean
+}
+
+// Probabilities returns P(K = k) for k = 0..bound
+func (m *cmpCountModel) Probabilities(lambda float64, bound int) []float64 {
+	probabilities := make([]float64, bound+1)
+	if lambda <= 0 {
+		probabilities[0] = 1
+		return probabilities
+	}
+	logLambda := math.Log(lambda)
+	logZ, _ := m.series(logLambda)
+	for k := range probabilities {
+		probabilities[k] = math.Exp(float64(k)*logLambda - m.nu*lgammaFactorial(k) - logZ)
+	}
+	return probabilities
+}
+
+// Mean returns E[K]
+func (m *cmpCountModel) Mean(lambda float64) float64 {
+	if lambda <= 0 {
+		return 0
+	}
+	_, mean := m.series(math.Log(lambda))
+	return mean
+}
+
+// CMPProb calculates the Conway-Maxwell-Poisson probability P(X = k) = λ^k / (k!)^ν / Z(λ, ν).
+// ν = 1 is Poisson(λ); a larger ν concentrates the goals, a smaller one spreads them. λ is the
+// rate on the ratings' scale, not the mean unless ν = 1 (see CMPMean)
+func CMPProb(lambda, nu float64, k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	if lambda <= 0 {
+		if k == 0 {
+			return 1.0
+		}
+		return 0
+	}
+	return math.Exp(newCMPCountModel(nu).LogProb(math.Log(lambda), k))
+}
+
+// CMPMean returns the expected goals of CMP(λ, ν)
+func CMPMean(lambda, nu float64) float64 {
+	return newCMPCountModel(nu).Mean(lambda)
+}
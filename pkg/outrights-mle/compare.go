@@ -0,0 +1,106 @@
+package outrightsmle
+
+import "math"
+
+// Fields reported by Compare
+const (
+	MoveMark           = "mark"
+	MoveExpectedPoints = "expected_points"
+	MoveAttackRating   = "attack_rating"
+	MoveDefenseRating  = "defense_rating"
+)
+
+// CompareOptions sets the smallest change Compare reports for each kind of value
+// A move is reported when its absolute change is strictly greater than the threshold
+type CompareOptions struct {
+	MarkThreshold   float64 `json:"mark_threshold"`   // Mark value (probability) change, e.g. 0.02
+	PointsThreshold float64 `json:"points_threshold"` // Expected season points change
+	RatingThreshold float64 `json:"rating_threshold"` // Attack or defense rating change
+}
+
+// DefaultCompareOptions returns thresholds suited to a day-on-day comparison
+func DefaultCompareOptions() CompareOptions {
+	return CompareOptions{
+		MarkThreshold:   0.02,
+		PointsThreshold: 1.0,
+		RatingThreshold: 0.05,
+	}
+}
+
+// TeamMove is one value that moved by more than its threshold between two results
+type TeamMove struct {
+	League string  `json:"league"`
+	Team   string  `json:"team"`
+	Field  string  `json:"field"`            // MoveMark, MoveExpectedPoints, MoveAttackRating or MoveDefenseRating
+	Market string  `json:"market,omitempty"` // Mark moves only
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Change float64 `json:"change"` // After - Before
+}
+
+// Comparison lists the moves between two results by league, then team (in the first result's
+// table order), then field
+type Comparison struct {
+	Moves      []TeamMove `json:"moves"`
+	OnlyBefore []string   `json:"only_before,omitempty"` // "league/team" present only in the first result
+	OnlyAfter  []string   `json:"only_after,omitempty"`  // "league/team" present only in the second result
+}
+
+// Compare diffs two MultiLeagueResults (typically yesterday's and today's run) and reports teams
+// whose marks, expected points or ratings moved by more than the thresholds
+// Teams are matched by league and name; mark moves are relative to the same market in both results
+func Compare(before, after *MultiLeagueResult, options CompareOptions) Comparison {
+	var comparison Comparison
+	for _, league := range sortedKeys(before.Leagues) {
+		afterTeams := make(map[string]Team, len(after.Leagues[league]))
+		for _, team := range after.Leagues[league] {
+			afterTeams[team.Name] = team
+		}
+
+		for _, teamBefore := range before.Leagues[league] {
+			teamAfter, exists := afterTeams[teamBefore.Name]
+			if !exists {
+				comparison.OnlyBefore = append(comparison.OnlyBefore, league+"/"+teamBefore.Name)
+				continue
+			}
+			add := func(field, market string, valueBefore, valueAfter, threshold float64) {
+				if math.Abs(valueAfter-valueBefore) > threshold {
+					comparison.Moves = append(comparison.Moves, TeamMove{
+						League: league,
+						Team:   teamBefore.Name,
+						Field:  field,
+						Market: market,
+						Before: valueBefore,
+						After:  valueAfter,
+						Change: valueAfter - valueBefore,
+					})
+				}
+			}
+			add(MoveExpectedPoints, "", teamBefore.ExpectedSeasonPoints, teamAfter.ExpectedSeasonPoints, options.PointsThreshold)
+			add(MoveAttackRating, "", teamBefore.AttackRating, teamAfter.AttackRating, options.RatingThreshold)
+			add(MoveDefenseRating, "", teamBefore.DefenseRating, teamAfter.DefenseRating, options.RatingThreshold)
+
+			for _, market := range sortedKeys(before.MarkValues[league]) {
+				markBefore, inBefore := before.MarkValues[league][market][teamBefore.Name]
+				markAfter, inAfter := after.MarkValues[league][market][teamBefore.Name]
+				if inBefore && inAfter {
+					add(MoveMark, market, markBefore, markAfter, options.MarkThreshold)
+				}
+			}
+		}
+	}
+
+	for _, league := range sortedKeys(after.Leagues) {
+		beforeTeams := make(map[string]bool, len(before.Leagues[league]))
+		for _, team := range before.Leagues[league] {
+			beforeTeams[team.Name] = true
+		}
+		for _, team := range after.Leagues[league] {
+			if !beforeTeams[team.Name] {
+				comparison.OnlyAfter = append(comparison.OnlyAfter, league+"/"+team.Name)
+			}
+		}
+	}
+
+	return comparison
+}
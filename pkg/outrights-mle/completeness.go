@@ -0,0 +1,87 @@
+package outrightsmle
+
+import "sort"
+
+// SeasonCompleteness reports how much of one league season is present in a set of results
+type SeasonCompleteness struct {
+	League       string         `json:"league"`
+	Season       string         `json:"season"`
+	Teams        int            `json:"teams"`                   // Distinct teams with a result this season
+	Expected     int            `json:"expected"`                // Matches in a full season of the league's format
+	Played       int            `json:"played"`                  // Distinct matches present
+	Duplicates   int            `json:"duplicates,omitempty"`    // Results repeating a (date, home, away) already counted
+	MissingGames map[string]int `json:"missing_games,omitempty"` // Games each team is short of a full season
+	Complete     bool           `json:"complete"`
+}
+
+// CheckCompleteness compares each (league, season) in the results with the number of matches
+// its format implies (teams x (teams - 1) x rounds, e.g. 380 for ENG1), so a truncated
+// download shows up before it is fitted. Cup matches are ignored, as are leagues without a
+// format. The current season is reported as partial like any other short season; callers
+// decide whether that is expected. Reports are sorted by league, then season
+func CheckCompleteness(results []MatchResult, formats map[string]LeagueFormat) []SeasonCompleteness {
+	type seasonKey struct{ league, season string }
+	type matchKey struct{ date, home, away string }
+
+	games := make(map[seasonKey]map[string]int)
+	seen := make(map[seasonKey]map[matchKey]bool)
+	duplicates := make(map[seasonKey]int)
+	for _, result := range results {
+		if result.Competition != "" {
+			continue
+		}
+		if _, exists := formats[result.League]; !exists {
+			continue
+		}
+		key := seasonKey{result.League, result.Season}
+		if seen[key] == nil {
+			seen[key] = make(map[matchKey]bool)
+			games[key] = make(map[string]int)
+		}
+		match := matchKey{result.Date, result.HomeTeam, result.AwayTeam}
+		if seen[key][match] {
+			duplicates[key]++
+			continue
+		}
+		seen[key][match] = true
+		games[key][result.HomeTeam]++
+		games[key][result.AwayTeam]++
+	}
+
+	reports := make([]SeasonCompleteness, 0, len(seen))
+	for key, teamGames := range games {
+		format := formats[key.league]
+		rounds := format.Rounds
+		if rounds < 1 {
+			rounds = 1
+		}
+		perTeam := 2 * (format.Teams - 1) * rounds
+
+		report := SeasonCompleteness{
+			League:     key.league,
+			Season:     key.season,
+			Teams:      len(teamGames),
+			Expected:   format.Teams * (format.Teams - 1) * rounds,
+			Played:     len(seen[key]),
+			Duplicates: duplicates[key],
+		}
+		for team, played := range teamGames {
+			if played < perTeam {
+				if report.MissingGames == nil {
+					report.MissingGames = make(map[string]int)
+				}
+				report.MissingGames[team] = perTeam - played
+			}
+		}
+		report.Complete = report.Played == report.Expected && report.Teams == format.Teams && report.MissingGames == nil
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].League != reports[j].League {
+			return reports[i].League < reports[j].League
+		}
+		return reports[i].Season < reports[j].Season
+	})
+	return reports
+}
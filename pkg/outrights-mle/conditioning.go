@@ -0,0 +1,251 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Fixed result outcomes for FixedResult.Outcome
+const (
+	OutcomeHomeWin = "home"
+	OutcomeDraw    = "draw"
+	OutcomeAwayWin = "away"
+)
+
+// maxConditionedResamples bounds rejection sampling of a score matching a fixed outcome
+const maxConditionedResamples = 100
+
+// FixedResult pins a remaining fixture's result in the season simulation
+// Set Outcome to keep simulated scores (and so goal difference) consistent with that outcome, or
+// HomeGoals and AwayGoals for an exact score
+type FixedResult struct {
+	Fixture   string `json:"fixture"`              // "{Home} vs {Away}"
+	Outcome   string `json:"outcome,omitempty"`    // "home", "draw" or "away"
+	HomeGoals *int   `json:"home_goals,omitempty"` // Exact score (both goals must be set)
+	AwayGoals *int   `json:"away_goals,omitempty"`
+}
+
+// Conditioning fixes future results for a what-if simulation
+type Conditioning struct {
+	Results []FixedResult `json:"results"`
+}
+
+// score returns the fixed score, or resamples with draw until the simulated score matches the fixed
+// outcome; an outcome that is very unlikely under the ratings takes fallback's draw instead
+func (fixed *FixedResult) score(homeGoals, awayGoals int, draw, fallback func() (int, int)) (int, int) {
+	if fixed.HomeGoals != nil && fixed.AwayGoals != nil {
+		return *fixed.HomeGoals, *fixed.AwayGoals
+	}
+	for i := 0; i < maxConditionedResamples; i++ {
+		if fixed.matches(homeGoals, awayGoals) {
+			return homeGoals, awayGoals
+		}
+		homeGoals, awayGoals = draw()
+	}
+	return fallback()
+}
+
+// conditionalScores holds the cells of a score matrix with a fixed outcome, for sampling
+type conditionalScores struct {
+	scores     [][2]int
+	cumulative []float64
+}
+
+// newConditionalScores collects matrix's cells with the fixed outcome and their cumulative probabilities
+func newConditionalScores(fixed *FixedResult, matrix *ScoreMatrix) *conditionalScores {
+	conditional := &conditionalScores{}
+	total := 0.0
+	for homeGoals, row := range matrix.Matrix {
+		for awayGoals, probability := range row {
+			if !fixed.matches(homeGoals, awayGoals) {
+				continue
+			}
+			total += probability
+			conditional.scores = append(conditional.scores, [2]int{homeGoals, awayGoals})
+			conditional.cumulative = append(conditional.cumulative, total)
+		}
+	}
+	return conditional
+}
+
+// sample draws a score in proportion to its probability given the outcome
+func (c *conditionalScores) sample(rng randSource) (int, int) {
+	u := rng.Float64() * c.cumulative[len(c.cumulative)-1]
+	i := sort.SearchFloat64s(c.cumulative, u)
+	if i == len(c.scores) {
+		i--
+	}
+	return c.scores[i][0], c.scores[i][1]
+}
+
+// matches reports whether a score has the fixed outcome
+func (fixed *FixedResult) matches(homeGoals, awayGoals int) bool {
+	switch fixed.Outcome {
+	case OutcomeHomeWin:
+		return homeGoals > awayGoals
+	case OutcomeAwayWin:
+		return homeGoals < awayGoals
+	default:
+		return homeGoals == awayGoals
+	}
+}
+
+// byFixture indexes fixed results by fixture name (nil-safe)
+func (c *Conditioning) byFixture() map[string]*FixedResult {
+	if c == nil || len(c.Results) == 0 {
+		return nil
+	}
+	fixed := make(map[string]*FixedResult, len(c.Results))
+	for i := range c.Results {
+		fixed[c.Results[i].Fixture] = &c.Results[i]
+	}
+	return fixed
+}
+
+// simulatedFixtures returns the fixtures played in simulations
+func simulatedFixtures(simulations map[string]*SimPoints) map[string]bool {
+	fixtures := make(map[string]bool)
+	for _, simPoints := range simulations {
+		if simPoints == nil {
+			continue
+		}
+		for _, fixture := range simPoints.Fixtures {
+			fixtures[fixture] = true
+		}
+	}
+	return fixtures
+}
+
+// validate checks each fixed result is well formed and is one of the remaining fixtures
+func (c *Conditioning) validate(remaining map[string]bool) error {
+	if c == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, fixed := range c.Results {
+		if seen[fixed.Fixture] {
+			return fmt.Errorf("fixture %q is fixed more than once", fixed.Fixture)
+		}
+		seen[fixed.Fixture] = true
+
+		hasScore := fixed.HomeGoals != nil || fixed.AwayGoals != nil
+		switch {
+		case hasScore && (fixed.HomeGoals == nil || fixed.AwayGoals == nil):
+			return fmt.Errorf("fixture %q: both home_goals and away_goals are required for an exact score", fixed.Fixture)
+		case hasScore && (*fixed.HomeGoals < 0 || *fixed.AwayGoals < 0):
+			return fmt.Errorf("fixture %q: goals cannot be negative", fixed.Fixture)
+		case hasScore && fixed.Outcome != "" && !fixed.matches(*fixed.HomeGoals, *fixed.AwayGoals):
+			return fmt.Errorf("fixture %q: score %d-%d contradicts outcome %q", fixed.Fixture, *fixed.HomeGoals, *fixed.AwayGoals, fixed.Outcome)
+		case !hasScore && fixed.Outcome != OutcomeHomeWin && fixed.Outcome != OutcomeDraw && fixed.Outcome != OutcomeAwayWin:
+			return fmt.Errorf("fixture %q: outcome must be %q, %q or %q, got %q", fixed.Fixture, OutcomeHomeWin, OutcomeDraw, OutcomeAwayWin, fixed.Outcome)
+		}
+
+		if !remaining[fixed.Fixture] {
+			return fmt.Errorf("fixture %q is not a remaining fixture in any simulated league", fixed.Fixture)
+		}
+	}
+	return nil
+}
+
+// RunConditionalSimulation re-runs only the season simulation stage of a RunMLESolver or
+// PredictSeason result with the given results fixed, reusing the fitted ratings; leagues without a
+// fixed result are copied unchanged. The original result is not modified
+func RunConditionalSimulation(result *MultiLeagueResult, conditioning Conditioning) (*MultiLeagueResult, error) {
+	if result == nil || result.simInputs == nil {
+		return nil, fmt.Errorf("result must come from RunMLESolver or PredictSeason, with its simulations not released")
+	}
+	if result.simInputs.options.RatingsOnly {
+		return nil, fmt.Errorf("result has no season simulation to condition: RatingsOnly was set")
+	}
+	for _, league := range sortedKeys(result.Simulations) {
+		if _, err := result.simulation(league); err != nil {
+			return nil, err
+		}
+	}
+	if err := conditioning.validate(simulatedFixtures(result.Simulations)); err != nil {
+		return nil, fmt.Errorf("conditioning failed: %w", err)
+	}
+
+	// Only leagues containing a fixed fixture need re-simulating
+	fixed := conditioning.byFixture()
+	var leagues []string
+	for _, league := range sortedKeys(result.Simulations) {
+		for _, fixture := range result.Simulations[league].Fixtures {
+			if fixed[fixture] != nil {
+				leagues = append(leagues, league)
+				break
+			}
+		}
+	}
+
+	inputs := *result.simInputs
+	inputs.options.Conditioning = &conditioning
+
+	conditional := &MultiLeagueResult{
+		Leagues:       copyMap(result.Leagues),
+		Markets:       result.Markets,
+		MarkValues:    copyMap(result.MarkValues),
+		MarkStdErrors: copyMap(result.MarkStdErrors),
+		PlaceValues:   copyMap(result.PlaceValues),
+		MarkOdds:      copyMap(result.MarkOdds),
+		BookPrices:    copyMap(result.BookPrices),
+		LeagueChanges: result.LeagueChanges,
+		Fingerprint:   result.Fingerprint,
+		MatchOdds:     result.MatchOdds,
+		MLEParams:     result.MLEParams,
+		Diagnostics:   copyMap(result.Diagnostics),
+		LatestSeason:  result.LatestSeason,
+		TotalMatches:  result.TotalMatches,
+		Simulations:   copyMap(result.Simulations),
+		Timings: TimingBreakdown{
+			Simulation: make(map[string]time.Duration),
+			Markets:    make(map[string]time.Duration),
+		},
+		simInputs: &inputs,
+	}
+
+	startTime := time.Now()
+	outcomes := simulateLeagues(leagues, &inputs)
+	conditional.applyOutcomes(outcomes)
+	conditional.ProcessingTime = time.Since(startTime)
+	if inputs.options.Deterministic {
+		conditional.clearTimings()
+	}
+
+	return conditional, nil
+}
+
+// copyMap returns a shallow copy of a map
+func copyMap[V any](m map[string]V) map[string]V {
+	copied := make(map[string]V, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// sortedKeys returns a map's keys in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitByTeams splits a team-keyed map into the entries for the given teams and the rest
+func splitByTeams[V any](m map[string]V, teams []string) (map[string]V, map[string]V) {
+	included := make(map[string]V)
+	rest := make(map[string]V)
+	for team, value := range m {
+		if containsString(teams, team) {
+			included[team] = value
+		} else {
+			rest[team] = value
+		}
+	}
+	return included, rest
+}
@@ -0,0 +1,311 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+)
+
+// Count model identifiers for SimParams.CountModel
+const (
+	CountModelPoisson = "poisson" // Goals are Poisson given the ratings' rates
+	CountModelCMP     = "cmp"     // Conway-Maxwell-Poisson goals with a fitted dispersion
+	CountModelWeibull = "weibull" // Weibull inter-arrival goals with a fitted shape
+)
+
+// CountModel is the distribution of one side's goals given its rate λ, where log λ is the
+// ratings' linear predictor, and the model's shape parameter. The likelihood, score matrices and
+// season simulation all draw on it. A model is immutable once created, so it can be shared
+// between goroutines
+type CountModel interface {
+	Name() string   // SimParams.CountModel identifier
+	Shape() float64 // CMP dispersion or Weibull shape, 1 being Poisson; 0 for Poisson itself
+
+	// LogProb returns log P(goals) at log rate logLambda
+	LogProb(logLambda float64, goals int) float64
+
+	// Score returns the derivative of LogProb in log λ, the residual that the ratings' gradient
+	// steps follow: goals − λ for Poisson
+	Score(logLambda float64, goals int) float64
+
+	// Probabilities returns P(k) for k = 0..bound at rate lambda
+	Probabilities(lambda float64, bound int) []float64
+
+	// Mean returns the expected goals at rate lambda, which is lambda only for Poisson
+	Mean(lambda float64) float64
+}
+
+// shapedCountModel describes a count model with a fitted shape: how to create it and the bounds
+// its shape is fitted within
+type shapedCountModel struct {
+	create   func(shape float64) CountModel
+	min, max float64
+}
+
+// shapedCountModels lists the count models beyond Poisson by SimParams.CountModel identifier
+var shapedCountModels = map[string]shapedCountModel{
+	CountModelCMP:     {newCMPCountModel, minDispersion, maxDispersion},
+	CountModelWeibull: {newWeibullCountModel, minWeibullShape, maxWeibullShape},
+}
+
+// NewCountModel creates the named count model with the given shape; an empty name is Poisson,
+// which takes no shape, and a zero shape is 1
+func NewCountModel(name string, shape float64) (CountModel, error) {
+	if name == "" || name == CountModelPoisson {
+		return poissonCountModel{}, nil
+	}
+	model, exists := shapedCountModels[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown count model %q (expected %q, %q or %q)", name, CountModelPoisson, CountModelCMP, CountModelWeibull)
+	}
+	if shape == 0 {
+		shape = 1
+	}
+	if shape < model.min || shape > model.max {
+		return nil, fmt.Errorf("%s count shape must be between %v and %v, got %v", name, model.min, model.max, shape)
+	}
+	return model.create(shape), nil
+}
+
+// poissonCountModel is the default count model. The fit and simulation keep their own Poisson
+// loops, which this reproduces
+type poissonCountModel struct{}
+
+func (poissonCountModel) Name() string   { return CountModelPoisson }
+func (poissonCountModel) Shape() float64 { return 0 }
+
+func (poissonCountModel) LogProb(logLambda float64, goals int) float64 {
+	return float64(goals)*logLambda - math.Exp(logLambda) - lgammaFactorial(goals)
+}
+
+func (poissonCountModel) Score(logLambda float64, goals int) float64 {
+	return float64(goals) - math.Exp(logLambda)
+}
+
+func (poissonCountModel) Probabilities(lambda float64, bound int) []float64 {
+	probabilities := make([]float64, bound+1)
+	for k := range probabilities {
+		probabilities[k] = PoissonProb(lambda, k)
+	}
+	return probabilities
+}
+
+func (poissonCountModel) Mean(lambda float64) float64 { return lambda }
+
+// NewCountScoreMatrix creates a score matrix from a count model's goal probabilities for each
+// side, with the Dixon-Coles adjustment as NewScoreMatrix
+func NewCountScoreMatrix(model CountModel, lambdaHome, lambdaAway, rho float64, bound int) *ScoreMatrix {
+	if _, poisson := model.(poissonCountModel); poisson {
+		return NewScoreMatrix(lambdaHome, lambdaAway, rho, bound)
+	}
+	home := model.Probabilities(lambdaHome, bound)
+	away := model.Probabilities(lambdaAway, bound)
+	matrix := make([][]float64, bound+1)
+	for homeGoals := range matrix {
+		matrix[homeGoals] = make([]float64, bound+1)
+		for awayGoals := range matrix[homeGoals] {
+			matrix[homeGoals][awayGoals] = home[homeGoals] * away[awayGoals] * DixonColesAdjustment(homeGoals, awayGoals, rho)
+		}
+	}
+	return &ScoreMatrix{
+		HomeGoals: bound,
+		AwayGoals: bound,
+		Matrix:    matrix,
+	}
+}
+
+// countSamplerGoals bounds the goals a sampler tabulates; the tail beyond is negligible for any
+// football rate
+const countSamplerGoals = 40
+
+// goalSampler returns a sampler of one side's goals at rate lambda: samplePoisson for Poisson,
+// otherwise inverse transform over the model's cumulative probabilities, where a draw in the
+// untabulated tail takes the largest tabulated score
+func goalSampler(model CountModel, lambda float64) func(rng randSource) int {
+	if _, poisson := model.(poissonCountModel); poisson {
+		return func(rng randSource) int { return samplePoisson(rng, lambda) }
+	}
+	cumulative := model.Probabilities(lambda, countSamplerGoals)
+	for k := 1; k < len(cumulative); k++ {
+		cumulative[k] += cumulative[k-1]
+	}
+	return func(rng randSource) int {
+		u := rng.Float64()
+		for k, total := range cumulative {
+			if u < total {
+				return k
+			}
+		}
+		return len(cumulative) - 1
+	}
+}
+
+// validateCountModel checks SimParams.CountModel and CountShape, and the fit options a shaped
+// model can be combined with: the season home advantage and covariate steps, and the dynamic
+// filter, assume Poisson goals
+func validateCountModel(simParams *SimParams) error {
+	model, err := NewCountModel(simParams.CountModel, simParams.CountShape)
+	if err != nil {
+		return err
+	}
+	if _, poisson := model.(poissonCountModel); poisson {
+		return nil
+	}
+	switch {
+	case simParams.RatingModel == RatingModelDynamic:
+		return fmt.Errorf("count model %q is not supported with the %q rating model", model.Name(), RatingModelDynamic)
+	case simParams.FitSeasonHomeAdvantage:
+		return fmt.Errorf("count model %q is not supported with fit_season_home_advantage", model.Name())
+	case simParams.FitSeasonRho:
+		return fmt.Errorf("count model %q is not supported with fit_season_rho", model.Name())
+	case len(simParams.FormCovariates) > 0:
+		return fmt.Errorf("count model %q is not supported with form_covariates", model.Name())
+	}
+	return nil
+}
+
+// configuredCountModel returns the count model name and starting shape for params: SimParams'
+// with the shape defaulted to 1, or empty and zero for Poisson goals
+func configuredCountModel(simParams *SimParams) (string, float64) {
+	if _, shaped := shapedCountModels[simParams.CountModel]; !shaped {
+		return "", 0
+	}
+	if simParams.CountShape != 0 {
+		return simParams.CountModel, simParams.CountShape
+	}
+	return simParams.CountModel, 1
+}
+
+// initialCountModel returns the starting count model name and shape: the warm start's shape
+// when it used the same model, otherwise configuredCountModel
+func (s *MLESolver) initialCountModel() (string, float64) {
+	name, shape := configuredCountModel(s.options.SimParams)
+	if name != "" && s.initialParams != nil && s.initialParams.CountModel == name && s.initialParams.CountShape != 0 {
+		return name, s.initialParams.CountShape
+	}
+	return name, shape
+}
+
+// poissonGoals reports whether params price goals as Poisson
+func (p *MLEParams) poissonGoals() bool {
+	_, shaped := shapedCountModels[p.CountModel]
+	return !shaped
+}
+
+// countModel returns the params' count model; Poisson when they have none, or one this version
+// does not know
+func (p *MLEParams) countModel() CountModel {
+	model, err := NewCountModel(p.CountModel, p.CountShape)
+	if err != nil {
+		return poissonCountModel{}
+	}
+	return model
+}
+
+// goalModel returns the count model of the solver's current params, kept between calls until
+// the shape moves
+func (s *MLESolver) goalModel() CountModel {
+	name := s.params.CountModel
+	if s.params.poissonGoals() {
+		name = CountModelPoisson
+	}
+	if s.countModel == nil || s.countModel.Name() != name || s.countModel.Shape() != s.params.CountShape {
+		s.countModel = s.params.countModel()
+	}
+	return s.countModel
+}
+
+// countWinningMargins returns the margin bands for rates lambdaHome and lambdaAway: exact from
+// the Skellam distribution for Poisson goals, otherwise from a bound-goal score matrix
+func countWinningMargins(model CountModel, lambdaHome, lambdaAway, rho float64, bound int) WinningMargins {
+	if _, poisson := model.(poissonCountModel); poisson {
+		return NewSkellamDistribution(lambdaHome, lambdaAway, rho).WinningMargins()
+	}
+	return NewCountScoreMatrix(model, lambdaHome, lambdaAway, rho, bound).WinningMargins()
+}
+
+// countRates returns a match's capped log rates, as the Poisson likelihood computes them
+func (s *MLESolver) countRates(match MatchResult) (logHome, logAway float64, homeCapped, awayCapped bool) {
+	intercept := s.matchIntercept(match)
+	logHome = intercept + s.params.AttackRatings[match.HomeTeam] - s.params.DefenseRatings[match.AwayTeam] + s.matchHomeAdvantage(match)
+	logAway = intercept + s.params.AttackRatings[match.AwayTeam] - s.params.DefenseRatings[match.HomeTeam]
+	var lambdaHome, lambdaAway float64
+	if lambdaHome, homeCapped = capLambda(math.Exp(logHome), s.options.SimParams); homeCapped {
+		logHome = math.Log(lambdaHome)
+	}
+	if lambdaAway, awayCapped = capLambda(math.Exp(logAway), s.options.SimParams); awayCapped {
+		logAway = math.Log(lambdaAway)
+	}
+	return logHome, logAway, homeCapped, awayCapped
+}
+
+// countLogLikelihood is logLikelihood under a shaped count model
+func (s *MLESolver) countLogLikelihood(model CountModel, matches []MatchResult) float64 {
+	logLikelihood := 0.0
+	for _, match := range matches {
+		adjustment := DixonColesAdjustment(match.HomeGoals, match.AwayGoals, s.matchRho(match))
+		if adjustment <= 0 {
+			continue
+		}
+		logHome, logAway, _, _ := s.countRates(match)
+		logProb := model.LogProb(logHome, match.HomeGoals) + model.LogProb(logAway, match.AwayGoals) + math.Log(adjustment)
+		logLikelihood += s.getMatchWeight(match) * logProb
+	}
+	return logLikelihood
+}
+
+// countGradients is gradients under a shaped count model, with the model's scores as residuals
+func (s *MLESolver) countGradients(model CountModel, matches []MatchResult) map[string]float64 {
+	gradients := make(map[string]float64)
+	for _, match := range matches {
+		logHome, logAway, homeCapped, awayCapped := s.countRates(match)
+		weight := s.getMatchWeight(match)
+		if !homeCapped {
+			residual := weight * model.Score(logHome, match.HomeGoals)
+			gradients[match.HomeTeam+"_attack"] += residual
+			gradients[match.AwayTeam+"_defense"] -= residual
+		}
+		if !awayCapped {
+			residual := weight * model.Score(logAway, match.AwayGoals)
+			gradients[match.AwayTeam+"_attack"] += residual
+			gradients[match.HomeTeam+"_defense"] -= residual
+		}
+	}
+	return gradients
+}
+
+// countShapeStep is the shape change used to difference the likelihood in updateCountShape
+const countShapeStep = 1e-4
+
+// updateCountShape takes one Newton step for the count model's shape, with the likelihood's
+// first and second derivatives in the shape taken by central differences. The Dixon-Coles terms
+// do not depend on the shape, so they are left out. The step is skipped where the likelihood is
+// not concave, and the shape stays within the model's bounds
+func (s *MLESolver) updateCountShape() {
+	shaped := shapedCountModels[s.params.CountModel]
+	shape := s.params.CountShape
+	models := [3]CountModel{shaped.create(shape - countShapeStep), s.goalModel(), shaped.create(shape + countShapeStep)}
+	var logLikelihoods [3]float64
+	for _, match := range s.matches {
+		if DixonColesAdjustment(match.HomeGoals, match.AwayGoals, s.matchRho(match)) <= 0 {
+			continue
+		}
+		logHome, logAway, _, _ := s.countRates(match)
+		weight := s.getMatchWeight(match)
+		for i, model := range models {
+			logLikelihoods[i] += weight * (model.LogProb(logHome, match.HomeGoals) + model.LogProb(logAway, match.AwayGoals))
+		}
+	}
+
+	lower, middle, upper := logLikelihoods[0], logLikelihoods[1], logLikelihoods[2]
+	gradient := (upper - lower) / (2 * countShapeStep)
+	curvature := (upper - 2*middle + lower) / (countShapeStep * countShapeStep)
+	if curvature >= 0 {
+		return
+	}
+	s.params.CountShape = math.Max(shaped.min, math.Min(shaped.max, shape-gradient/curvature))
+}
+
+// printCountShape reports the fitted count model shape
+func (s *MLESolver) printCountShape() {
+	fmt.Printf("🎲 %s count shape: %.4f\n", s.params.CountModel, s.params.CountShape)
+}
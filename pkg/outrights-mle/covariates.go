@@ -0,0 +1,244 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Form covariates for SimParams.FormCovariates
+const (
+	CovariateRecentPPG      = "recent_ppg"      // Points per game over the team's last FormWindow matches
+	CovariateUnbeatenStreak = "unbeaten_streak" // Matches since the team's last defeat
+	CovariateShortRest      = "short_rest"      // Days short of a full week's rest since the team's last match
+)
+
+// fullRestDays is the rest after which a team carries no fatigue for the short_rest covariate
+const fullRestDays = 7
+
+// covariateKey identifies a match for covariate lookup without allocating
+type covariateKey struct {
+	date, homeTeam, awayTeam string
+}
+
+// validateCovariates checks SimParams.FormCovariates names known covariates, each once
+func validateCovariates(simParams *SimParams) error {
+	seen := make(map[string]bool)
+	for _, name := range simParams.FormCovariates {
+		if name != CovariateRecentPPG && name != CovariateUnbeatenStreak && name != CovariateShortRest {
+			return fmt.Errorf("unknown form covariate %q (expected %q, %q or %q)", name, CovariateRecentPPG, CovariateUnbeatenStreak, CovariateShortRest)
+		}
+		if seen[name] {
+			return fmt.Errorf("form covariate %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// formCovariates returns, per match, the home team's pre-match value of each covariate minus the
+// away team's, from the teams' earlier matches in any competition and season
+// Teams without an earlier match take the data's mean points per game, a zero streak and full rest
+func formCovariates(matches []MatchResult, names []string, window int) map[covariateKey][]float64 {
+	if window <= 0 {
+		window = defaultFormWindow
+	}
+
+	ordered := append([]MatchResult(nil), matches...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Date < ordered[j].Date
+	})
+
+	points := func(goalsFor, goalsAgainst int) int {
+		switch {
+		case goalsFor > goalsAgainst:
+			return 3
+		case goalsFor == goalsAgainst:
+			return 1
+		}
+		return 0
+	}
+	totalPoints := 0
+	for _, match := range ordered {
+		totalPoints += points(match.HomeGoals, match.AwayGoals) + points(match.AwayGoals, match.HomeGoals)
+	}
+	meanPPG := 0.0
+	if len(ordered) > 0 {
+		meanPPG = float64(totalPoints) / float64(2*len(ordered))
+	}
+
+	recent := make(map[string][]int) // Team -> points in its last window matches, oldest first
+	streaks := make(map[string]int)
+	lastDates := make(map[string]string)
+	value := func(name, team, date string) float64 {
+		switch name {
+		case CovariateUnbeatenStreak:
+			return float64(streaks[team])
+		case CovariateShortRest:
+			return restShortfall(lastDates[team], date)
+		}
+		if len(recent[team]) == 0 {
+			return meanPPG
+		}
+		sum := 0
+		for _, p := range recent[team] {
+			sum += p
+		}
+		return float64(sum) / float64(len(recent[team]))
+	}
+	record := func(team, date string, goalsFor, goalsAgainst int) {
+		lastDates[team] = date
+		p := points(goalsFor, goalsAgainst)
+		recent[team] = append(recent[team], p)
+		if len(recent[team]) > window {
+			recent[team] = recent[team][1:]
+		}
+		if p == 0 {
+			streaks[team] = 0
+		} else {
+			streaks[team]++
+		}
+	}
+
+	covariates := make(map[covariateKey][]float64, len(ordered))
+	for _, match := range ordered {
+		differences := make([]float64, len(names))
+		for i, name := range names {
+			differences[i] = value(name, match.HomeTeam, match.Date) - value(name, match.AwayTeam, match.Date)
+		}
+		covariates[covariateKey{match.Date, match.HomeTeam, match.AwayTeam}] = differences
+		record(match.HomeTeam, match.Date, match.HomeGoals, match.AwayGoals)
+		record(match.AwayTeam, match.Date, match.AwayGoals, match.HomeGoals)
+	}
+	return covariates
+}
+
+// restShortfall returns the days short of fullRestDays between a team's last match and date (YYYY-MM-DD)
+// Zero without a last match, on unparseable dates, or after a full week's rest
+func restShortfall(lastDate, date string) float64 {
+	if lastDate == "" || date == "" {
+		return 0
+	}
+	last, err := time.Parse("2006-01-02", lastDate)
+	if err != nil {
+		return 0
+	}
+	current, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0
+	}
+	days := current.Sub(last).Hours() / 24
+	if days < 0 || days >= fullRestDays {
+		return 0
+	}
+	return fullRestDays - days
+}
+
+// lastMatchDates returns each team's latest match date, from which fixture rest is counted
+func lastMatchDates(matches []MatchResult) map[string]string {
+	dates := make(map[string]string)
+	for _, match := range matches {
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if match.Date > dates[team] {
+				dates[team] = match.Date
+			}
+		}
+	}
+	return dates
+}
+
+// restTerms returns the short_rest shift to the home log scoring rate of each dated fixture, with
+// rest counted from the teams' last fitted matches (MLEParams.LastMatchDates) and then from their
+// earlier fixtures in the list. Nil when params carry no short_rest coefficient
+func restTerms(params MLEParams, fixtures []ScheduledFixture) map[covariateKey]float64 {
+	coefficient, exists := params.CovariateCoefficients[CovariateShortRest]
+	if !exists || len(params.LastMatchDates) == 0 {
+		return nil
+	}
+
+	ordered := make([]ScheduledFixture, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		if fixture.Date != "" {
+			ordered = append(ordered, fixture)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Date < ordered[j].Date
+	})
+
+	lastDates := copyMap(params.LastMatchDates)
+	terms := make(map[covariateKey]float64, len(ordered))
+	for _, fixture := range ordered {
+		shortfall := restShortfall(lastDates[fixture.HomeTeam], fixture.Date) - restShortfall(lastDates[fixture.AwayTeam], fixture.Date)
+		terms[covariateKey{fixture.Date, fixture.HomeTeam, fixture.AwayTeam}] = coefficient * shortfall
+		lastDates[fixture.HomeTeam] = fixture.Date
+		lastDates[fixture.AwayTeam] = fixture.Date
+	}
+	return terms
+}
+
+// covariateTerm returns the covariates' shift to the home team's log scoring rate; the away rate
+// moves by the negative. Zero without covariates or for a match they were not computed for
+func (s *MLESolver) covariateTerm(match MatchResult) float64 {
+	if len(s.covariates) == 0 {
+		return 0
+	}
+	differences, exists := s.covariates[covariateKey{match.Date, match.HomeTeam, match.AwayTeam}]
+	if !exists {
+		return 0
+	}
+	term := 0.0
+	for i, name := range s.options.SimParams.FormCovariates {
+		term += s.params.CovariateCoefficients[name] * differences[i]
+	}
+	return term
+}
+
+// updateCovariateCoefficients takes one Newton step for each covariate coefficient in turn, and
+// records its standard error from the curvature
+func (s *MLESolver) updateCovariateCoefficients() {
+	for i, name := range s.options.SimParams.FormCovariates {
+		gradient, curvature := 0.0, 0.0
+		for _, match := range s.matches {
+			differences, exists := s.covariates[covariateKey{match.Date, match.HomeTeam, match.AwayTeam}]
+			if !exists || differences[i] == 0 {
+				continue
+			}
+			term := s.covariateTerm(match)
+			lambdaHome, homeCapped := capLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.HomeTeam]-s.params.DefenseRatings[match.AwayTeam]+s.matchHomeAdvantage(match)+term), s.options.SimParams)
+			lambdaAway, awayCapped := capLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.AwayTeam]-s.params.DefenseRatings[match.HomeTeam]-term), s.options.SimParams)
+			weight := s.getMatchWeight(match)
+
+			// A rate held at MaxLambda does not move with the coefficient, as in gradients
+			if !homeCapped {
+				gradient += weight * differences[i] * (float64(match.HomeGoals) - lambdaHome)
+				curvature += weight * differences[i] * differences[i] * lambdaHome
+			}
+			if !awayCapped {
+				gradient -= weight * differences[i] * (float64(match.AwayGoals) - lambdaAway)
+				curvature += weight * differences[i] * differences[i] * lambdaAway
+			}
+		}
+		if curvature <= 0 {
+			continue
+		}
+		s.params.CovariateCoefficients[name] += gradient / curvature
+		s.params.CovariateStandardErrors[name] = 1 / math.Sqrt(curvature)
+	}
+}
+
+// printCovariates reports the fitted coefficients with their z-scores
+func (s *MLESolver) printCovariates() {
+	var parts []string
+	for _, name := range s.options.SimParams.FormCovariates {
+		coefficient, standardError := s.params.CovariateCoefficients[name], s.params.CovariateStandardErrors[name]
+		if standardError > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%.4f (z=%.2f)", name, coefficient, coefficient/standardError))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%.4f", name, coefficient))
+		}
+	}
+	fmt.Printf("📈 Form covariates: %s\n", strings.Join(parts, ", "))
+}
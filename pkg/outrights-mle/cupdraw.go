@@ -0,0 +1,291 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+)
+
+// CupRound configures one round of a cup draw
+type CupRound struct {
+	Name      string `json:"name,omitempty"`       // e.g. "Third Round" (default: named from the teams left, e.g. "Quarter-final")
+	TwoLegged bool   `json:"two_legged,omitempty"` // Home and away legs, decided on aggregate
+	Neutral   bool   `json:"neutral,omitempty"`    // Single match at a neutral venue, e.g. semi-finals and final
+}
+
+// CupDraw describes a knockout cup with an open draw before each round
+type CupDraw struct {
+	Name      string         `json:"name"`                 // Mixed into the simulation seed
+	Teams     []string       `json:"teams"`                // Teams still in the cup
+	Pots      map[string]int `json:"pots,omitempty"`       // team -> pot; teams in the same pot avoid each other where the draw allows (unlisted teams can meet anyone)
+	PotRounds int            `json:"pot_rounds,omitempty"` // Rounds the pots apply to, from the next round (0 = every round)
+	Rounds    []CupRound     `json:"rounds,omitempty"`     // Settings for the next rounds in order; rounds not listed are single matches at the first-drawn team's ground
+}
+
+// CupResult holds the probabilities from a cup draw simulation
+type CupResult struct {
+	Reach  map[string]map[string]float64 `json:"reach"`  // round -> team -> probability of being in the draw for it
+	Winner map[string]float64            `json:"winner"` // team -> probability of winning the cup
+	Rounds []string                      `json:"rounds"` // Rounds in order, keys of Reach
+	Paths  int                           `json:"paths"`
+}
+
+// SimulateCupDraw simulates the remaining draws and ties of a knockout cup with fitted ratings
+// Each round is drawn at random, keeping pots apart while it can, and each tie is decided from
+// the params' count model score matrices: a level tie goes to extra time (a third of the expected goals) and
+// then to penalties, won by either side with equal probability. With an odd number of teams
+// left, one team drawn at random gets a bye. Uses DefaultSimParams if simParams is nil
+// (SimulationPaths, Seed and GoalSimulationBound apply)
+func SimulateCupDraw(params MLEParams, simParams *SimParams, cup CupDraw) (*CupResult, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	if simParams.SimulationPaths < 1 {
+		return nil, fmt.Errorf("simulation paths must be at least 1, got %d", simParams.SimulationPaths)
+	}
+	params = params.forLeague("") // Ties are between leagues, so league intercepts go into the ratings
+	if len(cup.Teams) < 2 {
+		return nil, fmt.Errorf("cup %s needs at least 2 teams, got %d", cup.Name, len(cup.Teams))
+	}
+	index := make(map[string]int, len(cup.Teams))
+	for i, team := range cup.Teams {
+		if _, exists := index[team]; exists {
+			return nil, fmt.Errorf("cup %s lists %s twice", cup.Name, team)
+		}
+		if _, exists := params.AttackRatings[team]; !exists {
+			return nil, &UnknownTeamError{Team: team, Context: "cup " + cup.Name}
+		}
+		index[team] = i
+	}
+	for team, pot := range cup.Pots {
+		if _, exists := index[team]; !exists {
+			return nil, fmt.Errorf("cup %s pots name %s, which is not in the cup", cup.Name, team)
+		}
+		if pot < 1 {
+			return nil, fmt.Errorf("cup %s puts %s in pot %d; pots are numbered from 1", cup.Name, team, pot)
+		}
+	}
+	if cup.PotRounds < 0 {
+		return nil, fmt.Errorf("cup %s pot rounds must not be negative, got %d", cup.Name, cup.PotRounds)
+	}
+
+	var rounds []string
+	for teams := len(cup.Teams); teams > 1; teams = (teams + 1) / 2 {
+		round := len(rounds)
+		name := ""
+		if round < len(cup.Rounds) {
+			name = cup.Rounds[round].Name
+		}
+		if name == "" && teams&(teams-1) == 0 {
+			name = knockoutRoundName(teams)
+		} else if name == "" {
+			name = fmt.Sprintf("Round %d", round+1)
+		}
+		rounds = append(rounds, name)
+	}
+	if len(cup.Rounds) > len(rounds) {
+		return nil, fmt.Errorf("cup %s configures %d rounds, but %d teams play only %d", cup.Name, len(cup.Rounds), len(cup.Teams), len(rounds))
+	}
+
+	ties := newCupTies(params, simParams, cup.Teams)
+	rng := newSimulationRand(simParams.Seed, cup.Name)
+	pots := make([]int, len(cup.Teams))
+	for team, pot := range cup.Pots {
+		pots[index[team]] = pot
+	}
+
+	reachCounts := make([][]int, len(rounds))
+	for i := range reachCounts {
+		reachCounts[i] = make([]int, len(cup.Teams))
+	}
+	winnerCounts := make([]int, len(cup.Teams))
+	for path := 0; path < simParams.SimulationPaths; path++ {
+		remaining := make([]int, len(cup.Teams))
+		for i := range remaining {
+			remaining[i] = i
+		}
+		for round := range rounds {
+			for _, team := range remaining {
+				reachCounts[round][team]++
+			}
+			var settings CupRound
+			if round < len(cup.Rounds) {
+				settings = cup.Rounds[round]
+			}
+
+			rng.Shuffle(len(remaining), func(i, j int) { remaining[i], remaining[j] = remaining[j], remaining[i] })
+			var next []int
+			if len(remaining)%2 == 1 {
+				next = append(next, remaining[len(remaining)-1])
+				remaining = remaining[:len(remaining)-1]
+			}
+			usePots := len(cup.Pots) > 0 && (cup.PotRounds == 0 || round < cup.PotRounds)
+			for len(remaining) > 0 {
+				home := remaining[0]
+				opponent := 1
+				if usePots && pots[home] != 0 {
+					for j := 1; j < len(remaining); j++ {
+						if pots[remaining[j]] != pots[home] {
+							opponent = j
+							break
+						}
+					}
+				}
+				away := remaining[opponent]
+				remaining[opponent] = remaining[len(remaining)-1]
+				remaining = remaining[1 : len(remaining)-1]
+
+				if rng.Float64() < ties.homeAdvances(home, away, settings) {
+					next = append(next, home)
+				} else {
+					next = append(next, away)
+				}
+			}
+			remaining = next
+		}
+		winnerCounts[remaining[0]]++
+	}
+
+	paths := float64(simParams.SimulationPaths)
+	result := &CupResult{
+		Reach:  make(map[string]map[string]float64, len(rounds)),
+		Winner: make(map[string]float64, len(cup.Teams)),
+		Rounds: rounds,
+		Paths:  simParams.SimulationPaths,
+	}
+	for round, name := range rounds {
+		result.Reach[name] = make(map[string]float64, len(cup.Teams))
+		for i, team := range cup.Teams {
+			result.Reach[name][team] = float64(reachCounts[round][i]) / paths
+		}
+	}
+	for i, team := range cup.Teams {
+		result.Winner[team] = float64(winnerCounts[i]) / paths
+	}
+	return result, nil
+}
+
+// cupTieKind distinguishes how a tie is played, for caching its probabilities
+type cupTieKind int
+
+const (
+	cupTieHome cupTieKind = iota
+	cupTieNeutral
+	cupTieTwoLegged
+)
+
+// cupTies caches the probability that the first-drawn team wins each tie
+type cupTies struct {
+	params    MLEParams
+	model     CountModel
+	simParams *SimParams
+	bound     int
+	teams     []string
+	advances  map[[3]int]float64
+}
+
+func newCupTies(params MLEParams, simParams *SimParams, teams []string) *cupTies {
+	return &cupTies{
+		params:    params,
+		model:     params.countModel(),
+		simParams: simParams,
+		bound:     simParams.GoalSimulationBound,
+		teams:     teams,
+		advances:  make(map[[3]int]float64),
+	}
+}
+
+// homeAdvances returns the probability that home, the first team drawn, goes through
+func (t *cupTies) homeAdvances(home, away int, settings CupRound) float64 {
+	kind := cupTieHome
+	if settings.TwoLegged {
+		kind = cupTieTwoLegged
+	} else if settings.Neutral {
+		kind = cupTieNeutral
+	}
+	key := [3]int{home, away, int(kind)}
+	if probability, exists := t.advances[key]; exists {
+		return probability
+	}
+
+	homeTeam, awayTeam := t.teams[home], t.teams[away]
+	var probability float64
+	switch kind {
+	case cupTieTwoLegged:
+		probability = t.twoLeggedAdvance(homeTeam, awayTeam)
+	case cupTieNeutral:
+		probability = t.singleMatchAdvance(homeTeam, awayTeam, 0)
+	default:
+		probability = t.singleMatchAdvance(homeTeam, awayTeam, t.params.HomeAdvantage)
+	}
+	t.advances[key] = probability
+	return probability
+}
+
+// expectedGoals returns the scoring rates of home and away with the given home advantage
+func (t *cupTies) expectedGoals(homeTeam, awayTeam string, homeAdvantage float64) (float64, float64) {
+	return cappedLambda(math.Exp(t.params.Intercept+t.params.AttackRatings[homeTeam]-t.params.DefenseRatings[awayTeam]+homeAdvantage), t.simParams),
+		cappedLambda(math.Exp(t.params.Intercept+t.params.AttackRatings[awayTeam]-t.params.DefenseRatings[homeTeam]), t.simParams)
+}
+
+// resolveLevel returns the probability that the home side wins a level tie in extra time or on
+// penalties, with extra time played at the given rates
+func (t *cupTies) resolveLevel(lambdaHome, lambdaAway float64) float64 {
+	extraTime := normalizedOdds(NewCountScoreMatrix(t.model, lambdaHome/3, lambdaAway/3, t.params.Rho, t.bound))
+	return extraTime[0] + extraTime[1]/2
+}
+
+// singleMatchAdvance prices a one-off tie
+func (t *cupTies) singleMatchAdvance(homeTeam, awayTeam string, homeAdvantage float64) float64 {
+	lambdaHome, lambdaAway := t.expectedGoals(homeTeam, awayTeam, homeAdvantage)
+	odds := normalizedOdds(NewCountScoreMatrix(t.model, lambdaHome, lambdaAway, t.params.Rho, t.bound))
+	return odds[0] + odds[1]*t.resolveLevel(lambdaHome, lambdaAway)
+}
+
+// twoLeggedAdvance prices a tie with the first leg at home's ground and the second, plus any
+// extra time, at away's; the aggregate goal difference decides it
+func (t *cupTies) twoLeggedAdvance(homeTeam, awayTeam string) float64 {
+	firstHome, firstAway := t.expectedGoals(homeTeam, awayTeam, t.params.HomeAdvantage)
+	secondHome, secondAway := t.expectedGoals(awayTeam, homeTeam, t.params.HomeAdvantage)
+	first := NewCountScoreMatrix(t.model, firstHome, firstAway, t.params.Rho, t.bound)
+	second := NewCountScoreMatrix(t.model, secondHome, secondAway, t.params.Rho, t.bound)
+
+	// Goal difference of the first-drawn team in each leg, offset by bound
+	legDifference := func(m *ScoreMatrix, drawnIsHome bool) []float64 {
+		differences := make([]float64, 2*t.bound+1)
+		total := m.TotalProbability()
+		for homeGoals := 0; homeGoals <= m.HomeGoals; homeGoals++ {
+			for awayGoals := 0; awayGoals <= m.AwayGoals; awayGoals++ {
+				difference := homeGoals - awayGoals
+				if !drawnIsHome {
+					difference = -difference
+				}
+				differences[difference+t.bound] += m.Matrix[homeGoals][awayGoals] / total
+			}
+		}
+		return differences
+	}
+	firstLeg := legDifference(first, true)
+	secondLeg := legDifference(second, false)
+
+	var ahead, level float64
+	for i, p := range firstLeg {
+		for j, q := range secondLeg {
+			switch aggregate := i + j - 2*t.bound; {
+			case aggregate > 0:
+				ahead += p * q
+			case aggregate == 0:
+				level += p * q
+			}
+		}
+	}
+	// Extra time is at the second-leg venue, so the first-drawn team is the away side
+	return ahead + level*(1-t.resolveLevel(secondHome, secondAway))
+}
+
+// normalizedOdds returns a score matrix's 1X2 probabilities rescaled to sum to 1, so goals
+// beyond the matrix bound do not leak probability
+func normalizedOdds(m *ScoreMatrix) [3]float64 {
+	odds := m.MatchOdds()
+	total := odds[0] + odds[1] + odds[2]
+	return [3]float64{odds[0] / total, odds[1] / total, odds[2] / total}
+}
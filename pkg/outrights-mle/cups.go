@@ -0,0 +1,40 @@
+package outrightsmle
+
+import "fmt"
+
+// competitionWeight returns the likelihood weight for a match's competition (league matches weigh 1)
+func competitionWeight(simParams *SimParams, competition string) float64 {
+	if competition == "" {
+		return 1.0
+	}
+	if weight, exists := simParams.CompetitionWeights[competition]; exists {
+		return weight
+	}
+	return simParams.CupMatchWeight
+}
+
+// splitCupMatches separates league matches from cup matches tagged with a competition
+// Cup matches only inform ratings; tables, simulations and league membership use league matches
+func splitCupMatches(matches []MatchResult) (league, cup []MatchResult) {
+	for _, match := range matches {
+		if match.Competition != "" {
+			cup = append(cup, match)
+		} else {
+			league = append(league, match)
+		}
+	}
+	return league, cup
+}
+
+// validateCompetitionWeights checks cup weights are usable likelihood weights
+func validateCompetitionWeights(simParams *SimParams) error {
+	if simParams.CupMatchWeight < 0 {
+		return fmt.Errorf("cup match weight must be non-negative, got %v", simParams.CupMatchWeight)
+	}
+	for competition, weight := range simParams.CompetitionWeights {
+		if weight < 0 {
+			return fmt.Errorf("competition weight for %s must be non-negative, got %v", competition, weight)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package outrightsmle
+
+import "math"
+
+// denseMatches packs a match list for the vectorized likelihood: team indices, goals and the
+// terms that stay fixed through a fit, with scratch vectors reused by every evaluation. Only the
+// Poisson terms and residuals go through the vector kernels; rates fills the rates in a scalar loop
+type denseMatches struct {
+	teams         []string // Index -> team, by name
+	home, away    []int
+	homeGoals     []float64
+	awayGoals     []float64
+	weights       []float64
+	logFactorials float64 // Σ weight × (log homeGoals! + log awayGoals!)
+	lowScores     []int   // Matches the Dixon-Coles adjustment applies to (both scores 0 or 1)
+	attack        []float64
+	defense       []float64
+	logLambdaHome []float64
+	logLambdaAway []float64
+	lambdaHome    []float64
+	lambdaAway    []float64
+	terms         []float64
+	residuals     []float64
+	cappedHome    []bool
+	cappedAway    []bool
+}
+
+// denseKey identifies a match list by its backing array, so the fit's matches are packed once.
+// Only the solver's own s.matches is passed in (a training solver's holds the early-stopping split),
+// and it is never rewritten after construction; a caller that reuses a backing array for other
+// matches would be served the stale packing
+type denseKey struct {
+	first *MatchResult
+	n     int
+}
+
+// denseMatchesFor returns the packed form of matches, building it on first use
+func (s *MLESolver) denseMatchesFor(matches []MatchResult) *denseMatches {
+	key := denseKey{&matches[0], len(matches)}
+	if dense, exists := s.dense[key]; exists {
+		return dense
+	}
+
+	teamSet := make(map[string]bool)
+	for _, match := range matches {
+		teamSet[match.HomeTeam], teamSet[match.AwayTeam] = true, true
+	}
+	teams := sortedKeys(teamSet)
+	index := make(map[string]int, len(teams))
+	for i, team := range teams {
+		index[team] = i
+	}
+
+	n := len(matches)
+	dense := &denseMatches{
+		teams:         teams,
+		home:          make([]int, n),
+		away:          make([]int, n),
+		homeGoals:     make([]float64, n),
+		awayGoals:     make([]float64, n),
+		weights:       make([]float64, n),
+		attack:        make([]float64, len(teams)),
+		defense:       make([]float64, len(teams)),
+		logLambdaHome: make([]float64, n),
+		logLambdaAway: make([]float64, n),
+		lambdaHome:    make([]float64, n),
+		lambdaAway:    make([]float64, n),
+		terms:         make([]float64, n),
+		residuals:     make([]float64, n),
+		cappedHome:    make([]bool, n),
+		cappedAway:    make([]bool, n),
+	}
+	for i, match := range matches {
+		dense.home[i], dense.away[i] = index[match.HomeTeam], index[match.AwayTeam]
+		dense.homeGoals[i], dense.awayGoals[i] = float64(match.HomeGoals), float64(match.AwayGoals)
+		dense.weights[i] = s.getMatchWeight(match)
+		dense.logFactorials += dense.weights[i] * (lgammaFactorial(match.HomeGoals) + lgammaFactorial(match.AwayGoals))
+		if match.HomeGoals <= 1 && match.AwayGoals <= 1 {
+			dense.lowScores = append(dense.lowScores, i)
+		}
+	}
+
+	if s.dense == nil {
+		s.dense = make(map[denseKey]*denseMatches)
+	}
+	s.dense[key] = dense
+	return dense
+}
+
+// lgammaFactorial returns log(n!) as log Γ(n+1), in constant time
+func lgammaFactorial(n int) float64 {
+	value, _ := math.Lgamma(float64(n) + 1)
+	return value
+}
+
+// rates gathers the current ratings and fills in each match's expected goals and their logs. A
+// rate held at MaxLambda is marked capped, with the log of the cap
+func (s *MLESolver) rates(dense *denseMatches, matches []MatchResult) {
+	for i, team := range dense.teams {
+		dense.attack[i] = s.params.AttackRatings[team]
+		dense.defense[i] = s.params.DefenseRatings[team]
+	}
+	simParams := s.options.SimParams
+	for i, match := range matches {
+		intercept := s.matchIntercept(match)
+		covariateTerm := s.covariateTerm(match)
+		home, away := dense.home[i], dense.away[i]
+		etaHome := intercept + dense.attack[home] - dense.defense[away] + s.matchHomeAdvantage(match) + covariateTerm
+		etaAway := intercept + dense.attack[away] - dense.defense[home] - covariateTerm
+
+		dense.lambdaHome[i], dense.cappedHome[i] = capLambda(math.Exp(etaHome), simParams)
+		dense.lambdaAway[i], dense.cappedAway[i] = capLambda(math.Exp(etaAway), simParams)
+		dense.logLambdaHome[i], dense.logLambdaAway[i] = etaHome, etaAway
+		if dense.cappedHome[i] {
+			dense.logLambdaHome[i] = math.Log(dense.lambdaHome[i])
+		}
+		if dense.cappedAway[i] {
+			dense.logLambdaAway[i] = math.Log(dense.lambdaAway[i])
+		}
+	}
+}
+
+// denseLogLikelihood is logLikelihood over packed matches: the weighted Poisson log probabilities
+// of both scores come from vector kernels, then the Dixon-Coles terms of low scores are added
+func (s *MLESolver) denseLogLikelihood(matches []MatchResult) float64 {
+	if len(matches) == 0 {
+		return 0
+	}
+	dense := s.denseMatchesFor(matches)
+	s.rates(dense, matches)
+
+	// goals × log λ − λ, for each side in turn
+	terms := dense.terms
+	vecMulTo(terms, dense.homeGoals, dense.logLambdaHome)
+	vecSub(terms, dense.lambdaHome)
+	logLikelihood := vecDot(dense.weights, terms)
+	vecMulTo(terms, dense.awayGoals, dense.logLambdaAway)
+	vecSub(terms, dense.lambdaAway)
+	logLikelihood += vecDot(dense.weights, terms) - dense.logFactorials
+
+	for _, i := range dense.lowScores {
+		adjustment := DixonColesAdjustment(int(dense.homeGoals[i]), int(dense.awayGoals[i]), s.matchRho(matches[i]))
+		if adjustment > 0 {
+			logLikelihood += dense.weights[i] * math.Log(adjustment)
+			continue
+		}
+		// A match with no probability is left out, as logLikelihood does
+		logLikelihood -= dense.weights[i] * (dense.homeGoals[i]*dense.logLambdaHome[i] - dense.lambdaHome[i] - lgammaFactorial(int(dense.homeGoals[i])) +
+			dense.awayGoals[i]*dense.logLambdaAway[i] - dense.lambdaAway[i] - lgammaFactorial(int(dense.awayGoals[i])))
+	}
+	return logLikelihood
+}
+
+// denseGradients is gradients over packed matches: the weighted residuals, goals − λ, come from
+// vector kernels and are scattered onto the attack of the scoring team and the defense of the other
+func (s *MLESolver) denseGradients(matches []MatchResult) map[string]float64 {
+	gradients := make(map[string]float64)
+	if len(matches) == 0 {
+		return gradients
+	}
+	dense := s.denseMatchesFor(matches)
+	s.rates(dense, matches)
+
+	attackGradients := make([]float64, len(dense.teams))
+	defenseGradients := make([]float64, len(dense.teams))
+	hasAttack := make([]bool, len(dense.teams))
+	hasDefense := make([]bool, len(dense.teams))
+	scatter := func(goals, lambdas []float64, capped []bool, scorers, conceders []int) {
+		residuals := dense.residuals
+		vecSubTo(residuals, goals, lambdas)
+		vecMul(residuals, dense.weights)
+		for i, residual := range residuals {
+			// A rate held at MaxLambda does not move with the ratings, so it contributes no gradient
+			if capped[i] {
+				continue
+			}
+			attackGradients[scorers[i]] += residual
+			defenseGradients[conceders[i]] -= residual
+			hasAttack[scorers[i]], hasDefense[conceders[i]] = true, true
+		}
+	}
+	scatter(dense.homeGoals, dense.lambdaHome, dense.cappedHome, dense.home, dense.away)
+	scatter(dense.awayGoals, dense.lambdaAway, dense.cappedAway, dense.away, dense.home)
+
+	for i, team := range dense.teams {
+		if hasAttack[i] {
+			gradients[team+"_attack"] = attackGradients[i]
+		}
+		if hasDefense[i] {
+			gradients[team+"_defense"] = defenseGradients[i]
+		}
+	}
+	return gradients
+}
@@ -0,0 +1,94 @@
+package outrightsmle
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+// fittedSolver returns a solver part-way through a fit on the bundled history, so the ratings,
+// intercept and weights the likelihood reads are all non-trivial
+func fittedSolver(tb testing.TB) *MLESolver {
+	tb.Helper()
+	data, err := os.ReadFile("../../fixtures/events.json")
+	if err != nil {
+		tb.Fatalf("reading events: %v", err)
+	}
+	var events []MatchResult
+	if err := json.Unmarshal(data, &events); err != nil {
+		tb.Fatalf("decoding events: %v", err)
+	}
+
+	simParams := DefaultSimParams()
+	simParams.MaxIterations = 20
+	solver := NewMLESolver(events, MLEOptions{SimParams: simParams}, nil)
+	if _, err := solver.Optimize(); err != nil {
+		tb.Fatalf("fitting: %v", err)
+	}
+	return solver
+}
+
+// The two paths sum in a different order, so they agree to rounding rather than bit for bit
+func TestDenseMatchesScalar(t *testing.T) {
+	solver := fittedSolver(t)
+
+	solver.options.VectorizedFit = false
+	logLikelihood := solver.logLikelihood(solver.matches)
+	gradients := solver.gradients(solver.matches)
+
+	denseLogLikelihood := solver.denseLogLikelihood(solver.matches)
+	if diff := math.Abs(denseLogLikelihood - logLikelihood); diff > 1e-9*math.Abs(logLikelihood) {
+		t.Errorf("log likelihood: dense %.12f, scalar %.12f", denseLogLikelihood, logLikelihood)
+	}
+
+	denseGradients := solver.denseGradients(solver.matches)
+	if len(denseGradients) != len(gradients) {
+		t.Fatalf("dense gradients have %d entries, scalar %d", len(denseGradients), len(gradients))
+	}
+	for key, gradient := range gradients {
+		dense, exists := denseGradients[key]
+		if !exists {
+			t.Errorf("dense gradients have no %s", key)
+			continue
+		}
+		if diff := math.Abs(dense - gradient); diff > 1e-9*math.Max(1, math.Abs(gradient)) {
+			t.Errorf("%s: dense %.12f, scalar %.12f", key, dense, gradient)
+		}
+	}
+}
+
+// Run with and without -tags gonum to compare the kernel builds
+func BenchmarkLogLikelihood(b *testing.B) {
+	solver := fittedSolver(b)
+	solver.options.VectorizedFit = false
+	b.Run("scalar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			solver.logLikelihood(solver.matches)
+		}
+	})
+	b.Run("dense-"+vectorKernels, func(b *testing.B) {
+		solver.denseLogLikelihood(solver.matches) // Pack outside the timer
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			solver.denseLogLikelihood(solver.matches)
+		}
+	})
+}
+
+func BenchmarkGradients(b *testing.B) {
+	solver := fittedSolver(b)
+	solver.options.VectorizedFit = false
+	b.Run("scalar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			solver.gradients(solver.matches)
+		}
+	})
+	b.Run("dense-"+vectorKernels, func(b *testing.B) {
+		solver.denseGradients(solver.matches)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			solver.denseGradients(solver.matches)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package outrightsmle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeterministicSeed is the simulation seed MLEOptions.Deterministic uses when SimParams.Seed is unset
+const DeterministicSeed int64 = 1
+
+// deterministicDigits is the significant digits MarshalDeterministicJSON keeps in each non-integer
+// number, which absorbs last-bit differences between platforms (e.g. fused multiply-add on arm64)
+const deterministicDigits = 12
+
+// deterministicSimParams returns simParams with DeterministicSeed in place of an unset seed,
+// copying rather than changing the caller's value
+func deterministicSimParams(simParams *SimParams) *SimParams {
+	if simParams.Seed != 0 {
+		return simParams
+	}
+	seeded := *simParams
+	seeded.Seed = DeterministicSeed
+	return &seeded
+}
+
+// clearTimings zeroes the wall-clock fields, which differ between otherwise identical runs
+func (result *MultiLeagueResult) clearTimings() {
+	result.ProcessingTime = 0
+	result.Timings = TimingBreakdown{
+		Simulation: make(map[string]time.Duration),
+		Markets:    make(map[string]time.Duration),
+	}
+	for league, diagnostics := range result.Diagnostics {
+		diagnostics.SimulationTime = 0
+		result.Diagnostics[league] = diagnostics
+	}
+}
+
+// MarshalDeterministicJSON encodes v as indented JSON with every non-integer number written to a
+// fixed number of significant digits. With a result from a Deterministic run, two runs on identical
+// inputs give byte-identical output, for golden-file checks and diff-based monitoring
+func MarshalDeterministicJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	rounded, err := roundJSONNumbers(data, deterministicDigits)
+	if err != nil {
+		return nil, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, rounded, "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+// roundJSONNumbers rewrites compact JSON with non-integer numbers to digits significant digits,
+// keeping object keys in their original order
+func roundJSONNumbers(data []byte, digits int) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	// One entry per open object or array: whether it is an object, and the tokens written in it
+	type container struct {
+		object bool
+		count  int
+	}
+	var stack []container
+	var out bytes.Buffer
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			out.WriteRune(rune(delim))
+			continue
+		}
+
+		// Separators: a comma before each array element or object key after the first, and a
+		// colon between an object key and its value
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			switch {
+			case top.object && top.count%2 == 1:
+				out.WriteByte(':')
+			case top.count > 0:
+				out.WriteByte(',')
+			}
+			top.count++
+		}
+
+		switch value := token.(type) {
+		case json.Delim:
+			out.WriteRune(rune(value))
+			stack = append(stack, container{object: value == '{'})
+		case json.Number:
+			out.WriteString(roundJSONNumber(value, digits))
+		case string:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		case bool:
+			out.WriteString(strconv.FormatBool(value))
+		case nil:
+			out.WriteString("null")
+		default:
+			return nil, fmt.Errorf("unexpected JSON token %v", token)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// roundJSONNumber writes a JSON number to digits significant digits; integers are left as they are
+func roundJSONNumber(number json.Number, digits int) string {
+	text := number.String()
+	if !strings.ContainsAny(text, ".eE") {
+		return text
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return text
+	}
+	return strconv.FormatFloat(value, 'g', digits, 64)
+}
@@ -0,0 +1,172 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Rating model identifiers for SimParams.RatingModel
+const (
+	RatingModelStatic  = "static"  // One rating per team fitted by time-decayed gradient ascent
+	RatingModelDynamic = "dynamic" // Random-walk ratings estimated by an approximate Kalman filter
+)
+
+// dynamicRating is a team's filtered rating state
+type dynamicRating struct {
+	attack, defense       float64
+	attackVar, defenseVar float64
+	lastDate              time.Time
+	lastSeason            string
+	league                string
+}
+
+// optimizeDynamic estimates random-walk ratings by filtering matches in date order
+// Each goal count is a Poisson observation of log λ = attack - defense (+ home advantage), updated
+// with a one-step Laplace approximation; rating variance grows with the weeks between matches and
+// across season breaks, so recent form moves ratings instead of a season-level time decay
+func (s *MLESolver) optimizeDynamic() (*MLEParams, error) {
+	simParams := s.options.SimParams
+	startTime := time.Now()
+
+	initialVariance := simParams.DynamicInitialVariance
+	if initialVariance <= 0 {
+		initialVariance = DefaultSimParams().DynamicInitialVariance
+	}
+
+	s.params = &MLEParams{
+		HomeAdvantage:  simParams.HomeAdvantage,
+		Rho:            simParams.Rho,
+		AttackRatings:  make(map[string]float64),
+		DefenseRatings: make(map[string]float64),
+		DroppedTeams:   s.droppedTeams,
+	}
+	s.params.Intercept = s.initialIntercept()
+	if len(simParams.SeasonHomeAdvantage) > 0 {
+		s.params.SeasonHomeAdvantage = copyMap(simParams.SeasonHomeAdvantage) // Supplied schedule only; not fitted
+	}
+	if len(simParams.SeasonRho) > 0 {
+		s.params.SeasonRho = copyMap(simParams.SeasonRho) // Supplied schedule only; not fitted
+	}
+
+	matches := append([]MatchResult(nil), s.matches...)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Date < matches[j].Date
+	})
+
+	if s.options.Debug {
+		fmt.Printf("🔧 Starting dynamic rating filter for %d teams, %d matches...\n", len(s.teamNames), len(matches))
+	}
+
+	states := make(map[string]*dynamicRating)
+	state := func(team string, date time.Time, season, league string) *dynamicRating {
+		rating, exists := states[team]
+		if !exists {
+			rating = &dynamicRating{attackVar: initialVariance, defenseVar: initialVariance}
+			if s.initialParams != nil {
+				rating.attack = s.initialParams.AttackRatings[team]
+				rating.defense = s.initialParams.DefenseRatings[team]
+			} else if _, isEntry := s.entryTeams[team]; isEntry && simParams.EntryPriorQuantile > 0 && league != "" {
+				// Start from the division's current lower ratings rather than average
+				var attacks, defenses []float64
+				for _, other := range states {
+					if other.league == league {
+						attacks = append(attacks, other.attack)
+						defenses = append(defenses, other.defense)
+					}
+				}
+				if len(attacks) > 0 {
+					rating.attack = quantileOf(attacks, simParams.EntryPriorQuantile)
+					rating.defense = quantileOf(defenses, simParams.EntryPriorQuantile)
+				}
+			}
+			states[team] = rating
+		} else {
+			// Random-walk drift since the team's previous match
+			weeks := date.Sub(rating.lastDate).Hours() / (24 * 7)
+			drift := math.Max(weeks, 0) * simParams.DynamicWeeklyVariance
+			if season != rating.lastSeason {
+				seasonVariance := simParams.DynamicSeasonVariance
+				if s.leagueChangeTeams[team] && season == s.latestSeason {
+					seasonVariance *= simParams.LeagueChangeLearningRate
+				}
+				drift += seasonVariance
+			}
+			// A new manager since the previous match resets some certainty, like a season break
+			for _, changeDate := range s.managerChanges[team] {
+				if changed, err := time.Parse("2006-01-02", changeDate); err == nil && changed.After(rating.lastDate) && !changed.After(date) {
+					drift += simParams.DynamicSeasonVariance * simParams.ManagerChangeLearningRate
+				}
+			}
+			drift += transferWindowVariance(rating.lastDate, date, simParams)
+			rating.attackVar += drift
+			rating.defenseVar += drift
+		}
+		rating.lastDate = date
+		rating.lastSeason = season
+		if league != "" {
+			rating.league = league
+		}
+		return rating
+	}
+
+	for _, match := range matches {
+		date, err := time.Parse("2006-01-02", match.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match date %q: %w", match.Date, err)
+		}
+		// Cup matches update ratings but leave each team's league unchanged
+		league := match.League
+		if match.Competition != "" {
+			league = ""
+		}
+		home := state(match.HomeTeam, date, match.Season, league)
+		away := state(match.AwayTeam, date, match.Season, league)
+		weight := competitionWeight(simParams, match.Competition)
+
+		// Both observations use the pre-match state
+		lambdaHome := cappedLambda(math.Exp(s.params.Intercept+home.attack-away.defense+s.matchHomeAdvantage(match)), simParams)
+		lambdaAway := cappedLambda(math.Exp(s.params.Intercept+away.attack-home.defense), simParams)
+		updateDynamicPair(&home.attack, &home.attackVar, &away.defense, &away.defenseVar, float64(match.HomeGoals), lambdaHome, weight)
+		updateDynamicPair(&away.attack, &away.attackVar, &home.defense, &home.defenseVar, float64(match.AwayGoals), lambdaAway, weight)
+		for _, rating := range []*float64{&home.attack, &home.defense, &away.attack, &away.defense} {
+			*rating = boundRating(*rating, simParams)
+		}
+	}
+
+	for team, rating := range states {
+		s.params.AttackRatings[team] = rating.attack
+		s.params.DefenseRatings[team] = rating.defense
+	}
+	s.normalizeRatings()
+	s.boundRatings()
+
+	s.params.LogLikelihood = s.CalculateLogLikelihood()
+	s.params.Iterations = 1
+	s.params.Converged = true
+
+	if s.options.Debug {
+		fmt.Printf("✅ Dynamic filter complete (log-likelihood: %.4f)\n", s.params.LogLikelihood)
+	}
+	s.options.observeOptimization(s.params.Iterations, true, time.Since(startTime))
+	s.finishHomeAdvantage()
+	s.finishRho()
+
+	return s.params, nil
+}
+
+// updateDynamicPair applies a Poisson goal observation to an attack rating and the opposing defense rating
+// weight scales the observation's information (1 for league matches, the competition weight for cups)
+func updateDynamicPair(attack, attackVar, defense, defenseVar *float64, goals, lambda, weight float64) {
+	// log λ = attack - defense, so the innovation moves the two ratings in opposite directions
+	information := weight * lambda
+	gain := 1 / (1 + information*(*attackVar+*defenseVar))
+	residual := weight * (goals - lambda)
+
+	*attack += *attackVar * residual * gain
+	*defense -= *defenseVar * residual * gain
+
+	*attackVar -= *attackVar * *attackVar * information * gain
+	*defenseVar -= *defenseVar * *defenseVar * information * gain
+}
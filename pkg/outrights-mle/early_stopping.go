@@ -0,0 +1,133 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// optimizeWithEarlyStopping holds out the most recent SimParams.ValidationGameweeks gameweeks, fits
+// the rest until validation loss stops improving for EarlyStoppingPatience iterations, then refits
+// all matches for the best iteration count so the most recent results still inform the ratings
+func (s *MLESolver) optimizeWithEarlyStopping() (*MLEParams, error) {
+	simParams := s.options.SimParams
+	patience := simParams.EarlyStoppingPatience
+	if patience <= 0 {
+		patience = DefaultSimParams().EarlyStoppingPatience
+	}
+
+	train, validation := splitRecentGameweeks(s.matches, simParams.ValidationGameweeks)
+	if len(train) == 0 || len(validation) == 0 {
+		if s.options.Debug {
+			fmt.Printf("⚠️  Validation split of %d gameweeks left no training or validation matches, fitting without early stopping\n", simParams.ValidationGameweeks)
+		}
+		return s.optimizeFixedIterations(simParams.MaxIterations, math.NaN())
+	}
+
+	// The training fit is internal, so it neither reports metrics nor splits again
+	trainOptions := s.options
+	trainOptions.Metrics = nil
+	trainOptions.Debug = false
+	trainSolver := NewMLESolver(train, trainOptions, s.leagueChangeTeams)
+	trainSolver.initialParams = s.initialParams
+	trainSolver.covariates = s.covariates // Validation matches keep the form built up in training
+	trainSolver.maxIterations = simParams.MaxIterations
+
+	bestLoss := math.Inf(1)
+	bestIterations := 1
+	sinceBest := 0
+	trainSolver.onIteration = func(iter int) bool {
+		loss := trainSolver.validationLoss(validation)
+		if loss < bestLoss {
+			bestLoss = loss
+			bestIterations = iter + 1
+			sinceBest = 0
+		} else {
+			sinceBest++
+		}
+		return sinceBest >= patience
+	}
+	if _, err := trainSolver.Optimize(); err != nil {
+		return nil, fmt.Errorf("early stopping fit failed: %w", err)
+	}
+
+	if s.options.Debug {
+		fmt.Printf("🛑 Early stopping: best validation loss %.5f at iteration %d (%d held-out matches)\n", bestLoss, bestIterations, len(validation))
+	}
+	return s.optimizeFixedIterations(bestIterations, bestLoss)
+}
+
+// optimizeFixedIterations runs the gradient fit on all matches for a set number of iterations
+func (s *MLESolver) optimizeFixedIterations(iterations int, validationLoss float64) (*MLEParams, error) {
+	s.maxIterations = iterations
+	defer func() { s.maxIterations = 0 }()
+
+	params, err := s.Optimize()
+	if err != nil {
+		return nil, err
+	}
+	if !math.IsNaN(validationLoss) {
+		params.ValidationLoss = validationLoss
+	}
+	return params, nil
+}
+
+// validationLoss returns the mean negative log-likelihood per match of held-out scorelines
+func (s *MLESolver) validationLoss(matches []MatchResult) float64 {
+	loss := 0.0
+	for _, match := range matches {
+		covariateTerm := s.covariateTerm(match)
+		lambdaHome := cappedLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.HomeTeam]-s.params.DefenseRatings[match.AwayTeam]+s.matchHomeAdvantage(match)+covariateTerm), s.options.SimParams)
+		lambdaAway := cappedLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.AwayTeam]-s.params.DefenseRatings[match.HomeTeam]-covariateTerm), s.options.SimParams)
+		prob := PoissonProb(lambdaHome, match.HomeGoals) * PoissonProb(lambdaAway, match.AwayGoals) *
+			DixonColesAdjustment(match.HomeGoals, match.AwayGoals, s.matchRho(match))
+		if model := s.goalModel(); !s.params.poissonGoals() {
+			prob = math.Exp(model.LogProb(math.Log(lambdaHome), match.HomeGoals)+model.LogProb(math.Log(lambdaAway), match.AwayGoals)) *
+				DixonColesAdjustment(match.HomeGoals, match.AwayGoals, s.matchRho(match))
+		}
+		loss -= math.Log(math.Max(prob, 1e-12))
+	}
+	return loss / float64(len(matches))
+}
+
+// splitRecentGameweeks separates matches in the most recent k ISO weeks from the rest
+func splitRecentGameweeks(matches []MatchResult, k int) ([]MatchResult, []MatchResult) {
+	gameweek := func(match MatchResult) string {
+		date, err := time.Parse("2006-01-02", match.Date)
+		if err != nil {
+			return ""
+		}
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%04d-%02d", year, week)
+	}
+
+	weeks := make(map[string]bool)
+	for _, match := range matches {
+		if week := gameweek(match); week != "" {
+			weeks[week] = true
+		}
+	}
+	sortedWeeks := make([]string, 0, len(weeks))
+	for week := range weeks {
+		sortedWeeks = append(sortedWeeks, week)
+	}
+	sort.Strings(sortedWeeks)
+	if k > len(sortedWeeks) {
+		k = len(sortedWeeks)
+	}
+	heldOut := make(map[string]bool)
+	for _, week := range sortedWeeks[len(sortedWeeks)-k:] {
+		heldOut[week] = true
+	}
+
+	var train, validation []MatchResult
+	for _, match := range matches {
+		if heldOut[gameweek(match)] {
+			validation = append(validation, match)
+		} else {
+			train = append(train, match)
+		}
+	}
+	return train, validation
+}
@@ -0,0 +1,83 @@
+package outrightsmle
+
+import "sort"
+
+// EdgeEntry compares the model's mark for one team in one market against an offered price
+type EdgeEntry struct {
+	Market       string  `json:"market"`
+	Team         string  `json:"team"`
+	Probability  float64 `json:"probability"`            // Model mark value
+	FairPrice    float64 `json:"fair_price"`             // 1 / probability (0 if probability is 0)
+	OfferedPrice float64 `json:"offered_price"`          // Bookmaker decimal odds
+	Edge         float64 `json:"edge"`                   // Expected return per unit staked: probability * offered - 1
+	LadderPrice  float64 `json:"ladder_price,omitempty"` // FairPrice snapped to SimParams.PriceLadder
+
+	// Each-way markets only
+	PlaceProbability float64 `json:"place_probability,omitempty"` // Probability of finishing within the place terms
+	EachWayEdge      float64 `json:"each_way_edge,omitempty"`     // Expected return per unit on a 2-unit each-way bet
+}
+
+// EdgeReport lists priced selections for a league, sorted by edge (best first)
+type EdgeReport struct {
+	League  string      `json:"league"`
+	Entries []EdgeEntry `json:"entries"`
+}
+
+// BuildEdgeReports compares mark values against Market.Prices for every league with priced markets
+// placeValues (may be nil) supplies place probabilities for each-way markets
+func BuildEdgeReports(markets []Market, markValues, placeValues map[string]map[string]map[string]float64) map[string]EdgeReport {
+	reports := make(map[string]EdgeReport)
+
+	for _, market := range markets {
+		if len(market.Prices) == 0 {
+			continue
+		}
+
+		teamMarks := markValues[market.League][market.Name]
+		for team, offered := range market.Prices {
+			probability, exists := teamMarks[team]
+			if !exists || offered <= 0 {
+				continue
+			}
+
+			fairPrice := 0.0
+			if probability > 0 {
+				fairPrice = 1 / probability
+			}
+
+			entry := EdgeEntry{
+				Market:       market.Name,
+				Team:         team,
+				Probability:  probability,
+				FairPrice:    fairPrice,
+				OfferedPrice: offered,
+				Edge:         probability*offered - 1,
+			}
+
+			// Each-way: one unit on the win at full odds plus one unit on the place at fractional odds
+			if market.EachWay != nil {
+				placeProb := placeValues[market.League][market.Name][team]
+				placePrice := 1 + (offered-1)*market.EachWay.Fraction
+				entry.PlaceProbability = placeProb
+				entry.EachWayEdge = (probability*offered + placeProb*placePrice - 2) / 2
+			}
+
+			report := reports[market.League]
+			report.League = market.League
+			report.Entries = append(report.Entries, entry)
+			reports[market.League] = report
+		}
+	}
+
+	for _, report := range reports {
+		entries := report.Entries
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Edge != entries[j].Edge {
+				return entries[i].Edge > entries[j].Edge
+			}
+			return entries[i].Market+entries[i].Team < entries[j].Market+entries[j].Team
+		})
+	}
+
+	return reports
+}
@@ -0,0 +1,62 @@
+package outrightsmle
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for errors.Is; returned errors wrap them with the details
+var (
+	ErrInsufficientData    = errors.New("insufficient data")                         // Too few matches or teams to fit
+	ErrUnknownTeam         = errors.New("unknown team")                              // A team is not in the data or the ratings
+	ErrLeagueGroupMismatch = errors.New("league groups do not match the event data") // League groups name leagues or teams missing from the events
+	ErrNotConverged        = errors.New("MLE optimization did not converge")         // Fit hit its iteration limit (MLEOptions.RequireConvergence)
+	ErrSimulationReleased  = errors.New("simulation has been released")              // A query needs a simulation handed back by ReleaseSimulations
+)
+
+// UnknownTeamError reports a team that is not in the data or the ratings; it matches
+// ErrUnknownTeam with errors.Is and exposes the team with errors.As
+type UnknownTeamError struct {
+	Team    string // Team name as given
+	Context string // What referred to the team, e.g. `fixture "A vs B"` or "Winner market"
+	League  string // League the team was looked up in, when there is one
+}
+
+func (e *UnknownTeamError) Error() string {
+	if e.League != "" {
+		return fmt.Sprintf("%s has unknown team %s in league %s", e.Context, e.Team, e.League)
+	}
+	return fmt.Sprintf("%s has unknown team %s", e.Context, e.Team)
+}
+
+// Is matches ErrUnknownTeam
+func (e *UnknownTeamError) Is(target error) bool {
+	return target == ErrUnknownTeam
+}
+
+// NotConvergedError reports a fit that stopped at its iteration limit; it matches ErrNotConverged
+type NotConvergedError struct {
+	Iterations    int
+	LogLikelihood float64
+}
+
+func (e *NotConvergedError) Error() string {
+	return fmt.Sprintf("MLE optimization did not converge in %d iterations (log likelihood %.4f)", e.Iterations, e.LogLikelihood)
+}
+
+// Is matches ErrNotConverged
+func (e *NotConvergedError) Is(target error) bool {
+	return target == ErrNotConverged
+}
+
+// checkConvergence returns a NotConvergedError when options require convergence and the fit did
+// not converge; fits stopped early on validation loss are not held to it
+func checkConvergence(params *MLEParams, options MLEOptions) error {
+	if !options.RequireConvergence || params.Converged {
+		return nil
+	}
+	if options.SimParams != nil && options.SimParams.ValidationGameweeks > 0 {
+		return nil
+	}
+	return &NotConvergedError{Iterations: params.Iterations, LogLikelihood: params.LogLikelihood}
+}
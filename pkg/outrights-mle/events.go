@@ -2,8 +2,12 @@ package outrightsmle
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
 )
 
 // TeamConfig represents a team configuration from core-data
@@ -27,31 +31,80 @@ func NewEventProcessor(events []MatchResult, debug bool) *EventProcessor {
 	}
 }
 
-// LoadLeagueGroups loads team configurations from core-data/teams files
-func (ep *EventProcessor) LoadLeagueGroups() error {
-	// Extract leagues dynamically from events data
-	leagues := ExtractLeagues(ep.events)
+// LoadLeagueGroups loads team configurations for the leagues found in the events data
+// Files are read from fsys as {league}-teams.json, e.g. os.DirFS("core-data")
+func (ep *EventProcessor) LoadLeagueGroups(fsys fs.FS) error {
+	leagueGroups, err := ReadLeagueGroups(fsys, ExtractLeagues(ep.events))
+	if err != nil {
+		return err
+	}
+	
+	ep.leagueGroups = leagueGroups
+	
+	if ep.debug && len(leagueGroups) > 0 {
+		fmt.Printf("📂 Loaded league groups: ")
+		for league, teams := range leagueGroups {
+			fmt.Printf("%s(%d teams) ", league, len(teams))
+		}
+		fmt.Printf("\n")
+	}
+	
+	return nil
+}
+
+// SetLeagueGroups sets league groups supplied by the caller (league -> team names)
+func (ep *EventProcessor) SetLeagueGroups(leagueGroups map[string][]string) {
+	ep.leagueGroups = leagueGroups
+}
+
+// teamsFileSuffix names a league's teams file, e.g. ENG1-teams.json or SCO1-teams.json
+const teamsFileSuffix = "-teams.json"
+
+// TeamsFileLeagues lists the leagues with a {league}-teams.json file at the top of fsys, sorted
+func TeamsFileLeagues(fsys fs.FS) ([]string, error) {
+	filenames, err := fs.Glob(fsys, "*"+teamsFileSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("listing teams files: %w", err)
+	}
+	
+	leagues := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		if league := strings.TrimSuffix(filename, teamsFileSuffix); league != "" {
+			leagues = append(leagues, league)
+		}
+	}
+	sort.Strings(leagues)
+	return leagues, nil
+}
+
+// ReadLeagueGroups reads {league}-teams.json files from fsys for the given leagues
+// Leagues without a teams file are skipped; nil leagues reads every teams file in fsys
+func ReadLeagueGroups(fsys fs.FS, leagues []string) (map[string][]string, error) {
+	if leagues == nil {
+		discovered, err := TeamsFileLeagues(fsys)
+		if err != nil {
+			return nil, err
+		}
+		leagues = discovered
+	}
+	
 	leagueGroups := make(map[string][]string)
 	
 	for _, league := range leagues {
-		filename := fmt.Sprintf("core-data/%s-teams.json", league)
+		filename := league + teamsFileSuffix
 		
-		// Check if file exists
-		if _, err := os.Stat(filename); os.IsNotExist(err) {
+		data, err := fs.ReadFile(fsys, filename)
+		if errors.Is(err, fs.ErrNotExist) {
 			// File doesn't exist, skip this league
 			continue
 		}
-		
-		file, err := os.Open(filename)
 		if err != nil {
-			return fmt.Errorf("opening teams file %s: %w", filename, err)
+			return nil, fmt.Errorf("reading teams file %s: %w", filename, err)
 		}
-		defer file.Close()
 		
 		var teams []TeamConfig
-		decoder := json.NewDecoder(file)
-		if err := decoder.Decode(&teams); err != nil {
-			return fmt.Errorf("decoding teams JSON from %s: %w", filename, err)
+		if err := json.Unmarshal(data, &teams); err != nil {
+			return nil, fmt.Errorf("decoding teams JSON from %s: %w", filename, err)
 		}
 		
 		// Extract team names
@@ -63,17 +116,7 @@ func (ep *EventProcessor) LoadLeagueGroups() error {
 		leagueGroups[league] = teamNames
 	}
 	
-	ep.leagueGroups = leagueGroups
-	
-	if ep.debug && len(leagueGroups) > 0 {
-		fmt.Printf("📂 Loaded league groups: ")
-		for league, teams := range leagueGroups {
-			fmt.Printf("%s(%d teams) ", league, len(teams))
-		}
-		fmt.Printf("\n")
-	}
-	
-	return nil
+	return leagueGroups, nil
 }
 
 // GetLeagueGroups returns the loaded league groups
@@ -116,7 +159,84 @@ func (ep *EventProcessor) GroupEventsByLeague() map[string][]MatchResult {
 	return eventsByLeague
 }
 
-// DetectLeagueChangeTeams finds teams that have changed leagues across seasons  
+// Events returns the processor's events
+func (ep *EventProcessor) Events() []MatchResult {
+	return ep.events
+}
+
+// FilterByDateRange returns a processor holding the events played from from to to (YYYY-MM-DD,
+// both inclusive); an empty bound leaves that end open. League groups carry over unchanged
+func (ep *EventProcessor) FilterByDateRange(from, to string) (*EventProcessor, error) {
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", date)
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return nil, fmt.Errorf("date range start %s is after its end %s", from, to)
+	}
+
+	return ep.filter(func(event MatchResult) bool {
+		return (from == "" || event.Date >= from) && (to == "" || event.Date <= to)
+	}), nil
+}
+
+// FilterBySeasons returns a processor holding the events from the given seasons, e.g. the last
+// five for a shorter training window. League groups carry over unchanged
+func (ep *EventProcessor) FilterBySeasons(seasons ...string) *EventProcessor {
+	selected := make(map[string]bool, len(seasons))
+	for _, season := range seasons {
+		selected[season] = true
+	}
+	return ep.filter(func(event MatchResult) bool {
+		return selected[event.Season]
+	})
+}
+
+// FilterByLeagues returns a processor holding the events from the given leagues, with league
+// groups restricted to the same leagues
+func (ep *EventProcessor) FilterByLeagues(leagues ...string) *EventProcessor {
+	selected := make(map[string]bool, len(leagues))
+	for _, league := range leagues {
+		selected[league] = true
+	}
+	filtered := ep.filter(func(event MatchResult) bool {
+		return selected[event.League]
+	})
+	if ep.leagueGroups != nil {
+		filtered.leagueGroups = make(map[string][]string)
+		for league, teams := range ep.leagueGroups {
+			if selected[league] {
+				filtered.leagueGroups[league] = teams
+			}
+		}
+	}
+	return filtered
+}
+
+// filter returns a processor with the events keep accepts, sharing the debug flag and league groups
+func (ep *EventProcessor) filter(keep func(MatchResult) bool) *EventProcessor {
+	var events []MatchResult
+	for _, event := range ep.events {
+		if keep(event) {
+			events = append(events, event)
+		}
+	}
+	if ep.debug {
+		fmt.Printf("🔍 Filtered %d events to %d\n", len(ep.events), len(events))
+	}
+	return &EventProcessor{
+		events:       events,
+		debug:        ep.debug,
+		leagueGroups: ep.leagueGroups,
+	}
+}
+
+// DetectLeagueChangeTeams finds teams that have changed leagues across seasons
+// It is built from DetectLeagueChanges, so the learning-rate boost and the reported changes agree
 func (ep *EventProcessor) DetectLeagueChangeTeams() map[string]bool {
 	leagueChangeTeams := make(map[string]bool)
 	
@@ -124,68 +244,84 @@ func (ep *EventProcessor) DetectLeagueChangeTeams() map[string]bool {
 		fmt.Printf("🔄 Detecting teams with league changes across 10 seasons...\n")
 	}
 	
-	// Group teams by season and league to detect changes
-	teamSeasonLeague := make(map[string]map[string]string) // team -> season -> league
+	changes := ep.DetectLeagueChanges()
+	teamChanges := make(map[string][]string) // Team -> changes for debug output, in season order
+	for _, change := range changes {
+		leagueChangeTeams[change.Team] = true
+		symbol := "📈"
+		if change.Direction == "relegated" {
+			symbol = "📉"
+		}
+		teamChanges[change.Team] = append(teamChanges[change.Team], fmt.Sprintf("%s %s→%s (%s)", symbol, change.FromLeague, change.ToLeague, change.Season))
+	}
+	
+	// Debug output for teams with changes
+	if ep.debug {
+		for _, team := range sortedKeys(leagueChangeTeams) {
+			fmt.Printf("  🔄 %s: %s\n", team, teamChanges[team][0])
+			for _, change := range teamChanges[team][1:] {
+				fmt.Printf("               %s\n", change)
+			}
+		}
+		fmt.Printf("📊 Found %d teams with historical league changes\n", len(leagueChangeTeams))
+	}
 	
+	return leagueChangeTeams
+}
+
+// DetectLeagueChanges lists every league change between consecutive seasons, ordered by season then team
+// League codes rank divisions (ENG1 above ENG2), so moving to a lower code is a promotion
+func (ep *EventProcessor) DetectLeagueChanges() []LeagueChange {
+	latestSeason := ""
+	teamSeasonLeague := make(map[string]map[string]string) // team -> season -> league
 	for _, event := range ep.events {
-		if teamSeasonLeague[event.HomeTeam] == nil {
-			teamSeasonLeague[event.HomeTeam] = make(map[string]string)
+		if event.Season > latestSeason {
+			latestSeason = event.Season
 		}
-		if teamSeasonLeague[event.AwayTeam] == nil {
-			teamSeasonLeague[event.AwayTeam] = make(map[string]string)
+		for _, team := range []string{event.HomeTeam, event.AwayTeam} {
+			if teamSeasonLeague[team] == nil {
+				teamSeasonLeague[team] = make(map[string]string)
+			}
+			teamSeasonLeague[team][event.Season] = event.League
 		}
-		
-		teamSeasonLeague[event.HomeTeam][event.Season] = event.League
-		teamSeasonLeague[event.AwayTeam][event.Season] = event.League
 	}
 	
-	// Detect league changes for each team
+	changes := []LeagueChange{}
 	for team, seasonLeagues := range teamSeasonLeague {
-		var seasons []string
+		seasons := make([]string, 0, len(seasonLeagues))
 		for season := range seasonLeagues {
 			seasons = append(seasons, season)
 		}
+		sort.Strings(seasons)
 		
-		// Sort seasons to check chronologically
-		for i := 0; i < len(seasons)-1; i++ {
-			for j := i + 1; j < len(seasons); j++ {
-				if seasons[i] > seasons[j] {
-					seasons[i], seasons[j] = seasons[j], seasons[i]
-				}
-			}
-		}
-		
-		// Check for league changes between consecutive seasons
-		var changes []string
-		for i := 0; i < len(seasons)-1; i++ {
-			currentLeague := seasonLeagues[seasons[i]]
-			nextLeague := seasonLeagues[seasons[i+1]]
-			
-			if currentLeague != nextLeague {
-				leagueChangeTeams[team] = true
-				// Track the change for debug output
-				if currentLeague < nextLeague {
-					changes = append(changes, fmt.Sprintf("📉 %s→%s", seasons[i], seasons[i+1]))
-				} else {
-					changes = append(changes, fmt.Sprintf("📈 %s→%s", seasons[i], seasons[i+1]))
-				}
+		for i := 1; i < len(seasons); i++ {
+			fromLeague := seasonLeagues[seasons[i-1]]
+			toLeague := seasonLeagues[seasons[i]]
+			if fromLeague == toLeague {
+				continue
 			}
-		}
-		
-		// Debug output for teams with changes
-		if ep.debug && len(changes) > 0 {
-			fmt.Printf("  🔄 %s: %s\n", team, fmt.Sprintf("%s", changes[0]))
-			for i := 1; i < len(changes); i++ {
-				fmt.Printf("               %s\n", changes[i])
+			direction := "relegated"
+			if toLeague < fromLeague {
+				direction = "promoted"
 			}
+			changes = append(changes, LeagueChange{
+				Team:             team,
+				FromLeague:       fromLeague,
+				ToLeague:         toLeague,
+				Season:           seasons[i],
+				Direction:        direction,
+				IntoLatestSeason: seasons[i] == latestSeason,
+			})
 		}
 	}
 	
-	if ep.debug {
-		fmt.Printf("📊 Found %d teams with historical league changes\n", len(leagueChangeTeams))
-	}
-	
-	return leagueChangeTeams
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Season != changes[j].Season {
+			return changes[i].Season < changes[j].Season
+		}
+		return changes[i].Team < changes[j].Team
+	})
+	return changes
 }
 
 // GetTeamsInSeason returns teams that played in a specific season for given events
@@ -218,11 +354,12 @@ func GetCurrentTeams(leagueGroups map[string][]string, eventsByLeague map[string
 		if leagueEvents, exists := eventsByLeague[league]; exists {
 			teamsMap := GetTeamsInSeason(leagueEvents, latestSeason)
 			
-			// Convert map to slice
+			// Convert map to slice, by name so remaining fixtures come out in a fixed order
 			var teams []string
 			for team := range teamsMap {
 				teams = append(teams, team)
 			}
+			sort.Strings(teams)
 			
 			if len(teams) > 0 {
 				currentTeams[league] = teams
@@ -233,7 +370,7 @@ func GetCurrentTeams(leagueGroups map[string][]string, eventsByLeague map[string
 	return currentTeams
 }
 
-// ExtractTeams gets unique team names from match data
+// ExtractTeams gets unique team names from match data, sorted
 func ExtractTeams(matches []MatchResult) []string {
 	teamSet := make(map[string]bool)
 	for _, match := range matches {
@@ -245,15 +382,160 @@ func ExtractTeams(matches []MatchResult) []string {
 	for team := range teamSet {
 		teams = append(teams, team)
 	}
+	sort.Strings(teams)
 
 	return teams
 }
 
+// filterAsOfDate returns the matches played on or before asOfDate (YYYY-MM-DD); an empty date keeps all
+func filterAsOfDate(matches []MatchResult, asOfDate string) ([]MatchResult, error) {
+	if asOfDate == "" {
+		return matches, nil
+	}
+	if _, err := time.Parse("2006-01-02", asOfDate); err != nil {
+		return nil, fmt.Errorf("invalid as-of date %q: expected YYYY-MM-DD", asOfDate)
+	}
+
+	var filtered []MatchResult
+	for _, match := range matches {
+		if match.Date <= asOfDate {
+			filtered = append(filtered, match)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("no matches on or before as-of date %s", asOfDate)
+	}
+	return filtered, nil
+}
+
+// DroppedTeam is a team left out of the fit for having too few matches (SimParams.MinMatchesPerTeam)
+type DroppedTeam struct {
+	Team       string `json:"team"`
+	Matches    int    `json:"matches"`     // Matches in the training window
+	LastSeason string `json:"last_season"` // Season of its latest match
+}
+
+// dropSparseTeams removes the matches of teams with fewer than minMatches matches, so one-off cup
+// opponents and misspelt names do not take a share of the zero-sum normalization. Teams with a
+// league match in the latest season are kept however few their matches, since they are simulated
+func dropSparseTeams(matches []MatchResult, minMatches int, latestSeason string) ([]MatchResult, []DroppedTeam) {
+	if minMatches <= 0 {
+		return matches, nil
+	}
+
+	counts := make(map[string]int)
+	lastSeason := make(map[string]string)
+	current := make(map[string]bool)
+	for _, match := range matches {
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			counts[team]++
+			if match.Season > lastSeason[team] {
+				lastSeason[team] = match.Season
+			}
+			if match.Season == latestSeason && match.Competition == "" {
+				current[team] = true
+			}
+		}
+	}
+
+	var dropped []DroppedTeam
+	droppedSet := make(map[string]bool)
+	for _, team := range sortedKeys(counts) {
+		if counts[team] < minMatches && !current[team] {
+			dropped = append(dropped, DroppedTeam{Team: team, Matches: counts[team], LastSeason: lastSeason[team]})
+			droppedSet[team] = true
+		}
+	}
+	if len(dropped) == 0 {
+		return matches, nil
+	}
+
+	filtered := make([]MatchResult, 0, len(matches))
+	for _, match := range matches {
+		if !droppedSet[match.HomeTeam] && !droppedSet[match.AwayTeam] {
+			filtered = append(filtered, match)
+		}
+	}
+	return filtered, dropped
+}
+
+// TeamRename maps a club's former name to its canonical name, so a rebrand or merger within the
+// data window does not split one club's history into two rating entities
+type TeamRename struct {
+	From   string `json:"from"`             // Name used in the source data
+	To     string `json:"to"`               // Canonical name the club is fitted and reported under
+	Season string `json:"season,omitempty"` // Season the change took effect (e.g. "2122"): only earlier seasons are renamed; empty renames every season
+}
+
+// ApplyTeamRenames returns a copy of matches with former team names replaced by canonical ones
+// The entry points apply MLEOptions.TeamRenames themselves; call this for other uses of the data,
+// such as TuneHyperparameters. Chains (A -> B, then B -> C) resolve to the final name; a rename that cycles back is an error
+func ApplyTeamRenames(matches []MatchResult, renames []TeamRename) ([]MatchResult, error) {
+	if len(renames) == 0 {
+		return matches, nil
+	}
+
+	byName := make(map[string][]TeamRename)
+	for _, rename := range renames {
+		if rename.From == "" || rename.To == "" {
+			return nil, fmt.Errorf("team rename %q -> %q needs both names", rename.From, rename.To)
+		}
+		if rename.From == rename.To {
+			return nil, fmt.Errorf("team rename %q maps to itself", rename.From)
+		}
+		byName[rename.From] = append(byName[rename.From], rename)
+	}
+
+	canonical := func(name, season string) (string, error) {
+		for steps := 0; steps <= len(renames); steps++ {
+			renamed := false
+			for _, rename := range byName[name] {
+				if rename.Season == "" || season < rename.Season {
+					name = rename.To
+					renamed = true
+					break
+				}
+			}
+			if !renamed {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("team renames for %q form a cycle", name)
+	}
+
+	renamed := make([]MatchResult, len(matches))
+	for i, match := range matches {
+		homeTeam, err := canonical(match.HomeTeam, match.Season)
+		if err != nil {
+			return nil, err
+		}
+		awayTeam, err := canonical(match.AwayTeam, match.Season)
+		if err != nil {
+			return nil, err
+		}
+		match.HomeTeam, match.AwayTeam = homeTeam, awayTeam
+		renamed[i] = match
+	}
+	return renamed, nil
+}
+
+// ingestMatches prepares caller-supplied matches for fitting: truncated to the as-of date, with
+// team renames applied
+func ingestMatches(matches []MatchResult, options MLEOptions) ([]MatchResult, error) {
+	matches, err := filterAsOfDate(matches, options.AsOfDate)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyTeamRenames(matches, options.TeamRenames)
+}
+
 // ExtractLeagues gets unique league codes from match data
 func ExtractLeagues(matches []MatchResult) []string {
 	leagueSet := make(map[string]bool)
 	for _, match := range matches {
-		leagueSet[match.League] = true
+		if match.Competition == "" { // Cup matches are not part of any league
+			leagueSet[match.League] = true
+		}
 	}
 
 	leagues := make([]string, 0, len(leagueSet))
@@ -0,0 +1,154 @@
+package outrightsmle
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// ExchangeRunner is one selection's best exchange prices, mapped to a canonical team
+type ExchangeRunner struct {
+	Runner    string  `json:"runner"`     // Exchange runner name
+	Team      string  `json:"team"`       // Canonical team name ("" when the runner could not be matched)
+	BackPrice float64 `json:"back_price"` // Best price available to back (0 if none)
+	LayPrice  float64 `json:"lay_price"`  // Best price available to lay (0 if none)
+}
+
+// ExchangeMarket holds the current prices of one exchange outright market
+type ExchangeMarket struct {
+	MarketID   string           `json:"market_id"`
+	MarketName string           `json:"market_name"`
+	Runners    []ExchangeRunner `json:"runners"`
+}
+
+// Prices returns the best back price of every matched runner (team -> decimal odds), the
+// form Market.Prices expects, so exchange prices feed the edge report and Kelly staking
+func (m *ExchangeMarket) Prices() map[string]float64 {
+	prices := make(map[string]float64)
+	for _, runner := range m.Runners {
+		if runner.Team != "" && runner.BackPrice > 1 {
+			prices[runner.Team] = runner.BackPrice
+		}
+	}
+	return prices
+}
+
+// Unmatched returns the runner names that could not be mapped to a team
+func (m *ExchangeMarket) Unmatched() []string {
+	var unmatched []string
+	for _, runner := range m.Runners {
+		if runner.Team == "" {
+			unmatched = append(unmatched, runner.Runner)
+		}
+	}
+	return unmatched
+}
+
+// MatchTeamName maps an external name to a canonical team: an alias first, then an exact
+// match, then a match ignoring case, punctuation and club suffixes such as "FC"
+// Returns "" when nothing matches
+func MatchTeamName(name string, teams []string, aliases map[string]string) string {
+	if alias, exists := aliases[name]; exists {
+		return alias
+	}
+	for _, team := range teams {
+		if team == name {
+			return team
+		}
+	}
+	normalized := normalizeTeamName(name)
+	for _, team := range teams {
+		if normalizeTeamName(team) == normalized {
+			return team
+		}
+	}
+	return ""
+}
+
+// normalizeTeamName lower-cases a name and drops punctuation and common club affixes
+func normalizeTeamName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, "&", " and "))
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	kept := words[:0]
+	for _, word := range words {
+		switch word {
+		case "fc", "afc", "cf":
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " ")
+}
+
+// CalibrationEntry compares one team's mark with the exchange's implied probability
+type CalibrationEntry struct {
+	Team                string  `json:"team"`
+	Mark                float64 `json:"mark"`
+	ExchangeProbability float64 `json:"exchange_probability"` // Mid-market implied probability, normalized to the marks' total
+	Difference          float64 `json:"difference"`           // Mark - ExchangeProbability
+}
+
+// CalibrationReport measures how far model marks sit from exchange prices in one market
+type CalibrationReport struct {
+	Entries           []CalibrationEntry `json:"entries"` // Sorted by absolute difference (largest first)
+	MeanAbsoluteError float64            `json:"mean_absolute_error"`
+	RootMeanSquare    float64            `json:"root_mean_square"`
+}
+
+// ExchangeCalibration compares a market's marks (team -> mark) against exchange prices
+// Implied probabilities use the back/lay midpoint (or the one side quoted) and are rescaled to
+// the marks' total, so the exchange overround does not count as disagreement
+func ExchangeCalibration(marks map[string]float64, exchange *ExchangeMarket) CalibrationReport {
+	implied := make(map[string]float64)
+	markTotal, impliedTotal := 0.0, 0.0
+	for _, runner := range exchange.Runners {
+		mark, exists := marks[runner.Team]
+		if runner.Team == "" || !exists {
+			continue
+		}
+		sum, quotes := 0.0, 0
+		for _, price := range []float64{runner.BackPrice, runner.LayPrice} {
+			if price > 1 {
+				sum += 1 / price
+				quotes++
+			}
+		}
+		if quotes == 0 {
+			continue
+		}
+		implied[runner.Team] = sum / float64(quotes)
+		impliedTotal += implied[runner.Team]
+		markTotal += mark
+	}
+
+	var report CalibrationReport
+	if impliedTotal == 0 {
+		return report
+	}
+	squares := 0.0
+	for team, probability := range implied {
+		probability *= markTotal / impliedTotal
+		difference := marks[team] - probability
+		report.Entries = append(report.Entries, CalibrationEntry{
+			Team:                team,
+			Mark:                marks[team],
+			ExchangeProbability: probability,
+			Difference:          difference,
+		})
+		report.MeanAbsoluteError += math.Abs(difference)
+		squares += difference * difference
+	}
+	report.MeanAbsoluteError /= float64(len(report.Entries))
+	report.RootMeanSquare = math.Sqrt(squares / float64(len(report.Entries)))
+
+	sort.Slice(report.Entries, func(i, j int) bool {
+		di, dj := math.Abs(report.Entries[i].Difference), math.Abs(report.Entries[j].Difference)
+		if di != dj {
+			return di > dj
+		}
+		return report.Entries[i].Team < report.Entries[j].Team
+	})
+	return report
+}
@@ -0,0 +1,170 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Position is a bet the book has taken on an outright selection
+type Position struct {
+	League string  `json:"league"`
+	Market string  `json:"market"`
+	Team   string  `json:"team"`
+	Stake  float64 `json:"stake"` // Customer stake
+	Price  float64 `json:"price"` // Decimal odds laid
+}
+
+// PnLScenario is a single simulated season outcome for the book
+type PnLScenario struct {
+	Path    int      `json:"path"`
+	PnL     float64  `json:"pnl"`
+	Payouts []string `json:"payouts"` // Selections that paid out, as "{Market}: {Team}"
+}
+
+// ExposureReport summarizes the book's simulated P&L for one league
+type ExposureReport struct {
+	League         string        `json:"league"`
+	Positions      int           `json:"positions"`
+	TotalStake     float64       `json:"total_stake"`
+	ExpectedPnL    float64       `json:"expected_pnl"`
+	Variance       float64       `json:"variance"`
+	StdDev         float64       `json:"std_dev"`
+	WorstCase      float64       `json:"worst_case"` // Minimum P&L over all paths
+	ValueAtRisk95  float64       `json:"var_95"`     // 5th percentile P&L
+	WorstScenarios []PnLScenario `json:"worst_scenarios"`
+}
+
+// worstScenarioCount is the number of worst paths reported per league
+const worstScenarioCount = 5
+
+// CalculateExposure simulates the book's P&L per league for a set of positions
+// Each path pays stake * price * payoff to the customer, so fractional payoffs (e.g. playoff places) pay pro rata
+func CalculateExposure(result *MultiLeagueResult, positions []Position) (map[string]ExposureReport, error) {
+	marketsByKey := make(map[string]Market)
+	for _, market := range result.Markets {
+		marketsByKey[market.League+"/"+market.Name] = market
+	}
+
+	// Group positions by league, validating each against the markets and simulations
+	positionsByLeague := make(map[string][]Position)
+	for _, position := range positions {
+		market, exists := marketsByKey[position.League+"/"+position.Market]
+		if !exists {
+			return nil, fmt.Errorf("position references unknown market %s in league %s", position.Market, position.League)
+		}
+		if !containsString(market.Teams, position.Team) {
+			return nil, fmt.Errorf("position team %s is not in market %s", position.Team, position.Market)
+		}
+		if _, err := result.simulation(position.League); err != nil {
+			return nil, err
+		}
+		if position.Stake < 0 || position.Price < 1 {
+			return nil, fmt.Errorf("position %s/%s has invalid stake %v or price %v", position.Market, position.Team, position.Stake, position.Price)
+		}
+		positionsByLeague[position.League] = append(positionsByLeague[position.League], position)
+	}
+
+	reports := make(map[string]ExposureReport)
+	for league, leaguePositions := range positionsByLeague {
+		simPoints := result.Simulations[league]
+		pnl := make([]float64, simPoints.NPaths)
+		payouts := make([][]string, simPoints.NPaths)
+		totalStake := 0.0
+
+		// Per-path payoffs are computed once per market and shared by its positions
+		payoffCache := make(map[string]map[string][]float64)
+		for _, position := range leaguePositions {
+			market := marketsByKey[league+"/"+position.Market]
+			teamPayoffs, exists := payoffCache[market.Name]
+			if !exists {
+				teamPayoffs = simPoints.pathPayoffs(market.Teams, market.ParsedPayoff)
+				payoffCache[market.Name] = teamPayoffs
+			}
+
+			totalStake += position.Stake
+			for path, payoff := range teamPayoffs[position.Team] {
+				pnl[path] += position.Stake - position.Stake*position.Price*payoff
+				if payoff > 0 {
+					payouts[path] = append(payouts[path], position.Market+": "+position.Team)
+				}
+			}
+		}
+
+		reports[league] = summarizeExposure(league, len(leaguePositions), totalStake, pnl, payouts)
+	}
+
+	return reports, nil
+}
+
+// summarizeExposure computes moments, tail risk and worst scenarios from per-path P&L
+func summarizeExposure(league string, positions int, totalStake float64, pnl []float64, payouts [][]string) ExposureReport {
+	if len(pnl) == 0 {
+		return ExposureReport{League: league, Positions: positions, TotalStake: totalStake}
+	}
+
+	n := float64(len(pnl))
+	mean, meanSquare := 0.0, 0.0
+	for _, value := range pnl {
+		mean += value
+		meanSquare += value * value
+	}
+	mean /= n
+	variance := math.Max(0, meanSquare/n-mean*mean)
+
+	order := make([]int, len(pnl))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return pnl[order[i]] < pnl[order[j]]
+	})
+
+	var worst []PnLScenario
+	for _, path := range order[:minInt(worstScenarioCount, len(order))] {
+		worst = append(worst, PnLScenario{
+			Path:    path,
+			PnL:     pnl[path],
+			Payouts: payouts[path],
+		})
+	}
+
+	return ExposureReport{
+		League:         league,
+		Positions:      positions,
+		TotalStake:     totalStake,
+		ExpectedPnL:    mean,
+		Variance:       variance,
+		StdDev:         math.Sqrt(variance),
+		WorstCase:      pnl[order[0]],
+		ValueAtRisk95:  pnl[order[int(0.05*float64(len(order)))]],
+		WorstScenarios: worst,
+	}
+}
+
+// FormatPayouts joins scenario payouts for display
+func (s PnLScenario) FormatPayouts() string {
+	if len(s.Payouts) == 0 {
+		return "-"
+	}
+	return strings.Join(s.Payouts, ", ")
+}
+
+// containsString reports whether values contains target
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// minInt returns the smaller of two ints
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,39 @@
+package outrightsmle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// DataFingerprint identifies the inputs a result was produced from, as SHA-256 hex digests, so
+// saved output can be traced to its dataset. Equal digests mean equal inputs
+type DataFingerprint struct {
+	Events    string `json:"events"`              // EventsFingerprint of the events as passed (before as-of truncation and renames)
+	Markets   string `json:"markets,omitempty"`   // Digest of the markets as passed, in order
+	Handicaps string `json:"handicaps,omitempty"` // Digest of the handicaps
+	Params    string `json:"params,omitempty"`    // Digest of the saved or supplied MLEParams (PredictSeason or MLEOptions.SuppliedRatings only)
+}
+
+// newDataFingerprint fingerprints a run's inputs; empty markets and handicaps leave their digest empty
+func newDataFingerprint(events []MatchResult, markets []Market, handicaps map[string]int) DataFingerprint {
+	fingerprint := DataFingerprint{Events: EventsFingerprint(events)}
+	if len(markets) > 0 {
+		fingerprint.Markets = jsonFingerprint(markets)
+	}
+	if len(handicaps) > 0 {
+		fingerprint.Handicaps = jsonFingerprint(handicaps)
+	}
+	return fingerprint
+}
+
+// jsonFingerprint returns the SHA-256 hex digest of v's JSON, which is stable since encoding/json
+// writes map keys in sorted order
+func jsonFingerprint(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,133 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LeagueFormat describes a league's structure for market generation
+type LeagueFormat struct {
+	Teams         int `json:"teams"`          // Number of teams
+	Rounds        int `json:"rounds"`         // Times each ordered pair meets (1 = home and away once)
+	Promoted      int `json:"promoted"`       // Automatic promotion places (0 for a top division)
+	PlayoffPlaces int `json:"playoff_places"` // Promotion playoff places below the automatic spots
+	Relegated     int `json:"relegated"`      // Relegation places (0 for a bottom division)
+
+	// Optional relegation playoff: the team in this many places above the automatic relegation
+	// spots plays a two-legged tie against a lower-division side (e.g., 16th in an 18-team league)
+	RelegationPlayoff     int      `json:"relegation_playoff"`                // 0 = no relegation playoff
+	RelegationPlayoffLoss *float64 `json:"relegation_playoff_loss,omitempty"` // Fixed probability the playoff team is relegated (nil = 0.5)
+}
+
+// StandardLeagueFormats returns the formats of the leagues shipped in core-data, plus the
+// English women's leagues (WSL and WSL2, as 12-team divisions with one up and one down)
+func StandardLeagueFormats() map[string]LeagueFormat {
+	return map[string]LeagueFormat{
+		"ENG1": {Teams: 20, Rounds: 1, Promoted: 0, PlayoffPlaces: 0, Relegated: 3},
+		"ENG2": {Teams: 24, Rounds: 1, Promoted: 2, PlayoffPlaces: 4, Relegated: 3},
+		"ENG3": {Teams: 24, Rounds: 1, Promoted: 2, PlayoffPlaces: 4, Relegated: 4},
+		"ENG4": {Teams: 24, Rounds: 1, Promoted: 3, PlayoffPlaces: 4, Relegated: 2},
+		"WSL":  {Teams: 12, Rounds: 1, Promoted: 0, PlayoffPlaces: 0, Relegated: 1},
+		"WSL2": {Teams: 12, Rounds: 1, Promoted: 1, PlayoffPlaces: 0, Relegated: 1},
+	}
+}
+
+// StandardMarkets generates the usual outright markets for a league from its format
+// Playoff places pay 1/PlayoffPlaces in the Promotion market (one playoff winner is promoted)
+func StandardMarkets(league string, format LeagueFormat) ([]Market, error) {
+	n := format.Teams
+	if n < 2 {
+		return nil, fmt.Errorf("league %s format needs at least 2 teams, got %d", league, n)
+	}
+	if format.Promoted+format.PlayoffPlaces+format.Relegated+format.RelegationPlayoff > n {
+		return nil, fmt.Errorf("league %s format has more promotion/relegation places than teams", league)
+	}
+	if format.RelegationPlayoff > 0 && format.Relegated == 0 {
+		return nil, fmt.Errorf("league %s format has a relegation playoff but no relegation places", league)
+	}
+	if loss := format.RelegationPlayoffLoss; loss != nil && (*loss < 0 || *loss > 1) {
+		return nil, fmt.Errorf("league %s relegation playoff loss probability must be in [0, 1], got %v", league, *loss)
+	}
+
+	var markets []Market
+	add := func(name string, segments ...payoffSegment) {
+		markets = append(markets, Market{
+			Name:   name,
+			League: league,
+			Payoff: formatPayoff(segments),
+		})
+	}
+
+	add("Winner", payoffSegment{1, 1}, payoffSegment{n - 1, 0})
+	for _, k := range []int{2, 4, 6} {
+		if k < n/2 {
+			add("Top "+numberWord(k), payoffSegment{k, 1}, payoffSegment{n - k, 0})
+		}
+	}
+	half := n / 2
+	add("Top Half", payoffSegment{half, 1}, payoffSegment{n - half, 0})
+	add("Bottom Half", payoffSegment{n - half, 0}, payoffSegment{half, 1})
+
+	if format.Promoted > 0 {
+		rest := n - format.Promoted - format.PlayoffPlaces
+		if format.PlayoffPlaces > 0 {
+			playoffShare := 1 / float64(format.PlayoffPlaces)
+			add("Promotion", payoffSegment{format.Promoted, 1}, payoffSegment{format.PlayoffPlaces, playoffShare}, payoffSegment{rest, 0})
+			add("To Make The Playoffs", payoffSegment{format.Promoted, 0}, payoffSegment{format.PlayoffPlaces, 1}, payoffSegment{rest, 0})
+		} else {
+			add("Promotion", payoffSegment{format.Promoted, 1}, payoffSegment{rest, 0})
+		}
+	}
+
+	if format.Relegated > 0 {
+		if format.RelegationPlayoff > 0 {
+			// Playoff place pays the chance of losing the tie, so the mark is P(finish there) x P(lose).
+			// The tie is not simulated: its outcome is a fixed probability, not priced from ratings
+			playoffLoss := 0.5
+			if format.RelegationPlayoffLoss != nil {
+				playoffLoss = *format.RelegationPlayoffLoss
+			}
+			safe := n - format.Relegated - format.RelegationPlayoff
+			add("Relegation", payoffSegment{safe, 0}, payoffSegment{format.RelegationPlayoff, playoffLoss}, payoffSegment{format.Relegated, 1})
+			add("Relegation Playoff", payoffSegment{safe, 0}, payoffSegment{format.RelegationPlayoff, 1}, payoffSegment{format.Relegated, 0})
+		} else {
+			add("Relegation", payoffSegment{n - format.Relegated, 0}, payoffSegment{format.Relegated, 1})
+		}
+	}
+	add("Bottom", payoffSegment{n - 1, 0}, payoffSegment{1, 1})
+
+	return markets, nil
+}
+
+// payoffSegment is a run of consecutive positions sharing one payout
+type payoffSegment struct {
+	count int
+	value float64
+}
+
+// formatPayoff renders segments in payoff expression form, e.g. "2x1|4x0.25|18x0"
+func formatPayoff(segments []payoffSegment) string {
+	var parts []string
+	for _, segment := range segments {
+		if segment.count <= 0 {
+			continue
+		}
+		value := strconv.FormatFloat(segment.value, 'g', -1, 64)
+		if segment.count == 1 {
+			parts = append(parts, value)
+		} else {
+			parts = append(parts, fmt.Sprintf("%dx%s", segment.count, value))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// numberWord spells out small counts for market names ("Top Four")
+func numberWord(n int) string {
+	words := []string{"Zero", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Eleven", "Twelve"}
+	if n >= 0 && n < len(words) {
+		return words[n]
+	}
+	return strconv.Itoa(n)
+}
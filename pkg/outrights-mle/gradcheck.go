@@ -0,0 +1,95 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// GradientCheck compares the analytic likelihood gradients with central finite differences
+type GradientCheck struct {
+	Matches          int     `json:"matches"`            // Matches in the subsample
+	Parameters       int     `json:"parameters"`         // Ratings checked
+	Step             float64 `json:"step"`               // Finite difference step
+	MaxRelativeError float64 `json:"max_relative_error"` // Largest |analytic - numeric| / max(|analytic|, |numeric|, 1)
+	WorstParameter   string  `json:"worst_parameter"`    // Rating with the largest error, e.g. "Arsenal_attack"
+	Analytic         float64 `json:"analytic"`           // Analytic gradient of the worst parameter
+	Numeric          float64 `json:"numeric"`            // Finite difference gradient of the worst parameter
+}
+
+// defaultGradientStep is the finite difference step on the log-rate ratings
+const defaultGradientStep = 1e-5
+
+// CheckGradients fits ratings on the request, then compares the likelihood gradients updateRatings
+// uses (before the entry prior) with central finite differences of the log likelihood on an evenly spaced subsample of sampleSize
+// matches (0 = all). Use it to verify the analytic gradients after changing the likelihood
+// The subsample keeps the gradients away from zero at the fitted ratings, where errors would hide
+func CheckGradients(request MLERequest, sampleSize int) (*GradientCheck, error) {
+	matches, err := ingestMatches(request.HistoricalData, request.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	request.HistoricalData = matches
+	if err := validateRequest(request); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if sampleSize < 0 {
+		return nil, fmt.Errorf("sample size must not be negative, got %d", sampleSize)
+	}
+
+	options := request.Options
+	if options.SimParams == nil {
+		options.SimParams = DefaultSimParams()
+	}
+	solver := NewMLESolver(request.HistoricalData, options, request.LeagueChangeTeams)
+	if _, err := solver.Optimize(); err != nil {
+		return nil, fmt.Errorf("MLE optimization failed: %w", err)
+	}
+
+	sample := solver.matches
+	if sampleSize > 0 && sampleSize < len(sample) {
+		sample = make([]MatchResult, sampleSize)
+		for i := range sample {
+			sample[i] = solver.matches[i*len(solver.matches)/sampleSize]
+		}
+	}
+	return solver.checkGradients(sample, defaultGradientStep), nil
+}
+
+// checkGradients compares gradients(matches) with central differences at the current parameters
+func (s *MLESolver) checkGradients(matches []MatchResult, step float64) *GradientCheck {
+	analytic := s.gradients(matches)
+	keys := make([]string, 0, len(analytic))
+	for key := range analytic {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	check := &GradientCheck{Matches: len(matches), Parameters: len(keys), Step: step}
+	for _, key := range keys {
+		ratings := s.params.AttackRatings
+		team := strings.TrimSuffix(key, "_attack")
+		if strings.HasSuffix(key, "_defense") {
+			ratings = s.params.DefenseRatings
+			team = strings.TrimSuffix(key, "_defense")
+		}
+
+		original := ratings[team]
+		ratings[team] = original + step
+		above := s.logLikelihood(matches)
+		ratings[team] = original - step
+		below := s.logLikelihood(matches)
+		ratings[team] = original
+		numeric := (above - below) / (2 * step)
+
+		scale := math.Max(math.Max(math.Abs(analytic[key]), math.Abs(numeric)), 1)
+		if relative := math.Abs(analytic[key]-numeric) / scale; relative > check.MaxRelativeError || check.WorstParameter == "" {
+			check.MaxRelativeError = relative
+			check.WorstParameter = key
+			check.Analytic = analytic[key]
+			check.Numeric = numeric
+		}
+	}
+	return check
+}
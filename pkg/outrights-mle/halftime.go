@@ -0,0 +1,140 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+)
+
+// HalfTimeModel splits each side's expected goals between the two halves
+type HalfTimeModel struct {
+	HomeShare float64 `json:"home_share"` // Fraction of home goals scored in the first half
+	AwayShare float64 `json:"away_share"` // Fraction of away goals scored in the first half
+	Matches   int     `json:"matches"`    // Matches with half-time scores the shares were fitted on
+}
+
+// HalfTimeOdds holds first-half and half-time/full-time probabilities for one fixture
+type HalfTimeOdds struct {
+	Fixture          string             `json:"fixture"`
+	League           string             `json:"league"`
+	HalfTime         [3]float64         `json:"half_time"`       // First-half [home, draw, away]
+	HalfTimeFullTime map[string]float64 `json:"ht_ft"`           // "home/draw" etc. -> P(half-time outcome / full-time outcome)
+	FirstHalfOver    []float64          `json:"first_half_over"` // index n -> P(more than n first-half goals), i.e. over n+0.5
+}
+
+// firstHalfLines is the number of first-half over/under lines priced (0.5, 1.5, 2.5)
+const firstHalfLines = 3
+
+// FitHalfTimeModel estimates the share of home and away goals scored before half-time from the
+// matches that carry half-time scores; matches without them are skipped
+func FitHalfTimeModel(matches []MatchResult) (*HalfTimeModel, error) {
+	var homeFirstHalf, homeTotal, awayFirstHalf, awayTotal, count int
+	for _, match := range matches {
+		if match.HalfTimeHomeGoals == nil || match.HalfTimeAwayGoals == nil {
+			continue
+		}
+		halfTimeHome, halfTimeAway := *match.HalfTimeHomeGoals, *match.HalfTimeAwayGoals
+		if halfTimeHome < 0 || halfTimeAway < 0 || halfTimeHome > match.HomeGoals || halfTimeAway > match.AwayGoals {
+			return nil, fmt.Errorf("match %s vs %s on %s has half-time score %d-%d inconsistent with full-time %d-%d",
+				match.HomeTeam, match.AwayTeam, match.Date, halfTimeHome, halfTimeAway, match.HomeGoals, match.AwayGoals)
+		}
+		homeFirstHalf += halfTimeHome
+		awayFirstHalf += halfTimeAway
+		homeTotal += match.HomeGoals
+		awayTotal += match.AwayGoals
+		count++
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no matches with half-time scores")
+	}
+	if homeTotal == 0 || awayTotal == 0 {
+		return nil, fmt.Errorf("half-time model needs home and away goals, got %d and %d over %d matches", homeTotal, awayTotal, count)
+	}
+
+	return &HalfTimeModel{
+		HomeShare: float64(homeFirstHalf) / float64(homeTotal),
+		AwayShare: float64(awayFirstHalf) / float64(awayTotal),
+		Matches:   count,
+	}, nil
+}
+
+// PriceHalfTimeFixtures prices HT/FT and first-half over/under markets for "{Home} vs {Away}" fixtures
+// Each half is an independent Poisson match with the model's share of the full-match expected goals,
+// so the implied full-time result leaves out the Dixon-Coles adjustment used by PriceFixtures. Only
+// Poisson goals split that way, so params fitted with another count model are an error
+// Uses DefaultSimParams if simParams is nil (GoalSimulationBound applies to each half)
+func PriceHalfTimeFixtures(params MLEParams, simParams *SimParams, model *HalfTimeModel, fixtures []string, league string) ([]HalfTimeOdds, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	if !params.poissonGoals() {
+		return nil, fmt.Errorf("half-time prices need Poisson goals, but params use the %s count model", params.CountModel)
+	}
+	if model == nil {
+		return nil, fmt.Errorf("half-time model is required")
+	}
+	if model.HomeShare <= 0 || model.HomeShare >= 1 || model.AwayShare <= 0 || model.AwayShare >= 1 {
+		return nil, fmt.Errorf("half-time shares must be in (0, 1), got home %v and away %v", model.HomeShare, model.AwayShare)
+	}
+
+	params = params.forLeague(league)
+
+	outcomes := []string{OutcomeHomeWin, OutcomeDraw, OutcomeAwayWin}
+	halfTimeOdds := make([]HalfTimeOdds, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		homeTeam, awayTeam, err := fixtureTeams(params, fixture)
+		if err != nil {
+			return nil, err
+		}
+
+		lambdaHome := cappedLambda(math.Exp(params.Intercept+params.AttackRatings[homeTeam]-params.DefenseRatings[awayTeam]+params.HomeAdvantage), simParams)
+		lambdaAway := cappedLambda(math.Exp(params.Intercept+params.AttackRatings[awayTeam]-params.DefenseRatings[homeTeam]), simParams)
+		firstHalf := NewScoreMatrix(lambdaHome*model.HomeShare, lambdaAway*model.AwayShare, 0, simParams.GoalSimulationBound)
+		secondHalf := NewScoreMatrix(lambdaHome*(1-model.HomeShare), lambdaAway*(1-model.AwayShare), 0, simParams.GoalSimulationBound)
+
+		// Combine every first-half score with every second-half score
+		var combined [3][3]float64
+		for homeFirst := 0; homeFirst <= firstHalf.HomeGoals; homeFirst++ {
+			for awayFirst := 0; awayFirst <= firstHalf.AwayGoals; awayFirst++ {
+				p := firstHalf.Matrix[homeFirst][awayFirst]
+				halfTime := resultIndex(homeFirst, awayFirst)
+				for homeSecond := 0; homeSecond <= secondHalf.HomeGoals; homeSecond++ {
+					for awaySecond := 0; awaySecond <= secondHalf.AwayGoals; awaySecond++ {
+						fullTime := resultIndex(homeFirst+homeSecond, awayFirst+awaySecond)
+						combined[halfTime][fullTime] += p * secondHalf.Matrix[homeSecond][awaySecond]
+					}
+				}
+			}
+		}
+
+		odds := HalfTimeOdds{
+			Fixture:          fixture,
+			League:           league,
+			HalfTime:         firstHalf.MatchOdds(),
+			HalfTimeFullTime: make(map[string]float64, 9),
+			FirstHalfOver:    make([]float64, firstHalfLines),
+		}
+		for i, halfTime := range outcomes {
+			for j, fullTime := range outcomes {
+				odds.HalfTimeFullTime[halfTime+"/"+fullTime] = combined[i][j]
+			}
+		}
+		for line := range odds.FirstHalfOver {
+			odds.FirstHalfOver[line], _ = firstHalf.OverUnder(line)
+		}
+		halfTimeOdds = append(halfTimeOdds, odds)
+	}
+
+	return halfTimeOdds, nil
+}
+
+// resultIndex returns 0 for a home win, 1 for a draw and 2 for an away win
+func resultIndex(homeGoals, awayGoals int) int {
+	switch {
+	case homeGoals > awayGoals:
+		return 0
+	case homeGoals == awayGoals:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,49 @@
+package outrightsmle
+
+import "fmt"
+
+// HeadToHeadRecord is two teams' meetings and the model's current prices for the fixture both ways
+type HeadToHeadRecord struct {
+	TeamA         string        `json:"team_a"`
+	TeamB         string        `json:"team_b"`
+	Matches       []MatchResult `json:"matches"`         // Meetings in any competition, oldest first
+	Stats         MatchStats    `json:"stats"`           // Meetings from TeamA's perspective
+	AverageGoalsA float64       `json:"average_goals_a"` // TeamA's goals per meeting (0 without meetings)
+	AverageGoalsB float64       `json:"average_goals_b"` // TeamB's goals per meeting
+	TeamAHome     MatchOdds     `json:"team_a_home"`     // Current price of "TeamA vs TeamB"
+	TeamBHome     MatchOdds     `json:"team_b_home"`     // Current price of "TeamB vs TeamA"
+}
+
+// HeadToHead returns the meetings of teamA and teamB in matches, their aggregate record, and both
+// fixtures priced from params, e.g. for match previews or for checking ratings against history
+// Both teams need ratings; the prices carry the league of the latest meeting
+// Uses DefaultSimParams if simParams is nil
+func HeadToHead(matches []MatchResult, params MLEParams, simParams *SimParams, teamA, teamB string) (*HeadToHeadRecord, error) {
+	if teamA == "" || teamB == "" || teamA == teamB {
+		return nil, fmt.Errorf("head to head needs two different teams, got %q and %q", teamA, teamB)
+	}
+
+	meetings := QueryMatches(matches).ByTeam(teamA).Against(teamB)
+	record := &HeadToHeadRecord{
+		TeamA:   teamA,
+		TeamB:   teamB,
+		Matches: meetings.Matches(),
+		Stats:   meetings.Stats(),
+	}
+	league := ""
+	if n := len(record.Matches); n > 0 {
+		league = record.Matches[n-1].League
+		record.AverageGoalsA = float64(record.Stats.GoalsFor) / float64(n)
+		record.AverageGoalsB = float64(record.Stats.GoalsAgainst) / float64(n)
+	}
+
+	odds, err := PriceFixtures(params, simParams, []string{
+		fmt.Sprintf("%s vs %s", teamA, teamB),
+		fmt.Sprintf("%s vs %s", teamB, teamA),
+	}, league)
+	if err != nil {
+		return nil, err
+	}
+	record.TeamAHome, record.TeamBHome = odds[0], odds[1]
+	return record, nil
+}
@@ -0,0 +1,86 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// initialSeasonHomeAdvantage returns the starting per-season home advantage: the supplied schedule,
+// plus every other season at HomeAdvantage (or its warm-start value) when FitSeasonHomeAdvantage is set
+// Returns nil when neither applies, so every match uses the single HomeAdvantage
+func (s *MLESolver) initialSeasonHomeAdvantage() map[string]float64 {
+	simParams := s.options.SimParams
+	if len(simParams.SeasonHomeAdvantage) == 0 && !simParams.FitSeasonHomeAdvantage {
+		return nil
+	}
+
+	seasonHomeAdvantage := copyMap(simParams.SeasonHomeAdvantage)
+	if simParams.FitSeasonHomeAdvantage {
+		for _, match := range s.matches {
+			if _, exists := seasonHomeAdvantage[match.Season]; exists {
+				continue
+			}
+			seasonHomeAdvantage[match.Season] = simParams.HomeAdvantage
+			if s.initialParams != nil {
+				if value, exists := s.initialParams.SeasonHomeAdvantage[match.Season]; exists {
+					seasonHomeAdvantage[match.Season] = value
+				}
+			}
+		}
+	}
+	return seasonHomeAdvantage
+}
+
+// updateSeasonHomeAdvantage takes one Newton step for each fitted season's home advantage, with a
+// normal prior centred on HomeAdvantage so a season with few matches stays close to the default
+// Seasons in the supplied SeasonHomeAdvantage schedule are held fixed
+func (s *MLESolver) updateSeasonHomeAdvantage() {
+	simParams := s.options.SimParams
+	priorVariance := simParams.HomeAdvantagePriorVariance
+	if priorVariance <= 0 {
+		priorVariance = DefaultSimParams().HomeAdvantagePriorVariance
+	}
+
+	gradients := make(map[string]float64)
+	curvatures := make(map[string]float64)
+	for _, match := range s.matches {
+		if match.Neutral {
+			continue
+		}
+		if _, fixed := simParams.SeasonHomeAdvantage[match.Season]; fixed {
+			continue
+		}
+		weight := s.getMatchWeight(match)
+		lambdaHome, capped := capLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.HomeTeam]-s.params.DefenseRatings[match.AwayTeam]+s.params.SeasonHomeAdvantage[match.Season]+s.covariateTerm(match)), simParams)
+		if capped {
+			continue // A rate held at MaxLambda does not move with the home advantage
+		}
+		gradients[match.Season] += weight * (float64(match.HomeGoals) - lambdaHome)
+		curvatures[match.Season] += weight * lambdaHome
+	}
+
+	for season, gradient := range gradients {
+		value := s.params.SeasonHomeAdvantage[season]
+		gradient -= (value - simParams.HomeAdvantage) / priorVariance
+		s.params.SeasonHomeAdvantage[season] = value + gradient/(curvatures[season]+1/priorVariance)
+	}
+}
+
+// finishHomeAdvantage sets HomeAdvantage to the latest season's value, which pricing and season
+// simulation use, once per-season values are known
+func (s *MLESolver) finishHomeAdvantage() {
+	value, exists := s.params.SeasonHomeAdvantage[s.latestSeason]
+	if !exists {
+		return
+	}
+	s.params.HomeAdvantage = value
+
+	if s.options.Debug {
+		var parts []string
+		for _, season := range sortedKeys(s.params.SeasonHomeAdvantage) {
+			parts = append(parts, fmt.Sprintf("%s=%.3f", season, s.params.SeasonHomeAdvantage[season]))
+		}
+		fmt.Printf("🏠 Season home advantage: %s (current: %.3f)\n", strings.Join(parts, ", "), value)
+	}
+}
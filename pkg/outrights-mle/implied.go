@@ -0,0 +1,222 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ImpliedFixture is one fixture's de-margined market prices and the lambdas that reproduce them
+type ImpliedFixture struct {
+	Fixture       string     `json:"fixture"`
+	League        string     `json:"league"`
+	Probabilities [3]float64 `json:"probabilities"` // De-margined [home, draw, away], averaged across bookmakers
+	Bookmakers    int        `json:"bookmakers"`
+	LambdaHome    float64    `json:"lambda_home"`
+	LambdaAway    float64    `json:"lambda_away"`
+	FitError      float64    `json:"fit_error"` // Largest gap between the lambdas' 1X2 and the market's
+}
+
+// MarketRatings is a rating set implied by bookmaker 1X2 prices
+type MarketRatings struct {
+	Params   MLEParams        `json:"params"`   // Ratings for the priced teams, usable with PriceFixtures
+	Fixtures []ImpliedFixture `json:"fixtures"` // Fixtures in name order
+	RMSE     float64          `json:"rmse"`     // Root mean square error of the ratings against the fixtures' log lambdas
+}
+
+const (
+	impliedPriorWeight    = 0.1   // Ridge weight pulling each team toward its prior rating
+	impliedMaxSweeps      = 500   // Coordinate descent sweeps for the rating fit
+	impliedTolerance      = 1e-10 // Largest rating change that ends the fit
+	impliedMaxNewtonSteps = 100   // Newton steps when inverting one fixture's prices
+)
+
+// ImpliedLambdas back-solves the home and away scoring rates whose Dixon-Coles score matrix gives
+// the home and away win probabilities of a de-margined 1X2 set; the draw follows from them
+// Returns the rates and the largest remaining probability gap
+func ImpliedLambdas(probabilities [3]float64, rho float64, bound int) (float64, float64, float64, error) {
+	for i, p := range probabilities {
+		if p <= 0 || p >= 1 {
+			return 0, 0, 0, fmt.Errorf("probability %d must be in (0, 1), got %v", i, p)
+		}
+	}
+	if bound < 1 {
+		return 0, 0, 0, fmt.Errorf("goal bound must be at least 1, got %d", bound)
+	}
+
+	// Solve in log space so the rates stay positive
+	residuals := func(x, y float64) (float64, float64) {
+		odds := normalizedOdds(NewScoreMatrix(math.Exp(x), math.Exp(y), rho, bound))
+		return odds[0] - probabilities[0], odds[2] - probabilities[2]
+	}
+	x, y := math.Log(1.4), math.Log(1.1)
+	const step = 1e-6
+	for i := 0; i < impliedMaxNewtonSteps; i++ {
+		home, away := residuals(x, y)
+		if math.Max(math.Abs(home), math.Abs(away)) < 1e-12 {
+			break
+		}
+		homeX, awayX := residuals(x+step, y)
+		homeY, awayY := residuals(x, y+step)
+		a, b := (homeX-home)/step, (homeY-home)/step
+		c, d := (awayX-away)/step, (awayY-away)/step
+		determinant := a*d - b*c
+		if determinant == 0 {
+			break
+		}
+		dx := (d*home - b*away) / determinant
+		dy := (a*away - c*home) / determinant
+		// Damp large steps, which overshoot far from the solution
+		if size := math.Max(math.Abs(dx), math.Abs(dy)); size > 1 {
+			dx, dy = dx/size, dy/size
+		}
+		x, y = x-dx, y-dy
+	}
+
+	home, away := residuals(x, y)
+	odds := normalizedOdds(NewScoreMatrix(math.Exp(x), math.Exp(y), rho, bound))
+	fitError := math.Max(math.Max(math.Abs(home), math.Abs(away)), math.Abs(odds[1]-probabilities[1]))
+	return math.Exp(x), math.Exp(y), fitError, nil
+}
+
+// ImpliedRatings fits attack and defense ratings to bookmaker 1X2 prices for upcoming fixtures
+// Each fixture's prices are de-margined and averaged across bookmakers, then inverted to lambdas
+// with ImpliedLambdas; ratings are the least-squares fit to the log lambdas with home advantage,
+// rho and intercept held at the prior's values (SimParams when prior is nil). A light ridge pulls each team
+// toward its prior rating (zero without a prior), so teams priced in only one fixture stay
+// identified and the ratings sit on the prior's scale for comparing or blending
+// Uses DefaultSimParams if simParams is nil (GoalSimulationBound applies)
+func ImpliedRatings(odds []FixtureOdds, prior *MLEParams, simParams *SimParams) (*MarketRatings, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	homeAdvantage, rho, intercept := simParams.HomeAdvantage, simParams.Rho, 0.0
+	if prior != nil {
+		homeAdvantage, rho, intercept = prior.HomeAdvantage, prior.Rho, prior.Intercept
+	}
+
+	// Average the de-margined probabilities per fixture across bookmakers
+	type fixtureSum struct {
+		odds          FixtureOdds
+		probabilities [3]float64
+		bookmakers    int
+	}
+	sums := make(map[string]*fixtureSum)
+	for _, fixtureOdds := range odds {
+		if fixtureOdds.HomeTeam == "" || fixtureOdds.AwayTeam == "" || fixtureOdds.HomeTeam == fixtureOdds.AwayTeam {
+			return nil, fmt.Errorf("fixture %q needs two different teams", fixtureOdds.Fixture())
+		}
+		for i, price := range fixtureOdds.Prices {
+			if price <= 1 {
+				return nil, fmt.Errorf("fixture %s from %s has price %d of %v; decimal prices must exceed 1",
+					fixtureOdds.Fixture(), fixtureOdds.Bookmaker, i, price)
+			}
+		}
+		sum, exists := sums[fixtureOdds.Fixture()]
+		if !exists {
+			sum = &fixtureSum{odds: fixtureOdds}
+			sums[fixtureOdds.Fixture()] = sum
+		}
+		implied := fixtureOdds.ImpliedProbabilities()
+		for i := range implied {
+			sum.probabilities[i] += implied[i]
+		}
+		sum.bookmakers++
+	}
+	if len(sums) == 0 {
+		return nil, fmt.Errorf("no fixture prices to invert")
+	}
+
+	result := &MarketRatings{}
+	for _, name := range sortedKeys(sums) {
+		sum := sums[name]
+		var probabilities [3]float64
+		for i := range probabilities {
+			probabilities[i] = sum.probabilities[i] / float64(sum.bookmakers)
+		}
+		lambdaHome, lambdaAway, fitError, err := ImpliedLambdas(probabilities, rho, simParams.GoalSimulationBound)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", name, err)
+		}
+		result.Fixtures = append(result.Fixtures, ImpliedFixture{
+			Fixture:       name,
+			League:        sum.odds.League,
+			Probabilities: probabilities,
+			Bookmakers:    sum.bookmakers,
+			LambdaHome:    lambdaHome,
+			LambdaAway:    lambdaAway,
+			FitError:      fitError,
+		})
+	}
+
+	// Teams and their prior ratings
+	teamSet := make(map[string]bool)
+	for _, fixture := range result.Fixtures {
+		homeTeam, awayTeam := parseEventName(fixture.Fixture)
+		teamSet[homeTeam] = true
+		teamSet[awayTeam] = true
+	}
+	teams := make([]string, 0, len(teamSet))
+	for team := range teamSet {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	attack := make(map[string]float64, len(teams))
+	defense := make(map[string]float64, len(teams))
+	priorAttack := make(map[string]float64, len(teams))
+	priorDefense := make(map[string]float64, len(teams))
+	for _, team := range teams {
+		if prior != nil {
+			priorAttack[team] = prior.AttackRatings[team]
+			priorDefense[team] = prior.DefenseRatings[team]
+		}
+		attack[team], defense[team] = priorAttack[team], priorDefense[team]
+	}
+
+	// Coordinate descent: each rating has a closed-form update given the others
+	// log λ_home = intercept + attack[home] - defense[away] + homeAdvantage,
+	// log λ_away = intercept + attack[away] - defense[home]
+	for sweep := 0; sweep < impliedMaxSweeps; sweep++ {
+		change := 0.0
+		for _, team := range teams {
+			attackTarget, defenseTarget := impliedPriorWeight*priorAttack[team], impliedPriorWeight*priorDefense[team]
+			count := impliedPriorWeight
+			for _, fixture := range result.Fixtures {
+				homeTeam, awayTeam := parseEventName(fixture.Fixture)
+				switch team {
+				case homeTeam:
+					attackTarget += math.Log(fixture.LambdaHome) + defense[awayTeam] - homeAdvantage - intercept
+					defenseTarget += intercept + attack[awayTeam] - math.Log(fixture.LambdaAway)
+					count++
+				case awayTeam:
+					attackTarget += math.Log(fixture.LambdaAway) + defense[homeTeam] - intercept
+					defenseTarget += intercept + attack[homeTeam] + homeAdvantage - math.Log(fixture.LambdaHome)
+					count++
+				}
+			}
+			newAttack, newDefense := attackTarget/count, defenseTarget/count
+			change = math.Max(change, math.Max(math.Abs(newAttack-attack[team]), math.Abs(newDefense-defense[team])))
+			attack[team], defense[team] = newAttack, newDefense
+		}
+		if change < impliedTolerance {
+			break
+		}
+	}
+
+	squares := 0.0
+	for _, fixture := range result.Fixtures {
+		homeTeam, awayTeam := parseEventName(fixture.Fixture)
+		homeResidual := math.Log(fixture.LambdaHome) - (intercept + attack[homeTeam] - defense[awayTeam] + homeAdvantage)
+		awayResidual := math.Log(fixture.LambdaAway) - (intercept + attack[awayTeam] - defense[homeTeam])
+		squares += homeResidual*homeResidual + awayResidual*awayResidual
+	}
+	result.RMSE = math.Sqrt(squares / float64(2*len(result.Fixtures)))
+	result.Params = MLEParams{
+		HomeAdvantage:  homeAdvantage,
+		Rho:            rho,
+		Intercept:      intercept,
+		AttackRatings:  attack,
+		DefenseRatings: defense,
+	}
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ImportanceTarget is a finishing position range whose probability a fixture can swing
+type ImportanceTarget struct {
+	Name string `json:"name"`
+	From int    `json:"from"` // First position in the range (1 = top)
+	To   int    `json:"to"`   // Last position in the range (inclusive)
+}
+
+// OutcomeSwing is one team's target probability conditional on each result of a fixture
+type OutcomeSwing struct {
+	Team   string  `json:"team"`
+	Target string  `json:"target"`
+	Home   float64 `json:"home"`  // P(target | home win)
+	Draw   float64 `json:"draw"`  // P(target | draw)
+	Away   float64 `json:"away"`  // P(target | away win)
+	Swing  float64 `json:"swing"` // Largest minus smallest conditional probability
+}
+
+// FixtureImportance measures how much a remaining fixture's result moves its teams' outright chances
+type FixtureImportance struct {
+	Fixture    string         `json:"fixture"`
+	Importance float64        `json:"importance"` // Sum of swings across both teams and all targets
+	Swings     []OutcomeSwing `json:"swings"`
+}
+
+// DefaultImportanceTargets returns title, promotion/top-four, playoff and relegation ranges for a format
+func DefaultImportanceTargets(format LeagueFormat) []ImportanceTarget {
+	targets := []ImportanceTarget{{Name: "Title", From: 1, To: 1}}
+	if format.Promoted > 0 {
+		targets = append(targets, ImportanceTarget{Name: "Promotion", From: 1, To: format.Promoted})
+		if format.PlayoffPlaces > 0 {
+			targets = append(targets, ImportanceTarget{Name: "Playoffs", From: format.Promoted + 1, To: format.Promoted + format.PlayoffPlaces})
+		}
+	} else if format.Teams > 8 {
+		targets = append(targets, ImportanceTarget{Name: "Top Four", From: 1, To: 4})
+	}
+	if format.Relegated > 0 {
+		targets = append(targets, ImportanceTarget{Name: "Relegation", From: format.Teams - format.Relegated + 1, To: format.Teams})
+	}
+	return targets
+}
+
+// CalculateMatchImportance ranks a league's simulated fixtures by how much their result swings each
+// team's target probabilities, conditioning the retained simulation paths on each fixture outcome
+// Uses DefaultImportanceTargets for the league's standard format if targets is nil
+func CalculateMatchImportance(result *MultiLeagueResult, league string, targets []ImportanceTarget) ([]FixtureImportance, error) {
+	simPoints, err := result.simulation(league)
+	if err != nil {
+		return nil, err
+	}
+	nTeams := len(simPoints.TeamNames)
+
+	if targets == nil {
+		format, known := StandardLeagueFormats()[league]
+		if !known || format.Teams != nTeams {
+			format = LeagueFormat{Teams: nTeams, Relegated: minInt(3, nTeams/4)}
+		}
+		targets = DefaultImportanceTargets(format)
+	}
+	for _, target := range targets {
+		if target.From < 1 || target.To > nTeams || target.From > target.To {
+			return nil, fmt.Errorf("invalid target %s range [%d, %d] for %d teams", target.Name, target.From, target.To, nTeams)
+		}
+	}
+
+	// Per path, each team's share of every target range (fractional when dead-heated)
+	allTeams := simPoints.selectTeams(simPoints.TeamNames)
+	shares := make([][][]float64, len(targets)) // target -> team -> path
+	for t := range targets {
+		shares[t] = make([][]float64, nTeams)
+		for team := range shares[t] {
+			shares[t][team] = make([]float64, simPoints.NPaths)
+		}
+	}
+	for path := 0; path < simPoints.NPaths; path++ {
+		pos := 0
+		for _, group := range simPoints.pathStandings(allTeams, path) {
+			for t, target := range targets {
+				inRange := 0
+				for groupPos := pos + 1; groupPos <= pos+len(group); groupPos++ {
+					if groupPos >= target.From && groupPos <= target.To {
+						inRange++
+					}
+				}
+				if inRange == 0 {
+					continue
+				}
+				share := float64(inRange) / float64(len(group))
+				for _, team := range group {
+					shares[t][allTeams[team]][path] = share
+				}
+			}
+			pos += len(group)
+		}
+	}
+
+	importance := make([]FixtureImportance, 0, len(simPoints.Fixtures))
+	for f, fixture := range simPoints.Fixtures {
+		outcomes := simPoints.outcomes[f]
+		var outcomeCounts [3]float64
+		for _, outcome := range outcomes {
+			outcomeCounts[outcome]++
+		}
+
+		homeTeam, awayTeam := parseEventName(fixture)
+		entry := FixtureImportance{Fixture: fixture}
+		for _, team := range []string{homeTeam, awayTeam} {
+			teamIdx := simPoints.getTeamIndex(team)
+			for t, target := range targets {
+				var sums [3]float64
+				for path, outcome := range outcomes {
+					sums[outcome] += shares[t][teamIdx][path]
+				}
+				var conditional [3]float64
+				for outcome := range conditional {
+					if outcomeCounts[outcome] > 0 {
+						conditional[outcome] = sums[outcome] / outcomeCounts[outcome]
+					}
+				}
+
+				// Only outcomes that occurred on some path count toward the swing
+				low, high := 1.0, 0.0
+				for outcome, probability := range conditional {
+					if outcomeCounts[outcome] == 0 {
+						continue
+					}
+					if probability < low {
+						low = probability
+					}
+					if probability > high {
+						high = probability
+					}
+				}
+				swing := 0.0
+				if high > low {
+					swing = high - low
+				}
+				if swing == 0 {
+					continue
+				}
+
+				entry.Swings = append(entry.Swings, OutcomeSwing{
+					Team:   team,
+					Target: target.Name,
+					Home:   conditional[outcomeHome],
+					Draw:   conditional[outcomeDraw],
+					Away:   conditional[outcomeAway],
+					Swing:  swing,
+				})
+				entry.Importance += swing
+			}
+		}
+		sort.SliceStable(entry.Swings, func(i, j int) bool {
+			return entry.Swings[i].Swing > entry.Swings[j].Swing
+		})
+		importance = append(importance, entry)
+	}
+
+	sort.SliceStable(importance, func(i, j int) bool {
+		return importance[i].Importance > importance[j].Importance
+	})
+	return importance, nil
+}
@@ -0,0 +1,164 @@
+package outrightsmle
+
+import "fmt"
+
+// leagueFit is the outcome of one isolated per-league fit
+type leagueFit struct {
+	result *MLEResult
+	err    error
+}
+
+// fitLeaguesIndependently fits one solver per league on that league's matches only (no
+// cross-league pooling or cup matches), running the fits in a bounded worker pool
+func fitLeaguesIndependently(leagues []string, request MLERequest, currentTeams map[string][]string) (map[string]*MLEResult, error) {
+	if request.Options.Debug {
+		fmt.Printf("\n🏈 Running %d independent per-league MLE optimizations...\n", len(leagues))
+	}
+
+	fits := runLeagueWorkers(leagues, request.Options.Workers, func(league string) leagueFit {
+		var leagueMatches []MatchResult
+		for _, match := range request.HistoricalData {
+			if match.League == league && match.Competition == "" {
+				leagueMatches = append(leagueMatches, match)
+			}
+		}
+
+		leagueRequest := MLERequest{
+			HistoricalData:    leagueMatches,
+			LeagueChangeTeams: request.LeagueChangeTeams,
+			Schedule:          request.Schedule,
+			Options:           request.Options,
+		}
+		// Overrides and multipliers name teams across all leagues: the fit takes those of teams in
+		// its matches, and teams rated afterwards by rateUnseenTeams take theirs below
+		leagueTeams := ExtractTeams(leagueMatches)
+		fittedOverrides, unseenOverrides := splitByTeams(request.Options.RatingOverrides, leagueTeams)
+		fittedMultipliers, unseenMultipliers := splitByTeams(request.Options.LambdaMultipliers, leagueTeams)
+		leagueRequest.Options.RatingOverrides = fittedOverrides
+		leagueRequest.Options.LambdaMultipliers = fittedMultipliers
+		if teams, exists := request.LeagueGroups[league]; exists {
+			leagueRequest.LeagueGroups = map[string][]string{league: teams}
+		}
+
+		result, err := RunSimulation(leagueRequest)
+		if err != nil {
+			return leagueFit{err: fmt.Errorf("league %s: %w", league, err)}
+		}
+		rateUnseenTeams(result, currentTeams[league], request.Options.SimParams.EntryPriorQuantile)
+		if len(unseenOverrides) > 0 || len(unseenMultipliers) > 0 {
+			params := applyRatingOverrides(&result.MLEParams, unseenOverrides)
+			result.MLEParams = *applyLambdaMultipliers(params, unseenMultipliers)
+			result.Teams = teamsFromParams(&result.MLEParams)
+		}
+		return leagueFit{result: result}
+	})
+
+	results := make(map[string]*MLEResult, len(leagues))
+	for i, fit := range fits {
+		if fit.err != nil {
+			return nil, fit.err
+		}
+		results[leagues[i]] = fit.result
+		if request.Options.Debug {
+			fmt.Printf("✅ %s: %d iterations, converged=%v\n", leagues[i], fit.result.MLEParams.Iterations, fit.result.MLEParams.Converged)
+		}
+	}
+	return results, nil
+}
+
+// rateUnseenTeams gives current teams with no matches in the league's own data (e.g., a promoted
+// side named in league groups) the EntryPriorQuantile of the fitted current teams, so they are
+// still simulated; with the prior disabled they are rated average
+func rateUnseenTeams(result *MLEResult, teams []string, quantile float64) {
+	params := &result.MLEParams
+	var rated, unseen []string
+	for _, team := range teams {
+		if _, exists := params.AttackRatings[team]; exists {
+			rated = append(rated, team)
+		} else {
+			unseen = append(unseen, team)
+		}
+	}
+	if len(unseen) == 0 {
+		return
+	}
+
+	attack, defense := 0.0, 0.0
+	if quantile > 0 && len(rated) > 0 {
+		attacks := make([]float64, len(rated))
+		defenses := make([]float64, len(rated))
+		for i, team := range rated {
+			attacks[i] = params.AttackRatings[team]
+			defenses[i] = params.DefenseRatings[team]
+		}
+		attack, defense = quantileOf(attacks, quantile), quantileOf(defenses, quantile)
+	}
+	for _, team := range unseen {
+		params.AttackRatings[team] = attack
+		params.DefenseRatings[team] = defense
+	}
+	result.Teams = teamsFromParams(params)
+}
+
+// mergeLeagueFits combines isolated fits into one MLEResult; a team rated in several leagues
+// takes the rating from the league it currently plays in
+func mergeLeagueFits(leagues []string, fits map[string]*MLEResult, currentTeams map[string][]string) *MLEResult {
+	merged := &MLEResult{
+		MLEParams: MLEParams{
+			AttackRatings:  make(map[string]float64),
+			DefenseRatings: make(map[string]float64),
+			Converged:      true,
+		},
+	}
+
+	for _, league := range leagues {
+		fit := fits[league]
+		// Each fit has its own intercepts, so ratings are merged with them folded into attack
+		merged.MLEParams.HomeAdvantage = fit.MLEParams.HomeAdvantage
+		merged.MLEParams.Rho = fit.MLEParams.Rho
+		merged.MLEParams.CountModel, merged.MLEParams.CountShape = fit.MLEParams.CountModel, fit.MLEParams.CountShape
+		merged.MLEParams.LogLikelihood += fit.MLEParams.LogLikelihood
+		if fit.MLEParams.Iterations > merged.MLEParams.Iterations {
+			merged.MLEParams.Iterations = fit.MLEParams.Iterations
+		}
+		merged.MLEParams.Converged = merged.MLEParams.Converged && fit.MLEParams.Converged
+		merged.MatchOdds = append(merged.MatchOdds, fit.MatchOdds...)
+		merged.MatchesProcessed += fit.MatchesProcessed
+		if fit.ProcessingTime > merged.ProcessingTime {
+			merged.ProcessingTime = fit.ProcessingTime
+		}
+
+		for _, team := range currentTeams[league] {
+			if attack, exists := fit.MLEParams.AttackRatings[team]; exists {
+				merged.MLEParams.AttackRatings[team] = attack + fit.MLEParams.teamIntercept(team)
+				merged.MLEParams.DefenseRatings[team] = fit.MLEParams.DefenseRatings[team]
+			}
+		}
+	}
+
+	// Teams no longer in any current league keep the rating from the first league that has one
+	for _, league := range leagues {
+		fit := fits[league]
+		for team, attack := range fit.MLEParams.AttackRatings {
+			if _, exists := merged.MLEParams.AttackRatings[team]; !exists {
+				merged.MLEParams.AttackRatings[team] = attack + fit.MLEParams.teamIntercept(team)
+				merged.MLEParams.DefenseRatings[team] = fit.MLEParams.DefenseRatings[team]
+			}
+		}
+	}
+
+	// Overrides and availability multipliers are recorded whichever league applied them
+	for _, league := range leagues {
+		merged.MLEParams.RatingOverrides = append(merged.MLEParams.RatingOverrides, fits[league].MLEParams.RatingOverrides...)
+		for team, multiplier := range fits[league].MLEParams.LambdaMultipliers {
+			if merged.MLEParams.LambdaMultipliers == nil {
+				merged.MLEParams.LambdaMultipliers = make(map[string]LambdaMultiplier)
+			}
+			merged.MLEParams.LambdaMultipliers[team] = multiplier
+		}
+	}
+	sortRatingOverrides(merged.MLEParams.RatingOverrides)
+
+	merged.Teams = teamsFromParams(&merged.MLEParams)
+	return merged
+}
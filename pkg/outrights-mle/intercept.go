@@ -0,0 +1,159 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// initialIntercept returns the starting scoring intercept: the warm start's when there is one,
+// otherwise the log of the mean away goals per match, which is the intercept for average ratings
+// Zero unless SimParams.FitIntercept is set
+func (s *MLESolver) initialIntercept() float64 {
+	if !s.options.SimParams.FitIntercept {
+		return 0
+	}
+	if s.initialParams != nil && s.initialParams.Intercept != 0 {
+		return s.initialParams.Intercept
+	}
+	goals := 0
+	for _, match := range s.matches {
+		goals += match.AwayGoals
+	}
+	if goals == 0 {
+		return 0
+	}
+	return math.Log(float64(goals) / float64(len(s.matches)))
+}
+
+// latestTeamLeagues returns each team's league in its latest league match, the league whose
+// intercept its ratings are measured against
+func latestTeamLeagues(matches []MatchResult) map[string]string {
+	teamLeagues := make(map[string]string)
+	latest := make(map[string]string)
+	for _, match := range matches {
+		if match.Competition != "" {
+			continue
+		}
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if match.Date >= latest[team] {
+				latest[team] = match.Date
+				teamLeagues[team] = match.League
+			}
+		}
+	}
+	return teamLeagues
+}
+
+// initialLeagueIntercepts returns the starting league intercepts, zero or the warm start's value
+// for every league with league matches. Nil unless SimParams.FitLeagueIntercepts is set
+func (s *MLESolver) initialLeagueIntercepts() map[string]float64 {
+	if !s.options.SimParams.FitLeagueIntercepts {
+		return nil
+	}
+	intercepts := make(map[string]float64)
+	for _, match := range s.matches {
+		if match.Competition != "" {
+			continue
+		}
+		intercepts[match.League] = 0
+		if s.initialParams != nil {
+			intercepts[match.League] = s.initialParams.LeagueIntercepts[match.League]
+		}
+	}
+	return intercepts
+}
+
+// balanceLeagueRatings moves each league's scoring level out of its teams' ratings and into its
+// intercept: attack falls and defense rises by half the gap between their league averages, which
+// leaves the rates of its teams' matches in the league, and in cups, unchanged
+func (s *MLESolver) balanceLeagueRatings() {
+	attackSums := make(map[string]float64)
+	defenseSums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, team := range sortedKeys(s.teamNames) {
+		league, exists := s.params.TeamLeagues[team]
+		if !exists {
+			continue
+		}
+		attackSums[league] += s.params.AttackRatings[team]
+		defenseSums[league] += s.params.DefenseRatings[team]
+		counts[league]++
+	}
+
+	gaps := make(map[string]float64, len(counts))
+	for league, count := range counts {
+		gaps[league] = (attackSums[league] - defenseSums[league]) / float64(count)
+		s.params.LeagueIntercepts[league] += gaps[league]
+	}
+	for team := range s.teamNames {
+		league, exists := s.params.TeamLeagues[team]
+		if !exists {
+			continue
+		}
+		s.params.AttackRatings[team] -= gaps[league] / 2
+		s.params.DefenseRatings[team] += gaps[league] / 2
+	}
+}
+
+// matchIntercept returns the intercept for a match: Intercept plus the league's intercept for a
+// league match, or plus the mean of the two teams' league intercepts for a cup match
+func (s *MLESolver) matchIntercept(match MatchResult) float64 {
+	league := match.League
+	if match.Competition != "" {
+		league = ""
+	}
+	return s.params.fixtureIntercept(match.HomeTeam, match.AwayTeam, league)
+}
+
+// fixtureIntercept returns the intercept for homeTeam against awayTeam in league, as matchIntercept;
+// pass an empty league, or one without an intercept, for a match between leagues
+func (p *MLEParams) fixtureIntercept(homeTeam, awayTeam, league string) float64 {
+	if len(p.LeagueIntercepts) == 0 {
+		return p.Intercept
+	}
+	if intercept, exists := p.LeagueIntercepts[league]; exists {
+		return p.Intercept + intercept
+	}
+	return p.Intercept + (p.LeagueIntercepts[p.TeamLeagues[homeTeam]]+p.LeagueIntercepts[p.TeamLeagues[awayTeam]])/2
+}
+
+// teamIntercept returns Intercept plus the intercept of team's league
+func (p *MLEParams) teamIntercept(team string) float64 {
+	return p.Intercept + p.LeagueIntercepts[p.TeamLeagues[team]]
+}
+
+// forLeague returns a copy of params for pricing fixtures in league, with the league intercepts
+// resolved so that code reading only Intercept prices them correctly: league's intercept is added
+// to Intercept, or for an empty league, or one without an intercept, each team's league intercept
+// is split evenly between its attack and defense
+func (p MLEParams) forLeague(league string) MLEParams {
+	if len(p.LeagueIntercepts) == 0 {
+		return p
+	}
+	resolved := p
+	resolved.LeagueIntercepts, resolved.TeamLeagues = nil, nil
+	if intercept, exists := p.LeagueIntercepts[league]; exists {
+		resolved.Intercept += intercept
+		return resolved
+	}
+	resolved.AttackRatings = copyMap(p.AttackRatings)
+	resolved.DefenseRatings = copyMap(p.DefenseRatings)
+	for team, teamLeague := range p.TeamLeagues {
+		if _, exists := resolved.AttackRatings[team]; !exists {
+			continue
+		}
+		resolved.AttackRatings[team] += p.LeagueIntercepts[teamLeague] / 2
+		resolved.DefenseRatings[team] -= p.LeagueIntercepts[teamLeague] / 2
+	}
+	return resolved
+}
+
+// printLeagueIntercepts shows the fitted league intercepts
+func (s *MLESolver) printLeagueIntercepts() {
+	var parts []string
+	for _, league := range sortedKeys(s.params.LeagueIntercepts) {
+		parts = append(parts, fmt.Sprintf("%s=%+.3f", league, s.params.LeagueIntercepts[league]))
+	}
+	fmt.Printf("⚽ League intercepts: %s (intercept %.3f)\n", strings.Join(parts, ", "), s.params.Intercept)
+}
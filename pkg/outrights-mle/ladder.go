@@ -0,0 +1,104 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PriceLadder lists the decimal prices a bookmaker will quote, in ascending order
+type PriceLadder []float64
+
+// LadderBand sets the tick size for prices from From up to the next band's From
+type LadderBand struct {
+	From float64 `json:"from"`
+	Tick float64 `json:"tick"`
+}
+
+// standardTickBands is the common exchange ladder: 1.01-2 in 0.01, 2-3 in 0.02, ... 100-1000 in 10
+var standardTickBands = []LadderBand{
+	{1.01, 0.01}, {2, 0.02}, {3, 0.05}, {4, 0.1}, {6, 0.2},
+	{10, 0.5}, {20, 1}, {30, 2}, {50, 5}, {100, 10},
+}
+
+// TickLadder builds a ladder from tick bands (in ascending From order) up to and including max
+func TickLadder(bands []LadderBand, max float64) (PriceLadder, error) {
+	var ladder PriceLadder
+	for i, band := range bands {
+		if band.From <= 1 || band.Tick <= 0 {
+			return nil, fmt.Errorf("ladder band %d must start above 1.0 with a positive tick, got from=%v tick=%v", i, band.From, band.Tick)
+		}
+		end := max
+		if i+1 < len(bands) {
+			if bands[i+1].From <= band.From {
+				return nil, fmt.Errorf("ladder bands must be in ascending order, got %v after %v", bands[i+1].From, band.From)
+			}
+			end = bands[i+1].From
+		}
+		// Step by multiples of the tick from the band start, rounding away accumulated float error
+		for k := 0; ; k++ {
+			price := math.Round((band.From+float64(k)*band.Tick)*1e6) / 1e6
+			if price > max || price >= end && i+1 < len(bands) {
+				break
+			}
+			ladder = append(ladder, price)
+		}
+	}
+	return ladder, nil
+}
+
+// StandardTickLadder returns the common exchange ladder from 1.01 to 1000
+func StandardTickLadder() PriceLadder {
+	ladder, _ := TickLadder(standardTickBands, 1000)
+	return ladder
+}
+
+// FractionalPriceLadder returns the traditional fractional ladder (1/100 ... 1000/1) as decimal prices
+func FractionalPriceLadder() PriceLadder {
+	ladder := make(PriceLadder, len(fractionalLadder))
+	for i, fraction := range fractionalLadder {
+		ladder[i] = 1 + float64(fraction[0])/float64(fraction[1])
+	}
+	return ladder
+}
+
+// Snap returns the ladder price nearest to price, clamped to the ends of the ladder
+// Prices of 1.0 or less (certainties) and an empty ladder return price unchanged
+func (ladder PriceLadder) Snap(price float64) float64 {
+	if len(ladder) == 0 || price <= 1 {
+		return price
+	}
+	i := sort.SearchFloat64s(ladder, price)
+	switch {
+	case i == 0:
+		return ladder[0]
+	case i == len(ladder):
+		return ladder[len(ladder)-1]
+	case ladder[i]-price < price-ladder[i-1]:
+		return ladder[i]
+	default:
+		return ladder[i-1]
+	}
+}
+
+// validate checks the ladder is strictly ascending with every price above 1.0
+func (ladder PriceLadder) validate() error {
+	for i, price := range ladder {
+		if price <= 1 {
+			return fmt.Errorf("price ladder entries must be above 1.0, got %v", price)
+		}
+		if i > 0 && price <= ladder[i-1] {
+			return fmt.Errorf("price ladder must be strictly ascending, got %v after %v", price, ladder[i-1])
+		}
+	}
+	return nil
+}
+
+// snapEdgeReports sets each entry's LadderPrice to its fair price on the ladder
+func snapEdgeReports(reports map[string]EdgeReport, ladder PriceLadder) {
+	for _, report := range reports {
+		for i := range report.Entries {
+			report.Entries[i].LadderPrice = ladder.Snap(report.Entries[i].FairPrice)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ManagerChange records a team appointing a new manager, after which its past results say less
+// about its current strength
+type ManagerChange struct {
+	Team    string `json:"team"`              // Canonical team name
+	Date    string `json:"date"`              // Date the new manager took charge (YYYY-MM-DD)
+	Manager string `json:"manager,omitempty"` // New manager, for reference only
+}
+
+// managerChangeDecayMatches is the number of matches under a new manager over which the static
+// fit's enhanced learning rate decays back to the base rate
+const managerChangeDecayMatches = 10
+
+// validateManagerChanges checks each change names a team in the data and a valid date
+func validateManagerChanges(changes []ManagerChange, teams []string) error {
+	for _, change := range changes {
+		if _, err := time.Parse("2006-01-02", change.Date); err != nil {
+			return fmt.Errorf("manager change for %s has invalid date %q: expected YYYY-MM-DD", change.Team, change.Date)
+		}
+		if !containsString(teams, change.Team) {
+			return &UnknownTeamError{Team: change.Team, Context: "manager changes"}
+		}
+	}
+	return nil
+}
+
+// managerChangeDates returns each team's manager change dates, oldest first
+func managerChangeDates(changes []ManagerChange) map[string][]string {
+	dates := make(map[string][]string)
+	for _, change := range changes {
+		dates[change.Team] = append(dates[change.Team], change.Date)
+	}
+	for _, teamDates := range dates {
+		sort.Strings(teamDates)
+	}
+	return dates
+}
+
+// matchesUnderManager returns, for each team with a manager change, the number of its matches on or
+// after its latest change; zero when the new manager has not played yet
+func matchesUnderManager(matches []MatchResult, changes map[string][]string) map[string]int {
+	counts := make(map[string]int, len(changes))
+	for team := range changes {
+		counts[team] = 0
+	}
+	for _, match := range matches {
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if dates, exists := changes[team]; exists && match.Date >= dates[len(dates)-1] {
+				counts[team]++
+			}
+		}
+	}
+	return counts
+}
+
+// managerChangeFactor returns the learning rate multiplier for a team under a new manager: the full
+// ManagerChangeLearningRate until the manager's first match, decaying linearly to 1.0 over
+// managerChangeDecayMatches matches
+func (s *MLESolver) managerChangeFactor(team string) float64 {
+	played, exists := s.managerMatches[team]
+	if !exists || played >= managerChangeDecayMatches {
+		return 1.0
+	}
+	enhancementRange := s.options.SimParams.ManagerChangeLearningRate - 1.0
+	return 1.0 + enhancementRange*(1-float64(played)/managerChangeDecayMatches)
+}
@@ -0,0 +1,118 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Margin methods for spreading an overround across a probability set
+const (
+	MarginProportional      = "proportional"       // Every probability scaled by 1 + overround
+	MarginFavouriteWeighted = "favourite_weighted" // Margin shared in proportion to probability squared
+)
+
+// ApplyMargin adds a target overround to a probability set, returning booked probabilities
+// that sum to total*(1 + overround), where total is the set's own sum (1 for 1X2 or a winner
+// market, n for a top-n market); booked probabilities are capped at 1
+func ApplyMargin(probabilities []float64, overround float64, method string) ([]float64, error) {
+	if overround < 0 {
+		return nil, fmt.Errorf("overround must be non-negative, got %v", overround)
+	}
+	if err := validateMarginMethod(method); err != nil {
+		return nil, err
+	}
+
+	total, squares := 0.0, 0.0
+	for _, probability := range probabilities {
+		if probability < 0 {
+			return nil, fmt.Errorf("probabilities must be non-negative, got %v", probability)
+		}
+		total += probability
+		squares += probability * probability
+	}
+	margin := overround * total
+
+	booked := make([]float64, len(probabilities))
+	for i, probability := range probabilities {
+		if method == MarginFavouriteWeighted && squares > 0 {
+			booked[i] = probability + margin*probability*probability/squares
+		} else {
+			booked[i] = probability * (1 + overround)
+		}
+		if booked[i] > 1 {
+			booked[i] = 1
+		}
+	}
+	return booked, nil
+}
+
+// BookablePrices applies a margin to a probability set and returns decimal prices (1/booked);
+// a selection with zero probability gets a price of 0 (not offered)
+func BookablePrices(probabilities []float64, overround float64, method string) ([]float64, error) {
+	booked, err := ApplyMargin(probabilities, overround, method)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]float64, len(booked))
+	for i, probability := range booked {
+		if probability > 0 {
+			prices[i] = 1 / probability
+		}
+	}
+	return prices, nil
+}
+
+// validateMargins checks the configured overrounds and margin method
+func validateMargins(simParams *SimParams) error {
+	if simParams.MatchOverround < 0 || simParams.OutrightOverround < 0 {
+		return fmt.Errorf("overrounds must be non-negative, got match=%v outright=%v", simParams.MatchOverround, simParams.OutrightOverround)
+	}
+	return validateMarginMethod(simParams.MarginMethod)
+}
+
+// validateMarginMethod checks a margin method name; empty means proportional
+func validateMarginMethod(method string) error {
+	switch method {
+	case MarginProportional, MarginFavouriteWeighted, "":
+		return nil
+	}
+	return fmt.Errorf("unknown margin method %q (expected %s or %s)", method, MarginProportional, MarginFavouriteWeighted)
+}
+
+// matchBookOdds returns bookable [home, draw, away] prices, or nil unless SimParams.MatchOverround is set
+func matchBookOdds(probabilities [3]float64, simParams *SimParams) []float64 {
+	if simParams == nil || simParams.MatchOverround <= 0 {
+		return nil
+	}
+	prices, err := BookablePrices(probabilities[:], simParams.MatchOverround, simParams.MarginMethod)
+	if err != nil {
+		return nil
+	}
+	return prices
+}
+
+// marketBookPrices applies the outright margin to each market's marks (market -> team -> price)
+func marketBookPrices(values map[string]map[string]float64, simParams *SimParams) map[string]map[string]float64 {
+	prices := make(map[string]map[string]float64, len(values))
+	for market, teamMarks := range values {
+		teams := make([]string, 0, len(teamMarks))
+		for team := range teamMarks {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+
+		marks := make([]float64, len(teams))
+		for i, team := range teams {
+			marks[i] = teamMarks[team]
+		}
+		booked, err := BookablePrices(marks, simParams.OutrightOverround, simParams.MarginMethod)
+		if err != nil {
+			continue
+		}
+		prices[market] = make(map[string]float64, len(teams))
+		for i, team := range teams {
+			prices[market][team] = booked[i]
+		}
+	}
+	return prices
+}
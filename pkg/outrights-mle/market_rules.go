@@ -0,0 +1,103 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Exclude rule types
+const (
+	ExcludeByRating     = "rating"      // Exclude the N highest-rated teams (attack + defense) after fitting
+	ExcludeByLastSeason = "last_season" // Exclude last season's top N finishers still in the league
+)
+
+// ExcludeRule defines a market's excluded teams by rule, evaluated at run time
+// e.g. {"by": "rating", "top": 6} for a "Without Big Six" market that follows the ratings
+type ExcludeRule struct {
+	By  string `json:"by"`  // ExcludeByRating or ExcludeByLastSeason
+	Top int    `json:"top"` // Number of teams to exclude
+}
+
+// validateExcludeRule checks a rule market's shape before its teams are known
+func validateExcludeRule(market *Market, teamNames []string) error {
+	rule := market.ExcludeRule
+	if len(market.Include) > 0 || len(market.Exclude) > 0 {
+		return fmt.Errorf("market %s cannot combine exclude_rule with include or exclude fields", market.Name)
+	}
+	if rule.By != ExcludeByRating && rule.By != ExcludeByLastSeason {
+		return fmt.Errorf("market %s has unknown exclude_rule type %q (use %q or %q)", market.Name, rule.By, ExcludeByRating, ExcludeByLastSeason)
+	}
+	if rule.Top < 1 || rule.Top >= len(teamNames) {
+		return fmt.Errorf("market %s exclude_rule top (%d) must be between 1 and %d", market.Name, rule.Top, len(teamNames)-1)
+	}
+
+	parsedPayoff, err := parsePayoff(market.Payoff)
+	if err != nil {
+		return fmt.Errorf("error parsing payoff for market %s: %v", market.Name, err)
+	}
+	if expected := len(teamNames) - rule.Top; len(parsedPayoff) != expected {
+		return fmt.Errorf("%s exclude rule market payoff length (%d) does not match remaining teams count (%d)",
+			market.Name, len(parsedPayoff), expected)
+	}
+	return nil
+}
+
+// applyLastSeasonRule sets Exclude to the top finishers of the league's previous season among current teams
+// currentSeason is the in-progress season, or "" when the current teams come from league groups
+// The previous table is ranked with the league's tiebreak chain
+func applyLastSeasonRule(market *Market, teamNames []string, leagueEvents []MatchResult, currentSeason string, tiebreaks []string) error {
+	lastSeason := ""
+	for _, event := range leagueEvents {
+		if (currentSeason == "" || event.Season < currentSeason) && event.Season > lastSeason {
+			lastSeason = event.Season
+		}
+	}
+	if lastSeason == "" {
+		return fmt.Errorf("market %s exclude_rule needs a previous season for league %s", market.Name, market.League)
+	}
+
+	table := calcLeagueTable(teamNames, convertMatchResultsToEvents(leagueEvents, lastSeason), nil, tiebreaks)
+
+	// Only teams still in the league can be excluded; teams new to the league rank below everyone
+	excluded := make([]string, 0, market.ExcludeRule.Top)
+	for _, team := range table {
+		if team.Played > 0 && containsString(teamNames, team.Name) && len(excluded) < market.ExcludeRule.Top {
+			excluded = append(excluded, team.Name)
+		}
+	}
+	if len(excluded) < market.ExcludeRule.Top {
+		return fmt.Errorf("market %s exclude_rule found only %d of last season's teams in league %s", market.Name, len(excluded), market.League)
+	}
+
+	market.Exclude = excluded
+	return nil
+}
+
+// resolveRatingExcludeRules sets Exclude for rating-rule markets from fitted ratings and initializes them
+func resolveRatingExcludeRules(markets []Market, currentTeams map[string][]string, params MLEParams) error {
+	for i := range markets {
+		market := &markets[i]
+		if market.ExcludeRule == nil || market.ExcludeRule.By != ExcludeByRating {
+			continue
+		}
+
+		teamNames := currentTeams[market.League]
+		ranked := make([]string, len(teamNames))
+		copy(ranked, teamNames)
+		strength := func(team string) float64 {
+			return params.AttackRatings[team] + params.DefenseRatings[team]
+		}
+		sort.SliceStable(ranked, func(a, b int) bool {
+			return strength(ranked[a]) > strength(ranked[b])
+		})
+
+		market.Exclude = ranked[:market.ExcludeRule.Top]
+		if err := initExcludeMarket(teamNames, market); err != nil {
+			return err
+		}
+		if err := validateEachWay(market, len(market.Teams)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,13 @@ import (
 	"strings"
 )
 
+// Market types
+const (
+	MarketTypePosition   = ""            // Payoff by finishing position (default)
+	MarketTypePointsLine = "points_line" // Over/under a season points line per team; marks are P(over)
+	MarketTypeGoalsLine  = "goals_line"  // Over/under a season goals-scored line per team; marks are P(over)
+)
+
 
 // parsePayoff parses payoff expressions like "1|4x0.25|19x0" meaning 1 winner gets 1, 4 get 0.25, 19 losers get 0
 // Adapted from go-outrights/pkg/outrights/markets.go
@@ -60,7 +67,7 @@ func initIncludeMarket(teamNames []string, market *Market) error {
 			}
 		}
 		if !found {
-			return fmt.Errorf("%s market has unknown team %s in league %s", market.Name, teamName, market.League)
+			return &UnknownTeamError{Team: teamName, Context: market.Name + " market", League: market.League}
 		}
 	}
 	
@@ -100,7 +107,7 @@ func initExcludeMarket(teamNames []string, market *Market) error {
 			}
 		}
 		if !found {
-			return fmt.Errorf("%s market has unknown team %s in league %s", market.Name, teamName, market.League)
+			return &UnknownTeamError{Team: teamName, Context: market.Name + " market", League: market.League}
 		}
 	}
 	
@@ -166,8 +173,57 @@ func initStandardMarket(teamNames []string, market *Market) error {
 	return nil
 }
 
+// initLineMarket initializes an over/under line market from its per-team lines
+func initLineMarket(teamNames []string, market *Market) error {
+	if len(market.Lines) == 0 {
+		return fmt.Errorf("%s market has no lines defined", market.Name)
+	}
+	if len(market.Include) > 0 || len(market.Exclude) > 0 || market.ExcludeRule != nil {
+		return fmt.Errorf("%s line market cannot have include, exclude or exclude_rule fields", market.Name)
+	}
+	
+	market.Teams = []string{}
+	for _, teamName := range teamNames {
+		if _, exists := market.Lines[teamName]; exists {
+			market.Teams = append(market.Teams, teamName)
+		}
+	}
+	if len(market.Teams) != len(market.Lines) {
+		for teamName := range market.Lines {
+			if !containsString(teamNames, teamName) {
+				return &UnknownTeamError{Team: teamName, Context: market.Name + " market", League: market.League}
+			}
+		}
+	}
+	
+	return nil
+}
+
+// ValidateMarkets checks markets against each league's current teams (league -> team names, as in
+// league groups) without running the solver: leagues, types, payoff length against the team count,
+// include/exclude conflicts, unknown teams and each-way terms. Every market is checked, and the
+// problems are returned together as ValidationErrors (Field "markets[i]"); nil means all are valid
+// Last-season exclude rules need event data, so only their shape and payoff length are checked
+func ValidateMarkets(markets []Market, teamsByLeague map[string][]string) error {
+	var errors []ValidationError
+	for i := range markets {
+		market := []Market{markets[i]} // Initialization fills Teams and ParsedPayoff, so work on a copy
+		if err := validateAndInitializeMarkets(market, teamsByLeague, nil, "", nil); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("markets[%d]", i),
+				Message: err.Error(),
+			})
+		}
+	}
+	if len(errors) > 0 {
+		return ValidationErrors{Errors: errors}
+	}
+	return nil
+}
+
 // validateAndInitializeMarkets validates markets against current teams and initializes them
-func validateAndInitializeMarkets(markets []Market, currentTeams map[string][]string, eventsByLeague map[string][]MatchResult, latestSeason string) error {
+// eventsByLeague is nil when validating without event data; last-season rules are then left unresolved
+func validateAndInitializeMarkets(markets []Market, currentTeams map[string][]string, eventsByLeague map[string][]MatchResult, latestSeason string, tiebreaks map[string][]string) error {
 	for i := range markets {
 		market := &markets[i]
 		
@@ -182,11 +238,39 @@ func validateAndInitializeMarkets(markets []Market, currentTeams map[string][]st
 			return fmt.Errorf("market %s references unknown league %s", market.Name, market.League)
 		}
 		
+		// Line markets carry per-team lines instead of a payoff
+		if market.Type == MarketTypePointsLine || market.Type == MarketTypeGoalsLine {
+			if err := initLineMarket(teamNamesForLeague, market); err != nil {
+				return err
+			}
+			continue
+		}
+		if market.Type != MarketTypePosition {
+			return fmt.Errorf("market %s has unknown type %q", market.Name, market.Type)
+		}
+		
 		// Validate that market doesn't have both include and exclude
 		if len(market.Include) > 0 && len(market.Exclude) > 0 {
 			return fmt.Errorf("market %s cannot have both include and exclude fields", market.Name)
 		}
 		
+		// Rule-based exclusions: last season's finishers resolve now, rating rules after fitting
+		if market.ExcludeRule != nil {
+			if err := validateExcludeRule(market, teamNamesForLeague); err != nil {
+				return err
+			}
+			// A rule market's team count is fixed by its rule, so its each-way terms are checked before resolving
+			if err := validateEachWay(market, len(teamNamesForLeague)-market.ExcludeRule.Top); err != nil {
+				return err
+			}
+			if market.ExcludeRule.By == ExcludeByRating || eventsByLeague == nil {
+				continue
+			}
+			if err := applyLastSeasonRule(market, teamNamesForLeague, eventsByLeague[market.League], latestSeason, leagueTiebreaks(tiebreaks, market.League)); err != nil {
+				return err
+			}
+		}
+		
 		// Initialize teams based on include/exclude
 		var err error
 		if len(market.Include) > 0 {
@@ -200,7 +284,27 @@ func validateAndInitializeMarkets(markets []Market, currentTeams map[string][]st
 		if err != nil {
 			return err
 		}
+		
+		// Validate each-way terms against the market's teams
+		if err := validateEachWay(market, len(market.Teams)); err != nil {
+			return err
+		}
 	}
 	
+	return nil
+}
+
+// validateEachWay checks a market's each-way terms, if any, against its team count
+func validateEachWay(market *Market, teamCount int) error {
+	if market.EachWay == nil {
+		return nil
+	}
+	if market.EachWay.Places < 1 || market.EachWay.Places > teamCount {
+		return fmt.Errorf("market %s each-way places (%d) must be between 1 and the market's team count (%d)", 
+			market.Name, market.EachWay.Places, teamCount)
+	}
+	if market.EachWay.Fraction <= 0 || market.EachWay.Fraction > 1 {
+		return fmt.Errorf("market %s each-way fraction must be in (0, 1], got %v", market.Name, market.EachWay.Fraction)
+	}
 	return nil
 }
\ No newline at end of file
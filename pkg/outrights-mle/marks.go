@@ -1,13 +1,24 @@
 package outrightsmle
 
 import (
+	"math"
 	"strconv"
 	"strings"
 )
 
+// leagueMarks holds the mark calculations for one league, each keyed market -> team
+type leagueMarks struct {
+	Values      map[string]map[string]float64 // Expected payoff
+	StdErrors   map[string]map[string]float64 // Monte Carlo standard error, sqrt(Var(payoff)/NPaths)
+	PlaceValues map[string]map[string]float64 // Each-way place probability (each-way markets only)
+}
+
 // calculateMarkValues calculates mark values for markets using position probabilities from simulation
-func calculateMarkValues(simPoints *SimPoints, markets []Market, league string) map[string]map[string]float64 {
+func calculateMarkValues(simPoints *SimPoints, markets []Market, league string) *leagueMarks {
 	markValues := make(map[string]map[string]float64)
+	markStdErrors := make(map[string]map[string]float64)
+	placeValues := make(map[string]map[string]float64)
+	marks := &leagueMarks{Values: markValues, StdErrors: markStdErrors, PlaceValues: placeValues}
 	
 	// Filter markets for this league
 	var leagueMarkets []Market
@@ -18,12 +29,35 @@ func calculateMarkValues(simPoints *SimPoints, markets []Market, league string)
 	}
 	
 	if len(leagueMarkets) == 0 {
-		return markValues
+		return marks
 	}
 	
+	// Rank each path once for every market's teams
+	simPoints.positionProbabilitiesFor(positionMarketTeams(leagueMarkets, league))
+	
 	// Calculate mark value for each market
 	for _, market := range leagueMarkets {
 		teamMarks := make(map[string]float64)
+		teamStdErrors := make(map[string]float64)
+		teamPlaces := make(map[string]float64)
+		
+		// Line markets are priced from the final points or goals distribution rather than positions
+		if market.Type == MarketTypePointsLine || market.Type == MarketTypeGoalsLine {
+			totals := simPoints.Points
+			if market.Type == MarketTypeGoalsLine {
+				totals = simPoints.GoalsFor
+			}
+			for team, line := range market.Lines {
+				if idx := simPoints.getTeamIndex(team); idx >= 0 {
+					over := probabilityAbove(totals, idx, simPoints.NPaths, line)
+					teamMarks[team] = over
+					teamStdErrors[team] = monteCarloStdError(over, over, simPoints.NPaths)
+				}
+			}
+			markValues[market.Name] = teamMarks
+			markStdErrors[market.Name] = teamStdErrors
+			continue
+		}
 		
 		// Parse payoff structure (e.g., "1|4x0.25|19x0")
 		payoffParts := parsePayoffStructure(market.Payoff)
@@ -46,24 +80,81 @@ func calculateMarkValues(simPoints *SimPoints, markets []Market, league string)
 				// Team is in the market - calculate expected value
 				if teamProbs, exists := marketPositionProbs[teamName]; exists {
 					expectedValue := 0.0
+					expectedSquare := 0.0
 					
 					// Calculate expected payout based on position probabilities
 					for position, prob := range teamProbs {
 						if position < len(payoffParts) {
 							expectedValue += prob * payoffParts[position]
+							expectedSquare += prob * payoffParts[position] * payoffParts[position]
 						}
 					}
 					
 					teamMarks[teamName] = expectedValue
+					teamStdErrors[teamName] = monteCarloStdError(expectedValue, expectedSquare, simPoints.NPaths)
+					
+					// Each-way place component: probability of finishing within the place terms
+					if market.EachWay != nil {
+						placeProb := 0.0
+						for position := 0; position < market.EachWay.Places && position < len(teamProbs); position++ {
+							placeProb += teamProbs[position]
+						}
+						teamPlaces[teamName] = placeProb
+					}
 				}
 			}
 			// Teams excluded from market are not added to teamMarks (will be blank in display)
 		}
 		
 		markValues[market.Name] = teamMarks
+		markStdErrors[market.Name] = teamStdErrors
+		if market.EachWay != nil {
+			placeValues[market.Name] = teamPlaces
+		}
 	}
 	
-	return markValues
+	return marks
+}
+
+// positionMarketTeams returns the team sets of league's markets priced from finishing positions,
+// one per market; line markets are priced from totals instead
+func positionMarketTeams(markets []Market, league string) [][]string {
+	var teamSets [][]string
+	for _, market := range markets {
+		if market.League != league || market.Type == MarketTypePointsLine || market.Type == MarketTypeGoalsLine {
+			continue
+		}
+		teamSets = append(teamSets, market.Teams)
+	}
+	return teamSets
+}
+
+// probabilityAbove returns the fraction of a team's simulated values strictly above line
+func probabilityAbove(value func(team, path int) int, team, nPaths int, line float64) float64 {
+	if nPaths == 0 {
+		return 0
+	}
+	count := 0
+	for path := 0; path < nPaths; path++ {
+		if float64(value(team, path)) > line {
+			count++
+		}
+	}
+	return float64(count) / float64(nPaths)
+}
+
+// monteCarloStdError returns the standard error of a mean estimated from nPaths samples
+// given the sample mean E[X] and mean square E[X²]
+// With dead-heated positions E[X²] is taken over positions, which slightly overstates the error
+func monteCarloStdError(mean, meanSquare float64, nPaths int) float64 {
+	if nPaths <= 0 {
+		return 0
+	}
+	variance := meanSquare - mean*mean
+	if variance < 0 {
+		variance = 0 // Guard against floating point rounding
+	}
+	return math.Sqrt(variance / float64(nPaths))
 }
 
 // parsePayoffStructure parses payoff string like "1|4x0.25|19x0" into position-based payouts
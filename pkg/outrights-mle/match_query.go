@@ -0,0 +1,166 @@
+package outrightsmle
+
+import (
+	"sort"
+	"strings"
+)
+
+// MatchQuery selects match results fluently; each method returns a new query, so a base query can
+// be shared and narrowed in different ways
+//
+//	recent := QueryMatches(matches).ByTeam("Arsenal").ByLeague("ENG1").HomeOnly().Limit(10).Stats()
+type MatchQuery struct {
+	matches  []MatchResult
+	filters  []func(MatchResult) bool
+	team     string // ByTeam team, whose perspective Stats takes
+	homeOnly bool
+	limit    int
+}
+
+// MatchStats aggregates a query's matches, from the ByTeam team's perspective when there is one and
+// from the home side's otherwise
+type MatchStats struct {
+	Matches       int     `json:"matches"`
+	Wins          int     `json:"wins"`
+	Draws         int     `json:"draws"`
+	Losses        int     `json:"losses"`
+	GoalsFor      int     `json:"goals_for"`
+	GoalsAgainst  int     `json:"goals_against"`
+	Points        int     `json:"points"`          // 3 per win, 1 per draw
+	PointsPerGame float64 `json:"points_per_game"` // 0 without matches
+	Results       string  `json:"results"`         // W/D/L per match, oldest first (e.g. "WWDLW")
+}
+
+// QueryMatches starts a query over matches; the slice is not modified
+func QueryMatches(matches []MatchResult) *MatchQuery {
+	return &MatchQuery{matches: matches}
+}
+
+// with returns a copy of the query with another filter, leaving q's filters unshared
+func (q *MatchQuery) with(filter func(MatchResult) bool) *MatchQuery {
+	next := *q
+	next.filters = append(q.filters[:len(q.filters):len(q.filters)], filter)
+	return &next
+}
+
+// ByTeam keeps the team's matches, home or away, and makes Stats and HomeOnly relative to it
+func (q *MatchQuery) ByTeam(team string) *MatchQuery {
+	next := q.with(func(match MatchResult) bool {
+		return match.HomeTeam == team || match.AwayTeam == team
+	})
+	next.team = team
+	return next
+}
+
+// ByLeague keeps matches from the league
+func (q *MatchQuery) ByLeague(league string) *MatchQuery {
+	return q.with(func(match MatchResult) bool {
+		return match.League == league
+	})
+}
+
+// Against keeps matches with the opponent in them; with ByTeam, the two teams' meetings
+func (q *MatchQuery) Against(opponent string) *MatchQuery {
+	return q.with(func(match MatchResult) bool {
+		return match.HomeTeam == opponent || match.AwayTeam == opponent
+	})
+}
+
+// BySeason keeps matches from the season
+func (q *MatchQuery) BySeason(season string) *MatchQuery {
+	return q.with(func(match MatchResult) bool {
+		return match.Season == season
+	})
+}
+
+// Since keeps matches played on or after date (YYYY-MM-DD)
+func (q *MatchQuery) Since(date string) *MatchQuery {
+	return q.with(func(match MatchResult) bool {
+		return match.Date >= date
+	})
+}
+
+// HomeOnly keeps the ByTeam team's home matches; without ByTeam it drops neutral-venue matches
+func (q *MatchQuery) HomeOnly() *MatchQuery {
+	next := *q
+	next.homeOnly = true
+	return &next
+}
+
+// Limit keeps the n most recent matches after the other filters (0 = no limit)
+func (q *MatchQuery) Limit(n int) *MatchQuery {
+	next := *q
+	next.limit = n
+	return &next
+}
+
+// Matches returns the selected matches as a new slice, oldest first
+func (q *MatchQuery) Matches() []MatchResult {
+	var selected []MatchResult
+	for _, match := range q.matches {
+		if q.keep(match) {
+			selected = append(selected, match)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Date < selected[j].Date
+	})
+	if q.limit > 0 && len(selected) > q.limit {
+		selected = selected[len(selected)-q.limit:]
+	}
+	return selected
+}
+
+// keep reports whether a match passes every filter
+func (q *MatchQuery) keep(match MatchResult) bool {
+	for _, filter := range q.filters {
+		if !filter(match) {
+			return false
+		}
+	}
+	if q.homeOnly {
+		if q.team != "" {
+			return match.HomeTeam == q.team && !match.Neutral
+		}
+		return !match.Neutral
+	}
+	return true
+}
+
+// Count returns the number of selected matches
+func (q *MatchQuery) Count() int {
+	return len(q.Matches())
+}
+
+// Stats aggregates the selected matches
+func (q *MatchQuery) Stats() MatchStats {
+	var stats MatchStats
+	var results strings.Builder
+	for _, match := range q.Matches() {
+		goalsFor, goalsAgainst := match.HomeGoals, match.AwayGoals
+		if q.team != "" && match.AwayTeam == q.team {
+			goalsFor, goalsAgainst = match.AwayGoals, match.HomeGoals
+		}
+		stats.Matches++
+		stats.GoalsFor += goalsFor
+		stats.GoalsAgainst += goalsAgainst
+		switch {
+		case goalsFor > goalsAgainst:
+			stats.Wins++
+			stats.Points += 3
+			results.WriteString("W")
+		case goalsFor == goalsAgainst:
+			stats.Draws++
+			stats.Points++
+			results.WriteString("D")
+		default:
+			stats.Losses++
+			results.WriteString("L")
+		}
+	}
+	if stats.Matches > 0 {
+		stats.PointsPerGame = float64(stats.Points) / float64(stats.Matches)
+	}
+	stats.Results = results.String()
+	return stats
+}
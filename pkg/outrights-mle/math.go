@@ -23,7 +23,25 @@ func PoissonProb(lambda float64, k int) float64 {
 }
 
 // PoissonSample generates a random sample from Poisson distribution
+// Uses the shared math/rand source, which is safe for concurrent use; simulations use their own source
 func PoissonSample(lambda float64) int {
+	return samplePoisson(globalRand{}, lambda)
+}
+
+// randSource is the subset of *rand.Rand used for sampling
+type randSource interface {
+	Float64() float64
+	NormFloat64() float64
+}
+
+// globalRand adapts the top-level math/rand functions to randSource
+type globalRand struct{}
+
+func (globalRand) Float64() float64     { return rand.Float64() }
+func (globalRand) NormFloat64() float64 { return rand.NormFloat64() }
+
+// samplePoisson draws a Poisson sample from the given source
+func samplePoisson(rng randSource, lambda float64) int {
 	if lambda < 0 {
 		return 0
 	}
@@ -36,13 +54,13 @@ func PoissonSample(lambda float64) int {
 		
 		for p > L {
 			k++
-			p *= rand.Float64()
+			p *= rng.Float64()
 		}
 		return k - 1
 	}
 	
 	// Use normal approximation for large lambda
-	return int(math.Max(0, rand.NormFloat64()*math.Sqrt(lambda)+lambda+0.5))
+	return int(math.Max(0, rng.NormFloat64()*math.Sqrt(lambda)+lambda+0.5))
 }
 
 // logFactorial computes log(n!) for Poisson calculations
@@ -56,6 +56,59 @@ func (m *ScoreMatrix) MatchOdds() [3]float64 {
 	return [3]float64{homeWin, draw, awayWin}
 }
 
+// WinningMargins holds the probability of each winning-margin band for one match
+type WinningMargins struct {
+	HomeBy1     float64 `json:"home_by_1"`
+	HomeBy2     float64 `json:"home_by_2"`
+	HomeBy3Plus float64 `json:"home_by_3_plus"`
+	Draw        float64 `json:"draw"`
+	AwayBy1     float64 `json:"away_by_1"`
+	AwayBy2     float64 `json:"away_by_2"`
+	AwayBy3Plus float64 `json:"away_by_3_plus"`
+}
+
+// MarginProbability returns the probability that home goals minus away goals equals margin
+// (negative margins are away wins)
+func (m *ScoreMatrix) MarginProbability(margin int) float64 {
+	total := 0.0
+	for homeGoals := 0; homeGoals <= m.HomeGoals; homeGoals++ {
+		awayGoals := homeGoals - margin
+		if awayGoals >= 0 && awayGoals <= m.AwayGoals {
+			total += m.Matrix[homeGoals][awayGoals]
+		}
+	}
+	return total
+}
+
+// WinningMargins aggregates scorelines into winning-margin bands: win by 1, by 2, by 3 or more, or draw
+func (m *ScoreMatrix) WinningMargins() WinningMargins {
+	var margins WinningMargins
+	for homeGoals := 0; homeGoals <= m.HomeGoals; homeGoals++ {
+		for awayGoals := 0; awayGoals <= m.AwayGoals; awayGoals++ {
+			prob := m.Matrix[homeGoals][awayGoals]
+			
+			switch margin := homeGoals - awayGoals; {
+			case margin >= 3:
+				margins.HomeBy3Plus += prob
+			case margin == 2:
+				margins.HomeBy2 += prob
+			case margin == 1:
+				margins.HomeBy1 += prob
+			case margin == 0:
+				margins.Draw += prob
+			case margin == -1:
+				margins.AwayBy1 += prob
+			case margin == -2:
+				margins.AwayBy2 += prob
+			default:
+				margins.AwayBy3Plus += prob
+			}
+		}
+	}
+	
+	return margins
+}
+
 // OverUnder returns probability of total goals over/under a threshold
 func (m *ScoreMatrix) OverUnder(threshold int) (over, under float64) {
 	for homeGoals := 0; homeGoals <= m.HomeGoals; homeGoals++ {
@@ -0,0 +1,43 @@
+package outrightsmle
+
+import "time"
+
+// MetricsRecorder receives model health metrics from optimization and simulation runs
+// Implementations typically forward to Prometheus counters/histograms; methods must be safe for concurrent use
+type MetricsRecorder interface {
+	// ObserveOptimization is called once per MLE fit
+	ObserveOptimization(iterations int, converged bool, duration time.Duration)
+	// ObserveSimulation is called once per league season simulation
+	ObserveSimulation(league string, paths int, fixtures int, duration time.Duration)
+	// ObserveMarketEvaluation is called once per league mark calculation
+	ObserveMarketEvaluation(league string, markets int, duration time.Duration)
+}
+
+// PathsPerSecond returns simulation throughput for a completed simulation
+func PathsPerSecond(paths int, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(paths) / duration.Seconds()
+}
+
+// observeOptimization forwards to the recorder if one is configured
+func (o MLEOptions) observeOptimization(iterations int, converged bool, duration time.Duration) {
+	if o.Metrics != nil {
+		o.Metrics.ObserveOptimization(iterations, converged, duration)
+	}
+}
+
+// observeSimulation forwards to the recorder if one is configured
+func (o MLEOptions) observeSimulation(league string, paths int, fixtures int, duration time.Duration) {
+	if o.Metrics != nil {
+		o.Metrics.ObserveSimulation(league, paths, fixtures, duration)
+	}
+}
+
+// observeMarketEvaluation forwards to the recorder if one is configured
+func (o MLEOptions) observeMarketEvaluation(league string, markets int, duration time.Duration) {
+	if o.Metrics != nil {
+		o.Metrics.ObserveMarketEvaluation(league, markets, duration)
+	}
+}
@@ -0,0 +1,121 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Odds formats for displaying fair prices
+const (
+	OddsFormatDecimal    = "decimal"    // 2.50
+	OddsFormatFractional = "fractional" // 6/4, from the standard bookmaker ladder
+	OddsFormatAmerican   = "american"   // +150 / -200
+)
+
+// fractionalLadder holds the standard UK fractional prices as numerator/denominator pairs
+var fractionalLadder = [][2]int{
+	{1, 100}, {1, 50}, {1, 33}, {1, 25}, {1, 20}, {1, 16}, {1, 14}, {1, 12}, {1, 10}, {1, 9},
+	{1, 8}, {2, 15}, {1, 7}, {1, 6}, {2, 11}, {1, 5}, {2, 9}, {1, 4}, {2, 7}, {3, 10},
+	{1, 3}, {4, 11}, {2, 5}, {4, 9}, {1, 2}, {8, 15}, {4, 7}, {8, 13}, {4, 6}, {8, 11},
+	{4, 5}, {5, 6}, {10, 11}, {1, 1}, {11, 10}, {6, 5}, {5, 4}, {11, 8}, {6, 4}, {13, 8},
+	{7, 4}, {15, 8}, {2, 1}, {9, 4}, {5, 2}, {11, 4}, {3, 1}, {10, 3}, {7, 2}, {4, 1},
+	{9, 2}, {5, 1}, {11, 2}, {6, 1}, {13, 2}, {7, 1}, {15, 2}, {8, 1}, {17, 2}, {9, 1},
+	{10, 1}, {11, 1}, {12, 1}, {14, 1}, {16, 1}, {18, 1}, {20, 1}, {25, 1}, {33, 1}, {40, 1},
+	{50, 1}, {66, 1}, {80, 1}, {100, 1}, {125, 1}, {150, 1}, {200, 1}, {250, 1}, {500, 1}, {1000, 1},
+}
+
+// DecimalToFractional returns the nearest standard fractional price to decimal odds, e.g. "5/2"
+// for 3.5; "evs" for 2.0 and "" for odds of 1.0 or less (a certainty has no fractional price)
+// Nearness is measured on the log of the profit per unit, so short and long prices round alike
+func DecimalToFractional(decimal float64) string {
+	if decimal <= 1 {
+		return ""
+	}
+	target := math.Log(decimal - 1)
+	ratio := func(i int) float64 {
+		return float64(fractionalLadder[i][0]) / float64(fractionalLadder[i][1])
+	}
+	i := sort.Search(len(fractionalLadder), func(i int) bool { return ratio(i) >= decimal-1 })
+	if i == len(fractionalLadder) {
+		i--
+	} else if i > 0 && target-math.Log(ratio(i-1)) < math.Log(ratio(i))-target {
+		i--
+	}
+	if fractionalLadder[i] == [2]int{1, 1} {
+		return "evs"
+	}
+	return fmt.Sprintf("%d/%d", fractionalLadder[i][0], fractionalLadder[i][1])
+}
+
+// DecimalToAmerican returns moneyline odds: +150 for 2.5, -200 for 1.5 (rounded to the nearest
+// unit); 0 for odds of 1.0 or less
+func DecimalToAmerican(decimal float64) int {
+	switch {
+	case decimal <= 1:
+		return 0
+	case decimal >= 2:
+		return int(math.Round((decimal - 1) * 100))
+	default:
+		return -int(math.Round(100 / (decimal - 1)))
+	}
+}
+
+// FormatOdds renders decimal odds in the given format; "-" stands for a certainty in the
+// fractional and American formats
+func FormatOdds(decimal float64, format string) (string, error) {
+	switch format {
+	case OddsFormatDecimal, "":
+		return fmt.Sprintf("%.2f", decimal), nil
+	case OddsFormatFractional:
+		if decimal <= 1 {
+			return "-", nil
+		}
+		return DecimalToFractional(decimal), nil
+	case OddsFormatAmerican:
+		if decimal <= 1 {
+			return "-", nil
+		}
+		return fmt.Sprintf("%+d", DecimalToAmerican(decimal)), nil
+	}
+	return "", fmt.Errorf("unknown odds format %q (expected %s, %s or %s)", format, OddsFormatDecimal, OddsFormatFractional, OddsFormatAmerican)
+}
+
+// defaultOddsProbabilityFloor caps fair prices at 1000.0 when SimParams leaves the floor unset
+const defaultOddsProbabilityFloor = 0.001
+
+// decimalOdds converts a probability to fair decimal odds (1/p), raising it to floor first so
+// near-impossible outcomes still get a finite price
+func decimalOdds(probability, floor float64) float64 {
+	if floor <= 0 {
+		floor = defaultOddsProbabilityFloor
+	}
+	if probability < floor {
+		probability = floor
+	}
+	return 1.0 / probability
+}
+
+// matchDecimalOdds returns fair [home, draw, away] odds, or nil unless SimParams.DecimalOdds is set
+func matchDecimalOdds(probabilities [3]float64, simParams *SimParams) []float64 {
+	if simParams == nil || !simParams.DecimalOdds {
+		return nil
+	}
+	odds := make([]float64, len(probabilities))
+	for i, probability := range probabilities {
+		odds[i] = decimalOdds(probability, simParams.OddsProbabilityFloor)
+	}
+	return odds
+}
+
+// markDecimalOdds converts market -> team mark values to fair decimal odds
+func markDecimalOdds(values map[string]map[string]float64, floor float64) map[string]map[string]float64 {
+	odds := make(map[string]map[string]float64, len(values))
+	for market, teamMarks := range values {
+		odds[market] = make(map[string]float64, len(teamMarks))
+		for team, mark := range teamMarks {
+			odds[market][team] = decimalOdds(mark, floor)
+		}
+	}
+	return odds
+}
@@ -0,0 +1,88 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RatingOverride pins or offsets a team's fitted ratings, e.g. for a club whose takeover the
+// results do not yet show. A pinned side ignores its offset, so setting both is an error
+type RatingOverride struct {
+	Attack        *float64 `json:"attack,omitempty"`         // Pin attack to this value
+	Defense       *float64 `json:"defense,omitempty"`        // Pin defense to this value
+	AttackOffset  float64  `json:"attack_offset,omitempty"`  // Add to the fitted attack
+	DefenseOffset float64  `json:"defense_offset,omitempty"` // Add to the fitted defense
+	Reason        string   `json:"reason,omitempty"`         // Why the override was made, for the audit trail
+}
+
+// AppliedRatingOverride records one override as applied, with the ratings it replaced
+type AppliedRatingOverride struct {
+	Team          string  `json:"team"`
+	Reason        string  `json:"reason,omitempty"`
+	FittedAttack  float64 `json:"fitted_attack"`  // Before the override (after any RatingsBlend)
+	FittedDefense float64 `json:"fitted_defense"` // Before the override (after any RatingsBlend)
+	Attack        float64 `json:"attack"`         // After the override
+	Defense       float64 `json:"defense"`        // After the override
+}
+
+// validateRatingOverrides checks overrides name known teams and do not both pin and offset a side
+func validateRatingOverrides(overrides map[string]RatingOverride, teams []string) error {
+	for _, team := range sortedKeys(overrides) {
+		override := overrides[team]
+		if override.Attack != nil && override.AttackOffset != 0 {
+			return fmt.Errorf("rating override for %s both pins and offsets attack", team)
+		}
+		if override.Defense != nil && override.DefenseOffset != 0 {
+			return fmt.Errorf("rating override for %s both pins and offsets defense", team)
+		}
+		if !containsString(teams, team) {
+			return &UnknownTeamError{Team: team, Context: "rating overrides"}
+		}
+	}
+	return nil
+}
+
+// applyRatingOverrides returns a copy of params with the overrides applied and recorded in
+// RatingOverrides, ordered by team. Teams params do not rate are skipped
+func applyRatingOverrides(params *MLEParams, overrides map[string]RatingOverride) *MLEParams {
+	if len(overrides) == 0 {
+		return params
+	}
+
+	overridden := *params
+	overridden.AttackRatings = copyMap(params.AttackRatings)
+	overridden.DefenseRatings = copyMap(params.DefenseRatings)
+	overridden.RatingOverrides = append([]AppliedRatingOverride(nil), params.RatingOverrides...)
+	for _, team := range sortedKeys(overrides) {
+		attack, exists := overridden.AttackRatings[team]
+		if !exists {
+			continue
+		}
+		defense := overridden.DefenseRatings[team]
+		override := overrides[team]
+		applied := AppliedRatingOverride{Team: team, Reason: override.Reason, FittedAttack: attack, FittedDefense: defense}
+
+		if override.Attack != nil {
+			attack = *override.Attack
+		} else {
+			attack += override.AttackOffset
+		}
+		if override.Defense != nil {
+			defense = *override.Defense
+		} else {
+			defense += override.DefenseOffset
+		}
+		overridden.AttackRatings[team], overridden.DefenseRatings[team] = attack, defense
+		applied.Attack, applied.Defense = attack, defense
+		overridden.RatingOverrides = append(overridden.RatingOverrides, applied)
+	}
+	sortRatingOverrides(overridden.RatingOverrides)
+	return &overridden
+}
+
+// sortRatingOverrides orders applied overrides by team
+func sortRatingOverrides(applied []AppliedRatingOverride) {
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].Team < applied[j].Team
+	})
+}
@@ -0,0 +1,81 @@
+package outrightsmle
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// PathSample holds the final points and finishing positions of a league's teams on a sample of
+// simulation paths, for correlation analysis and custom markets built outside the package
+type PathSample struct {
+	League    string   `json:"league"`
+	Teams     []string `json:"teams"`     // Column order of Points and Positions, by name
+	Paths     []int    `json:"paths"`     // Simulation path of each row
+	Points    [][]int  `json:"points"`    // Row -> team -> final points, handicaps included
+	Positions [][]int  `json:"positions"` // Row -> team -> finishing position (1 = top)
+}
+
+// SamplePaths returns the first n simulation paths of league from the retained simulation, or
+// every path when n is 0 or at least the path count. Paths are independent draws, so the first n
+// are a random sample. Positions follow the league's tiebreak chain; with dead-heating, teams
+// level on every tiebreak share the highest position of their group
+func SamplePaths(result *MultiLeagueResult, league string, n int) (*PathSample, error) {
+	if result == nil {
+		return nil, fmt.Errorf("result is required")
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("path sample size must not be negative, got %d", n)
+	}
+	simPoints, err := result.simulation(league)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 || n > simPoints.NPaths {
+		n = simPoints.NPaths
+	}
+
+	teams := append([]string(nil), simPoints.TeamNames...)
+	sort.Strings(teams)
+	selectedIndices := simPoints.selectTeams(teams)
+
+	sample := &PathSample{
+		League:    league,
+		Teams:     teams,
+		Paths:     make([]int, n),
+		Points:    make([][]int, n),
+		Positions: make([][]int, n),
+	}
+	for path := 0; path < n; path++ {
+		points := make([]int, len(selectedIndices))
+		positions := make([]int, len(selectedIndices))
+		for column, idx := range selectedIndices {
+			points[column] = simPoints.Points(idx, path)
+		}
+		simPoints.pathPositions(selectedIndices, path, positions)
+		sample.Paths[path] = path
+		sample.Points[path] = points
+		sample.Positions[path] = positions
+	}
+	return sample, nil
+}
+
+// WriteCSV writes the sample as path,team,points,position rows
+func (s *PathSample) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"path", "team", "points", "position"}); err != nil {
+		return err
+	}
+	for row, path := range s.Paths {
+		for column, team := range s.Teams {
+			record := []string{strconv.Itoa(path), team, strconv.Itoa(s.Points[row][column]), strconv.Itoa(s.Positions[row][column])}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
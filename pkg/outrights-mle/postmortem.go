@@ -0,0 +1,406 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Checkpoint is a date in a completed season at which the model's predictions are rebuilt for a
+// postmortem, e.g. {"christmas", "2023-12-25"}. An empty date is the day before the season's
+// first league match
+type Checkpoint struct {
+	Name string `json:"name"`
+	Date string `json:"date,omitempty"` // As-of date (YYYY-MM-DD, inclusive)
+}
+
+// PostmortemOptions configures a season postmortem
+type PostmortemOptions struct {
+	Season      string       `json:"season"`                // Completed season to score
+	Leagues     []string     `json:"leagues,omitempty"`     // Leagues to score (default: every league in the season)
+	Checkpoints []Checkpoint `json:"checkpoints,omitempty"` // Sorted by date before running (default: pre-season only)
+}
+
+// TeamScore compares one team's prediction at a checkpoint with how its season ended
+type TeamScore struct {
+	Team                string  `json:"team"`
+	ActualPosition      int     `json:"actual_position"`
+	ActualPoints        int     `json:"actual_points"`
+	ExpectedPosition    float64 `json:"expected_position"`
+	ExpectedPoints      float64 `json:"expected_points"`
+	PositionProbability float64 `json:"position_probability"` // Predicted probability of the actual position
+}
+
+// MarketScore is the Brier score of a market's marks against the payoffs the final table paid
+type MarketScore struct {
+	Teams int     `json:"teams"`
+	Brier float64 `json:"brier"` // Mean squared error of mark against realized payoff
+}
+
+// LeagueScore is the report card for one league at one checkpoint. Lower is better for every
+// score except RankCorrelation and ChampionProbability
+type LeagueScore struct {
+	LogLoss                float64                `json:"log_loss"`                 // Mean −log P(actual position)
+	RankedProbabilityScore float64                `json:"ranked_probability_score"` // Mean over teams of the position RPS
+	PointsMAE              float64                `json:"points_mae"`               // Mean |expected − actual points|
+	PositionMAE            float64                `json:"position_mae"`             // Mean |expected − actual position|
+	RankCorrelation        float64                `json:"rank_correlation"`         // Spearman, expected against actual position
+	Champion               string                 `json:"champion"`
+	ChampionProbability    float64                `json:"champion_probability"` // Predicted probability that the champion finished first
+	Teams                  []TeamScore            `json:"teams"`                // By actual position
+	Markets                map[string]MarketScore `json:"markets,omitempty"`
+}
+
+// CheckpointReport holds every league's report card at one checkpoint
+type CheckpointReport struct {
+	Name    string                 `json:"name"`
+	Date    string                 `json:"date"`
+	Leagues map[string]LeagueScore `json:"leagues"`
+}
+
+// PostmortemReport scores what the model predicted at each checkpoint of a completed season
+// against the final tables
+type PostmortemReport struct {
+	Season      string             `json:"season"`
+	Checkpoints []CheckpointReport `json:"checkpoints"`
+}
+
+// RunPostmortem replays a completed season: at each checkpoint it runs RunMLESolver on the results
+// known by that date (options.AsOfDate is replaced by the checkpoint's) and scores the predicted
+// finishing positions, points and the scored leagues' marks against the season's final tables.
+// A pre-season checkpoint simulates the whole season for the teams that went on to play it, so
+// each of them needs results in an earlier season; a later checkpoint needs every team to have
+// played a league match by then
+func RunPostmortem(events []MatchResult, markets []Market, options MLEOptions, handicaps map[string]int, postmortem PostmortemOptions) (*PostmortemReport, error) {
+	if postmortem.Season == "" {
+		return nil, fmt.Errorf("a season is required")
+	}
+	if options.RatingsOnly {
+		return nil, fmt.Errorf("invalid request: a postmortem scores the season simulation, which RatingsOnly skips")
+	}
+	renamed, err := ApplyTeamRenames(events, options.TeamRenames)
+	if err != nil {
+		return nil, err
+	}
+
+	// The season's league matches give the final tables and the teams at each checkpoint
+	seasonEvents := make(map[string][]MatchResult)
+	for _, match := range renamed {
+		if match.Season != postmortem.Season || match.Competition != "" {
+			continue
+		}
+		if len(postmortem.Leagues) == 0 || containsString(postmortem.Leagues, match.League) {
+			seasonEvents[match.League] = append(seasonEvents[match.League], match)
+		}
+	}
+	if len(seasonEvents) == 0 {
+		return nil, fmt.Errorf("no league matches in season %s", postmortem.Season)
+	}
+	for _, league := range postmortem.Leagues {
+		if len(seasonEvents[league]) == 0 {
+			return nil, fmt.Errorf("no %s matches in season %s", league, postmortem.Season)
+		}
+	}
+	leagues := sortedKeys(seasonEvents)
+	seasonTeams := make(map[string][]string, len(leagues))
+	firstDate, lastDate := "", ""
+	for _, league := range leagues {
+		seasonTeams[league] = sortedKeys(GetTeamsInSeason(seasonEvents[league], postmortem.Season))
+		for _, match := range seasonEvents[league] {
+			if firstDate == "" || match.Date < firstDate {
+				firstDate = match.Date
+			}
+			lastDate = max(lastDate, match.Date)
+		}
+	}
+
+	// Only the scored leagues' markets are priced
+	var leagueMarkets []Market
+	for _, market := range markets {
+		if containsString(leagues, market.League) {
+			leagueMarkets = append(leagueMarkets, market)
+		}
+	}
+
+	checkpoints, err := resolveCheckpoints(postmortem.Checkpoints, firstDate, lastDate)
+	if err != nil {
+		return nil, err
+	}
+
+	simParams := options.SimParams
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	finalTables := make(map[string][]Team, len(leagues))
+	for _, league := range leagues {
+		finalTables[league] = calcLeagueTable(seasonTeams[league], convertMatchResultsToEvents(seasonEvents[league], postmortem.Season),
+			handicaps, leagueTiebreaks(simParams.Tiebreaks, league))
+	}
+
+	report := &PostmortemReport{Season: postmortem.Season, Checkpoints: make([]CheckpointReport, 0, len(checkpoints))}
+	for _, checkpoint := range checkpoints {
+		checkpointOptions := options
+		checkpointOptions.AsOfDate = checkpoint.Date
+
+		// Before the season's first match the tables are empty, so the teams come from the season
+		// itself; afterwards they come from the season's matches so far, which must include them all
+		var leagueGroups map[string][]string
+		if checkpoint.Date < firstDate {
+			if err := checkTeamsRated(renamed, seasonTeams, checkpoint); err != nil {
+				return nil, err
+			}
+			leagueGroups = seasonTeams
+		} else if err := checkTeamsPlayed(seasonEvents, seasonTeams, checkpoint); err != nil {
+			return nil, err
+		}
+
+		result, err := RunMLESolver(events, leagueMarkets, checkpointOptions, handicaps, leagueGroups)
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint %s (%s): %w", checkpoint.Name, checkpoint.Date, err)
+		}
+		checkpointReport := CheckpointReport{Name: checkpoint.Name, Date: checkpoint.Date, Leagues: make(map[string]LeagueScore, len(leagues))}
+		for _, league := range leagues {
+			checkpointReport.Leagues[league] = scoreLeague(result, league, finalTables[league])
+		}
+		result.ReleaseSimulations()
+		report.Checkpoints = append(report.Checkpoints, checkpointReport)
+	}
+	return report, nil
+}
+
+// resolveCheckpoints fills in pre-season dates and sorts the checkpoints by date, checking each
+// falls before the season's last match
+func resolveCheckpoints(checkpoints []Checkpoint, firstDate, lastDate string) ([]Checkpoint, error) {
+	if len(checkpoints) == 0 {
+		checkpoints = []Checkpoint{{Name: "pre-season"}}
+	}
+	first, err := time.Parse("2006-01-02", firstDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match date %q: %w", firstDate, err)
+	}
+	preSeason := first.AddDate(0, 0, -1).Format("2006-01-02")
+
+	resolved := make([]Checkpoint, len(checkpoints))
+	for i, checkpoint := range checkpoints {
+		if checkpoint.Date == "" {
+			checkpoint.Date = preSeason
+		}
+		if _, err := time.Parse("2006-01-02", checkpoint.Date); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint %s date %q: expected YYYY-MM-DD", checkpoint.Name, checkpoint.Date)
+		}
+		if checkpoint.Date >= lastDate {
+			return nil, fmt.Errorf("checkpoint %s (%s) is not before the season's last match on %s", checkpoint.Name, checkpoint.Date, lastDate)
+		}
+		resolved[i] = checkpoint
+	}
+	sort.SliceStable(resolved, func(i, j int) bool {
+		return resolved[i].Date < resolved[j].Date
+	})
+	return resolved, nil
+}
+
+// checkTeamsRated checks every team of the season has a result by a pre-season checkpoint, in any
+// league or cup, to rate it from
+func checkTeamsRated(events []MatchResult, seasonTeams map[string][]string, checkpoint Checkpoint) error {
+	rated := make(map[string]bool)
+	for _, match := range events {
+		if match.Date <= checkpoint.Date {
+			rated[match.HomeTeam], rated[match.AwayTeam] = true, true
+		}
+	}
+	for _, league := range sortedKeys(seasonTeams) {
+		var unrated []string
+		for _, team := range seasonTeams[league] {
+			if !rated[team] {
+				unrated = append(unrated, team)
+			}
+		}
+		if len(unrated) > 0 {
+			return fmt.Errorf("checkpoint %s (%s): %s has no earlier results for %s; leave the league out or use a later date", checkpoint.Name, checkpoint.Date, league, strings.Join(unrated, ", "))
+		}
+	}
+	return nil
+}
+
+// checkTeamsPlayed checks every team of the season has played a league match by the checkpoint,
+// since the run takes a league's teams from its matches so far
+func checkTeamsPlayed(seasonEvents map[string][]MatchResult, seasonTeams map[string][]string, checkpoint Checkpoint) error {
+	for _, league := range sortedKeys(seasonTeams) {
+		played := make(map[string]bool)
+		for _, match := range seasonEvents[league] {
+			if match.Date <= checkpoint.Date {
+				played[match.HomeTeam], played[match.AwayTeam] = true, true
+			}
+		}
+		for _, team := range seasonTeams[league] {
+			if !played[team] {
+				return fmt.Errorf("checkpoint %s (%s): %s has not played in %s yet; use a pre-season checkpoint or a later date", checkpoint.Name, checkpoint.Date, team, league)
+			}
+		}
+	}
+	return nil
+}
+
+// scoreLeague scores league's predictions in result against its final table. Log loss floors a
+// probability at half a simulation path, so a position no path reached does not dominate the mean
+func scoreLeague(result *MultiLeagueResult, league string, finalTable []Team) LeagueScore {
+	score := LeagueScore{Teams: make([]TeamScore, 0, len(finalTable))}
+	simPoints := result.Simulations[league]
+	probabilities := simPoints.positionProbabilities(nil)
+	predicted := make(map[string]Team)
+	for _, team := range result.Leagues[league] {
+		predicted[team.Name] = team
+	}
+	floor := 0.5 / float64(simPoints.NPaths)
+	nTeams := float64(len(finalTable))
+
+	expectedPositions := make([]float64, len(finalTable))
+	for i, actual := range finalTable {
+		teamProbs := probabilities[actual.Name]
+		teamScore := TeamScore{
+			Team:                actual.Name,
+			ActualPosition:      i + 1,
+			ActualPoints:        actual.Points,
+			ExpectedPosition:    predicted[actual.Name].ExpectedPosition,
+			ExpectedPoints:      predicted[actual.Name].ExpectedSeasonPoints,
+			PositionProbability: teamProbs[i],
+		}
+		score.Teams = append(score.Teams, teamScore)
+		expectedPositions[i] = teamScore.ExpectedPosition
+
+		score.LogLoss -= math.Log(math.Max(teamScore.PositionProbability, floor)) / nTeams
+		score.RankedProbabilityScore += rankedProbabilityScore(teamProbs, i) / nTeams
+		score.PointsMAE += math.Abs(teamScore.ExpectedPoints-float64(teamScore.ActualPoints)) / nTeams
+		score.PositionMAE += math.Abs(teamScore.ExpectedPosition-float64(teamScore.ActualPosition)) / nTeams
+	}
+	if len(finalTable) > 0 {
+		score.Champion = finalTable[0].Name
+		score.ChampionProbability = probabilities[score.Champion][0]
+	}
+	score.RankCorrelation = rankCorrelation(expectedPositions)
+	score.Markets = scoreMarkets(result, league, finalTable)
+	return score
+}
+
+// rankedProbabilityScore returns the ranked probability score of position probabilities against
+// the actual position (0-based): the mean squared gap between the predicted and actual cumulative
+// distributions, 0 for certainty of the right position and 1 for certainty of the furthest one
+func rankedProbabilityScore(probabilities []float64, actual int) float64 {
+	if len(probabilities) < 2 {
+		return 0
+	}
+	total, cumulative := 0.0, 0.0
+	for position := 0; position < len(probabilities)-1; position++ {
+		cumulative += probabilities[position]
+		observed := 0.0
+		if position >= actual {
+			observed = 1
+		}
+		total += (cumulative - observed) * (cumulative - observed)
+	}
+	return total / float64(len(probabilities)-1)
+}
+
+// rankCorrelation returns the Spearman correlation between predicted values, listed in actual
+// finishing order, and that order. Tied predictions share their mean rank
+func rankCorrelation(predicted []float64) float64 {
+	n := len(predicted)
+	if n < 2 {
+		return 0
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return predicted[order[i]] < predicted[order[j]]
+	})
+	ranks := make([]float64, n)
+	for start := 0; start < n; {
+		end := start + 1
+		for end < n && predicted[order[end]] == predicted[order[start]] {
+			end++
+		}
+		for _, idx := range order[start:end] {
+			ranks[idx] = float64(start+end+1) / 2
+		}
+		start = end
+	}
+
+	mean := float64(n+1) / 2
+	covariance, predictedVariance, actualVariance := 0.0, 0.0, 0.0
+	for i, rank := range ranks {
+		actual := float64(i+1) - mean
+		covariance += (rank - mean) * actual
+		predictedVariance += (rank - mean) * (rank - mean)
+		actualVariance += actual * actual
+	}
+	if predictedVariance == 0 {
+		return 0
+	}
+	return covariance / math.Sqrt(predictedVariance*actualVariance)
+}
+
+// scoreMarkets scores league's marks against what the final table paid: a position market's
+// payoff by each team's place among the market's teams, and a line market's 1 for over and 0 for
+// under
+func scoreMarkets(result *MultiLeagueResult, league string, finalTable []Team) map[string]MarketScore {
+	scores := make(map[string]MarketScore)
+	for _, market := range result.Markets {
+		marks := result.MarkValues[league][market.Name]
+		if market.League != league || len(marks) == 0 {
+			continue
+		}
+
+		payoffs := make(map[string]float64)
+		switch market.Type {
+		case MarketTypePointsLine, MarketTypeGoalsLine:
+			for _, team := range finalTable {
+				line, exists := market.Lines[team.Name]
+				if !exists {
+					continue
+				}
+				total := team.Points
+				if market.Type == MarketTypeGoalsLine {
+					total = team.GoalsFor
+				}
+				if float64(total) > line {
+					payoffs[team.Name] = 1
+				} else {
+					payoffs[team.Name] = 0
+				}
+			}
+		default:
+			payoffParts := parsePayoffStructure(market.Payoff)
+			place := 0
+			for _, team := range finalTable {
+				if !containsString(market.Teams, team.Name) {
+					continue
+				}
+				payoffs[team.Name] = 0
+				if place < len(payoffParts) {
+					payoffs[team.Name] = payoffParts[place]
+				}
+				place++
+			}
+		}
+
+		score := MarketScore{}
+		for _, team := range sortedKeys(payoffs) {
+			mark, exists := marks[team]
+			payoff := payoffs[team]
+			if !exists {
+				continue
+			}
+			score.Brier += (mark - payoff) * (mark - payoff)
+			score.Teams++
+		}
+		if score.Teams > 0 {
+			score.Brier /= float64(score.Teams)
+			scores[market.Name] = score
+		}
+	}
+	return scores
+}
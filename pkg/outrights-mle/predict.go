@@ -0,0 +1,55 @@
+package outrightsmle
+
+import "fmt"
+
+// PredictSeason builds tables and runs season simulations and marks from previously fitted params,
+// skipping the fit: the serving half of a train/serve split, with OptimizeRatings as the training
+// half. events need only hold the current season's results, for the tables and remaining
+// fixtures; leagueGroups and handicaps are optional as for RunMLESolver. Overrides and
+// multipliers in options apply on top of the saved ratings. A current team the params do not
+// rate is an ErrUnknownTeam error
+func PredictSeason(params MLEParams, events []MatchResult, leagueGroups map[string][]string, markets []Market, options MLEOptions, handicaps map[string]int) (*MultiLeagueResult, error) {
+	run, err := prepareLeagueRun(events, markets, options, handicaps, leagueGroups)
+	if err != nil {
+		return nil, err
+	}
+	return run.predict(params, "saved params")
+}
+
+// suppliedParams returns params carrying the supplied ratings, with home advantage, rho and any
+// count model shape from simParams, for RunMLESolver to use in place of a fit
+func suppliedParams(ratings *RatingSet, simParams *SimParams) (MLEParams, error) {
+	if err := ratings.validateTeams("supplied ratings"); err != nil {
+		return MLEParams{}, fmt.Errorf("invalid request: %w", err)
+	}
+	params := MLEParams{
+		HomeAdvantage:  simParams.HomeAdvantage,
+		Rho:            simParams.Rho,
+		AttackRatings:  copyMap(ratings.AttackRatings),
+		DefenseRatings: copyMap(ratings.DefenseRatings),
+	}
+	params.CountModel, params.CountShape = configuredCountModel(simParams)
+	return params, nil
+}
+
+// predict simulates the run's leagues from params instead of a fit; context names the params'
+// origin in unknown team errors
+func (run *leagueRun) predict(params MLEParams, context string) (*MultiLeagueResult, error) {
+	adjusted := applyLambdaMultipliers(applyRatingOverrides(&params, run.options.RatingOverrides), run.options.LambdaMultipliers)
+	for _, league := range run.leagues {
+		for _, team := range run.currentTeams[league] {
+			if _, exists := adjusted.AttackRatings[team]; !exists {
+				return nil, &UnknownTeamError{Team: team, League: league, Context: context}
+			}
+		}
+	}
+	run.result.RatingOverrides = adjusted.RatingOverrides
+	run.result.Fingerprint.Params = jsonFingerprint(params)
+	if run.options.IncludeMatchOdds {
+		solver := &MLESolver{params: adjusted, options: run.options}
+		request := MLERequest{HistoricalData: run.events, LeagueGroups: run.leagueGroups}
+		run.result.MatchOdds = matchOddsByLeague(generateFixturesPerLeague(teamsFromParams(adjusted), solver, request))
+	}
+
+	return run.simulate(teamsFromParams(adjusted), *adjusted, nil)
+}
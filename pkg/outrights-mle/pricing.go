@@ -0,0 +1,205 @@
+package outrightsmle
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// OptimizeRatings fits team ratings from historical match data without any season simulation
+// Pure computation on caller-supplied data (no filesystem or global state), suitable for WASM builds
+func OptimizeRatings(request MLERequest) (*MLEParams, error) {
+	matches, err := ingestMatches(request.HistoricalData, request.Options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	request.HistoricalData = matches
+
+	if err := validateRequest(request); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// Apply defaults if not provided
+	options := request.Options
+	if options.SimParams == nil {
+		options.SimParams = DefaultSimParams()
+	}
+
+	solver := NewMLESolver(request.HistoricalData, options, request.LeagueChangeTeams)
+	params, err := solver.Optimize()
+	if err != nil {
+		return nil, fmt.Errorf("MLE optimization failed: %w", err)
+	}
+	if err := checkConvergence(params, options); err != nil {
+		return nil, err
+	}
+	if options.RatingsBlend != nil {
+		params, err = options.RatingsBlend.apply(params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+	}
+
+	return applyLambdaMultipliers(applyRatingOverrides(params, options.RatingOverrides), options.LambdaMultipliers), nil
+}
+
+// PriceFixtures calculates 1X2 and winning-margin probabilities for "{Home} vs {Away}" fixtures from fitted parameters
+// Uses DefaultSimParams if simParams is nil
+func PriceFixtures(params MLEParams, simParams *SimParams, fixtures []string, league string) ([]MatchOdds, error) {
+	return priceFixtures(params, simParams, fixtures, league, false)
+}
+
+// PriceNeutralFixtures prices fixtures played at a neutral venue, e.g. cup finals (no home advantage)
+func PriceNeutralFixtures(params MLEParams, simParams *SimParams, fixtures []string, league string) ([]MatchOdds, error) {
+	return priceFixtures(params, simParams, fixtures, league, true)
+}
+
+// MatchProbabilities returns [home, draw, away] probabilities for homeTeam against awayTeam from
+// fitted parameters alone, e.g. ones loaded from JSON, without a solver or historical data
+// Uses DefaultSimParams if simParams is nil
+func (p MLEParams) MatchProbabilities(simParams *SimParams, homeTeam, awayTeam string) ([3]float64, error) {
+	return p.matchProbabilities(simParams, homeTeam, awayTeam, p.HomeAdvantage)
+}
+
+// NeutralMatchProbabilities is MatchProbabilities at a neutral venue (no home advantage)
+func (p MLEParams) NeutralMatchProbabilities(simParams *SimParams, homeTeam, awayTeam string) ([3]float64, error) {
+	return p.matchProbabilities(simParams, homeTeam, awayTeam, 0)
+}
+
+// matchProbabilities prices one match with the given home advantage
+func (p MLEParams) matchProbabilities(simParams *SimParams, homeTeam, awayTeam string, homeAdvantage float64) ([3]float64, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	for _, team := range []string{homeTeam, awayTeam} {
+		if _, exists := p.AttackRatings[team]; !exists {
+			return [3]float64{}, &UnknownTeamError{Team: team, Context: fmt.Sprintf("match %s vs %s", homeTeam, awayTeam)}
+		}
+	}
+
+	// No league is given, so league intercepts, if any, are split into each team's ratings
+	params := p.forLeague("")
+	solver := &MLESolver{
+		params:  &params,
+		options: MLEOptions{SimParams: simParams},
+	}
+	return solver.calculateMatchProbabilities(homeTeam, awayTeam, homeAdvantage), nil
+}
+
+// priceFixtures prices fixtures with or without the home advantage term
+func priceFixtures(params MLEParams, simParams *SimParams, fixtures []string, league string, neutral bool) ([]MatchOdds, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	if err := validateMargins(simParams); err != nil {
+		return nil, fmt.Errorf("invalid margins: %w", err)
+	}
+	params = params.forLeague(league)
+
+	solver := &MLESolver{
+		params:  &params,
+		options: MLEOptions{SimParams: simParams},
+	}
+
+	homeAdvantage := params.HomeAdvantage
+	if neutral {
+		homeAdvantage = 0
+	}
+
+	matchOdds := make([]MatchOdds, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		homeTeam, awayTeam, err := fixtureTeams(params, fixture)
+		if err != nil {
+			return nil, err
+		}
+
+		scoreMatrix := solver.scoreMatrix(homeTeam, awayTeam, homeAdvantage)
+		probabilities := scoreMatrix.MatchOdds()
+		lambdaHome, lambdaAway := solver.fixtureLambdas(homeTeam, awayTeam, homeAdvantage, 0)
+		margins := countWinningMargins(solver.goalModel(), lambdaHome, lambdaAway, params.Rho, simParams.GoalSimulationBound)
+		matchOdds = append(matchOdds, MatchOdds{
+			Fixture:       fixture,
+			League:        league,
+			Probabilities: probabilities,
+			Neutral:       neutral,
+			DecimalOdds:   matchDecimalOdds(probabilities, simParams),
+			BookOdds:      matchBookOdds(probabilities, simParams),
+			Margins:       &margins,
+		})
+	}
+
+	return matchOdds, nil
+}
+
+// fixtureTeams splits a "{Home} vs {Away}" fixture and checks both teams have ratings
+func fixtureTeams(params MLEParams, fixture string) (string, string, error) {
+	homeTeam, awayTeam := parseEventName(fixture)
+	if homeTeam == "" || awayTeam == "" {
+		return "", "", fmt.Errorf("invalid fixture %q: expected \"{Home} vs {Away}\"", fixture)
+	}
+	for _, team := range []string{homeTeam, awayTeam} {
+		if _, exists := params.AttackRatings[team]; !exists {
+			return "", "", &UnknownTeamError{Team: team, Context: fmt.Sprintf("fixture %q", fixture)}
+		}
+	}
+	return homeTeam, awayTeam, nil
+}
+
+// ScoreGrid is the full correct-score probability grid for one fixture
+type ScoreGrid struct {
+	Fixture       string      `json:"fixture"`
+	League        string      `json:"league"`
+	Neutral       bool        `json:"neutral,omitempty"`
+	MaxGoals      int         `json:"max_goals"`     // Largest score per side in the grid (SimParams.GoalSimulationBound)
+	Probabilities [][]float64 `json:"probabilities"` // [home_goals][away_goals] -> probability
+}
+
+// PriceScoreGrid returns the Dixon-Coles correct-score grid for a "{Home} vs {Away}" fixture, for
+// deriving exotic markets; neutral leaves out home advantage. Uses DefaultSimParams if simParams is nil
+func PriceScoreGrid(params MLEParams, simParams *SimParams, fixture, league string, neutral bool) (*ScoreGrid, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	homeTeam, awayTeam, err := fixtureTeams(params, fixture)
+	if err != nil {
+		return nil, err
+	}
+	params = params.forLeague(league)
+
+	solver := &MLESolver{
+		params:  &params,
+		options: MLEOptions{SimParams: simParams},
+	}
+	homeAdvantage := params.HomeAdvantage
+	if neutral {
+		homeAdvantage = 0
+	}
+	scoreMatrix := solver.scoreMatrix(homeTeam, awayTeam, homeAdvantage)
+
+	return &ScoreGrid{
+		Fixture:       fixture,
+		League:        league,
+		Neutral:       neutral,
+		MaxGoals:      scoreMatrix.HomeGoals,
+		Probabilities: scoreMatrix.Matrix,
+	}, nil
+}
+
+// WriteCSV writes the grid as home_goals,away_goals,probability rows
+func (g *ScoreGrid) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"home_goals", "away_goals", "probability"}); err != nil {
+		return err
+	}
+	for homeGoals, row := range g.Probabilities {
+		for awayGoals, probability := range row {
+			record := []string{strconv.Itoa(homeGoals), strconv.Itoa(awayGoals), strconv.FormatFloat(probability, 'g', -1, 64)}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,115 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// findEntryTeams returns teams whose first match is in the latest season, mapped to the league
+// they entered; these have no history to learn from, so zero (average) is a poor starting rating
+func findEntryTeams(matches []MatchResult, latestSeason string) map[string]string {
+	earliestSeason := latestSeason
+	firstSeason := make(map[string]string)
+	firstLeague := make(map[string]string)
+	for _, match := range matches {
+		if match.Competition != "" {
+			continue // Cup matches do not say which league a team entered
+		}
+		if match.Season < earliestSeason {
+			earliestSeason = match.Season
+		}
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if season, exists := firstSeason[team]; !exists || match.Season < season {
+				firstSeason[team] = match.Season
+				firstLeague[team] = match.League
+			}
+		}
+	}
+
+	entryTeams := make(map[string]string)
+	if earliestSeason == latestSeason {
+		return entryTeams // Single season of data: every team is new
+	}
+	for team, season := range firstSeason {
+		if season == latestSeason {
+			entryTeams[team] = firstLeague[team]
+		}
+	}
+	return entryTeams
+}
+
+// entryPriorTargets returns each entry team's attack and defense at the SimParams.EntryPriorQuantile
+// quantile of the current ratings of the established teams in the league it entered; nil when
+// the prior is disabled
+func (s *MLESolver) entryPriorTargets() map[string][2]float64 {
+	quantile := s.options.SimParams.EntryPriorQuantile
+	if quantile <= 0 || len(s.entryTeams) == 0 {
+		return nil
+	}
+
+	// Established teams per league in the latest season
+	leagueTeams := make(map[string]map[string]bool)
+	for _, match := range s.matches {
+		if match.Season != s.latestSeason || match.Competition != "" {
+			continue
+		}
+		if leagueTeams[match.League] == nil {
+			leagueTeams[match.League] = make(map[string]bool)
+		}
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if _, isEntry := s.entryTeams[team]; !isEntry {
+				leagueTeams[match.League][team] = true
+			}
+		}
+	}
+
+	targets := make(map[string][2]float64)
+	for team, league := range s.entryTeams {
+		var attacks, defenses []float64
+		for established := range leagueTeams[league] {
+			attacks = append(attacks, s.params.AttackRatings[established])
+			defenses = append(defenses, s.params.DefenseRatings[established])
+		}
+		if len(attacks) == 0 {
+			continue
+		}
+		targets[team] = [2]float64{quantileOf(attacks, quantile), quantileOf(defenses, quantile)}
+	}
+	return targets
+}
+
+// applyEntryPrior starts entry teams at their entry prior targets, before the first iteration
+// From zero ratings every target is zero; from warm-start ratings they are already spread out
+func (s *MLESolver) applyEntryPrior() {
+	for team, target := range s.entryPriorTargets() {
+		s.params.AttackRatings[team], s.params.DefenseRatings[team] = target[0], target[1]
+		if s.options.Debug {
+			fmt.Printf("🆕 Entry prior for %s (%s): attack=%.3f, defense=%.3f\n", team, s.entryTeams[team], target[0], target[1])
+		}
+	}
+}
+
+// addEntryPriorGradients adds the gradient of a normal prior on each entry team's ratings,
+// centred on its entry prior target with variance SimParams.EntryPriorVariance, so entry teams
+// are shrunk toward the quantile at every iteration as the established ratings spread out
+func (s *MLESolver) addEntryPriorGradients(gradients map[string]float64) {
+	variance := s.options.SimParams.EntryPriorVariance
+	if variance <= 0 {
+		variance = DefaultSimParams().EntryPriorVariance
+	}
+	for team, target := range s.entryPriorTargets() {
+		gradients[team+"_attack"] -= (s.params.AttackRatings[team] - target[0]) / variance
+		gradients[team+"_defense"] -= (s.params.DefenseRatings[team] - target[1]) / variance
+	}
+}
+
+// quantileOf returns the q-quantile (0-1) of values with linear interpolation
+func quantileOf(values []float64, q float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	position := math.Max(0, math.Min(1, q)) * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
@@ -0,0 +1,74 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+)
+
+// NoUpperBound can be passed as the upper bound of a points band for "min or more" questions
+const NoUpperBound = math.MaxInt
+
+// PointsBandProbability returns P(min <= final points <= max) for a team from the retained league simulation
+func PointsBandProbability(result *MultiLeagueResult, league, team string, min, max int) (float64, error) {
+	simPoints, teamIdx, err := lookupSimulation(result, league, team)
+	if err != nil {
+		return 0, err
+	}
+	if min > max {
+		return 0, fmt.Errorf("invalid points band [%d, %d]", min, max)
+	}
+
+	count := 0
+	for path := 0; path < simPoints.NPaths; path++ {
+		if points := simPoints.Points(teamIdx, path); points >= min && points <= max {
+			count++
+		}
+	}
+	return float64(count) / float64(simPoints.NPaths), nil
+}
+
+// PositionRangeProbability returns P(from <= finishing position <= to) for a team, with 1 as the top
+// position; exact ties are dead-heated as in the mark values
+func PositionRangeProbability(result *MultiLeagueResult, league, team string, from, to int) (float64, error) {
+	simPoints, _, err := lookupSimulation(result, league, team)
+	if err != nil {
+		return 0, err
+	}
+	if from < 1 || to > len(simPoints.TeamNames) || from > to {
+		return 0, fmt.Errorf("invalid position range [%d, %d] for %d teams", from, to, len(simPoints.TeamNames))
+	}
+
+	probability := 0.0
+	for _, p := range simPoints.positionProbabilities(nil)[team][from-1 : to] {
+		probability += p
+	}
+	return probability, nil
+}
+
+// simulation returns a league's retained simulation, which must not have been released
+func (result *MultiLeagueResult) simulation(league string) (*SimPoints, error) {
+	if result == nil {
+		return nil, fmt.Errorf("result is required")
+	}
+	simPoints, exists := result.Simulations[league]
+	if !exists || simPoints == nil {
+		return nil, fmt.Errorf("no simulation for league %s", league)
+	}
+	if simPoints.released() {
+		return nil, fmt.Errorf("league %s: %w", league, ErrSimulationReleased)
+	}
+	return simPoints, nil
+}
+
+// lookupSimulation finds a league's retained simulation and the team's index within it
+func lookupSimulation(result *MultiLeagueResult, league, team string) (*SimPoints, int, error) {
+	simPoints, err := result.simulation(league)
+	if err != nil {
+		return nil, -1, err
+	}
+	teamIdx := simPoints.getTeamIndex(team)
+	if teamIdx < 0 {
+		return nil, -1, &UnknownTeamError{Team: team, League: league, Context: "simulation"}
+	}
+	return simPoints, teamIdx, nil
+}
@@ -0,0 +1,118 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Bounds on a fitted season's rho, inside which every Dixon-Coles adjustment stays positive
+const (
+	minSeasonRho = -0.5
+	maxSeasonRho = 0.5
+)
+
+// initialSeasonRho returns the starting per-season rho: the supplied schedule, plus every other
+// season at Rho (or its warm-start value) when FitSeasonRho is set
+// Returns nil when neither applies, so every match uses the single Rho
+func (s *MLESolver) initialSeasonRho() map[string]float64 {
+	simParams := s.options.SimParams
+	if len(simParams.SeasonRho) == 0 && !simParams.FitSeasonRho {
+		return nil
+	}
+
+	seasonRho := copyMap(simParams.SeasonRho)
+	if simParams.FitSeasonRho {
+		for _, match := range s.matches {
+			if _, exists := seasonRho[match.Season]; exists {
+				continue
+			}
+			seasonRho[match.Season] = simParams.Rho
+			if s.initialParams != nil {
+				if value, exists := s.initialParams.SeasonRho[match.Season]; exists {
+					seasonRho[match.Season] = value
+				}
+			}
+		}
+	}
+	return seasonRho
+}
+
+// updateSeasonRho takes one Newton step for each fitted season's rho, with a normal prior centred
+// on Rho so a season with few matches stays close to the default. The adjustment alone does not
+// sum to one over the scores, so each match's score probability is normalised by
+// 1 + ρ·(P(1-0) + P(0-1) − P(0-0) − P(1-1)); otherwise rho would ignore the rates and follow the
+// low-score counts alone. Seasons in the supplied SeasonRho schedule are held fixed
+func (s *MLESolver) updateSeasonRho() {
+	simParams := s.options.SimParams
+	priorVariance := simParams.RhoPriorVariance
+	if priorVariance <= 0 {
+		priorVariance = DefaultSimParams().RhoPriorVariance
+	}
+
+	gradients := make(map[string]float64)
+	curvatures := make(map[string]float64)
+	for _, match := range s.matches {
+		if _, fixed := simParams.SeasonRho[match.Season]; fixed {
+			continue
+		}
+		rho := s.params.SeasonRho[match.Season]
+		weight := s.getMatchWeight(match)
+		covariateTerm := s.covariateTerm(match)
+		lambdaHome := cappedLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.HomeTeam]-s.params.DefenseRatings[match.AwayTeam]+s.matchHomeAdvantage(match)+covariateTerm), simParams)
+		lambdaAway := cappedLambda(math.Exp(s.matchIntercept(match)+s.params.AttackRatings[match.AwayTeam]-s.params.DefenseRatings[match.HomeTeam]-covariateTerm), simParams)
+
+		// The normaliser's derivative in ρ: P(1-0) + P(0-1) − P(0-0) − P(1-1)
+		slope := math.Exp(-lambdaHome-lambdaAway) * (lambdaHome + lambdaAway - 1 - lambdaHome*lambdaAway)
+		normaliser := slope / (1 + rho*slope)
+		score := -normaliser
+		curvature := -normaliser * normaliser
+		if match.HomeGoals <= 1 && match.AwayGoals <= 1 {
+			// The adjustment is 1 − ρ or 1 + ρ, so its log's derivative is ∓1 over it
+			adjustment := 1 / (1 + rho)
+			if match.HomeGoals == match.AwayGoals {
+				adjustment = -1 / (1 - rho)
+			}
+			score += adjustment
+			curvature += adjustment * adjustment
+		}
+		gradients[match.Season] += weight * score
+		curvatures[match.Season] += weight * curvature
+	}
+
+	for season, gradient := range gradients {
+		value := s.params.SeasonRho[season]
+		gradient -= (value - simParams.Rho) / priorVariance
+		curvature := curvatures[season] + 1/priorVariance
+		if curvature <= 0 {
+			continue
+		}
+		s.params.SeasonRho[season] = math.Max(minSeasonRho, math.Min(maxSeasonRho, value+gradient/curvature))
+	}
+}
+
+// finishRho sets Rho to the latest season's value, which pricing and season simulation use, once
+// per-season values are known
+func (s *MLESolver) finishRho() {
+	value, exists := s.params.SeasonRho[s.latestSeason]
+	if !exists {
+		return
+	}
+	s.params.Rho = value
+
+	if s.options.Debug {
+		var parts []string
+		for _, season := range sortedKeys(s.params.SeasonRho) {
+			parts = append(parts, fmt.Sprintf("%s=%.3f", season, s.params.SeasonRho[season]))
+		}
+		fmt.Printf("🥅 Season rho: %s (current: %.3f)\n", strings.Join(parts, ", "), value)
+	}
+}
+
+// matchRho returns the Dixon-Coles rho for a match: its season's value when per-season rho applies
+func (s *MLESolver) matchRho(match MatchResult) float64 {
+	if value, exists := s.params.SeasonRho[match.Season]; exists {
+		return value
+	}
+	return s.params.Rho
+}
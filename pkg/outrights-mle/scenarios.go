@@ -0,0 +1,72 @@
+package outrightsmle
+
+// scenarioPercentiles are the seasons reported in Team.Scenarios: best, median and worst case
+var scenarioPercentiles = []int{90, 50, 10}
+
+// Scenario is one season outcome of a team at a percentile of its simulated seasons, ordered by
+// final points and then finishing position, e.g. the 90th percentile: 74 points, 4th
+// Points and position come from the same paths, so they describe one season rather than two
+// separate marginals
+type Scenario struct {
+	Percentile int `json:"percentile"`
+	Points     int `json:"points"`   // Final points, handicaps included
+	Position   int `json:"position"` // Finishing position (1 = top)
+}
+
+// pathPositions fills positions with each selected team's finishing position on one path
+// Teams sharing a dead-heated group share its highest position
+func (sp *SimPoints) pathPositions(selectedIndices []int, path int, positions []int) {
+	pos := 1
+	for _, group := range sp.pathStandings(selectedIndices, path) {
+		for _, team := range group {
+			positions[team] = pos
+		}
+		pos += len(group)
+	}
+}
+
+// scenarios returns each team's outcomes at the given percentiles of its paths, which are ordered
+// by points and then by position, better seasons higher; a percentile p takes the nearest-rank
+// path, the ceil(p% of NPaths)-th from the bottom
+// Outcomes are tallied by points and position rather than sorted per team
+func (sp *SimPoints) scenarios(percentiles []int) map[string][]Scenario {
+	n := len(sp.TeamNames)
+	if n == 0 || sp.NPaths == 0 {
+		return nil
+	}
+	selectedIndices := sp.selectTeams(sp.TeamNames)
+	low, high := sp.Points(0, 0), sp.Points(0, 0)
+	for team := 0; team < n; team++ {
+		for path := 0; path < sp.NPaths; path++ {
+			low, high = min(low, sp.Points(team, path)), max(high, sp.Points(team, path))
+		}
+	}
+
+	// team -> (points - low) * n + (n - position): ascending order of seasons
+	width := (high - low + 1) * n
+	tallies := make([]int, n*width)
+	positions := make([]int, n)
+	for path := 0; path < sp.NPaths; path++ {
+		sp.pathPositions(selectedIndices, path, positions)
+		for team := 0; team < n; team++ {
+			tallies[team*width+(sp.Points(team, path)-low)*n+n-positions[team]]++
+		}
+	}
+
+	scenarios := make(map[string][]Scenario, n)
+	for team, name := range sp.TeamNames {
+		teamScenarios := make([]Scenario, len(percentiles))
+		for i, percentile := range percentiles {
+			rank := max((percentile*sp.NPaths+99)/100, 1)
+			seen := 0
+			for key, tally := range tallies[team*width : (team+1)*width] {
+				if seen += tally; seen >= rank {
+					teamScenarios[i] = Scenario{Percentile: percentile, Points: low + key/n, Position: n - key%n}
+					break
+				}
+			}
+		}
+		scenarios[name] = teamScenarios
+	}
+	return scenarios
+}
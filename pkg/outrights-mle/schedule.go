@@ -0,0 +1,74 @@
+package outrightsmle
+
+import "fmt"
+
+// RoundRobinSchedule generates a full-season schedule in which every ordered pair of teams meets
+// rounds times (1 = home and away once), for pricing leagues whose fixtures are not announced
+// Each cycle is a single round robin by the circle method followed by its mirror with venues
+// swapped, so every team has the same number of home and away games. Within a leg, venues
+// alternate as far as the method allows (at most three in a row at one venue for 20 teams).
+// Round holds the matchday number; with an odd number of teams one team rests each matchday.
+// Dates are left empty
+func RoundRobinSchedule(league string, teams []string, rounds int) ([]ScheduledFixture, error) {
+	if len(teams) < 2 {
+		return nil, fmt.Errorf("league %s round robin needs at least 2 teams, got %d", league, len(teams))
+	}
+	if rounds < 1 {
+		return nil, fmt.Errorf("league %s round robin rounds must be at least 1, got %d", league, rounds)
+	}
+	seen := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		if team == "" {
+			return nil, fmt.Errorf("league %s round robin has an empty team name", league)
+		}
+		if seen[team] {
+			return nil, fmt.Errorf("league %s round robin lists %s twice", league, team)
+		}
+		seen[team] = true
+	}
+
+	// An empty slot stands for the bye when the team count is odd
+	slots := append([]string(nil), teams...)
+	if len(slots)%2 == 1 {
+		slots = append(slots, "")
+	}
+	n := len(slots)
+
+	// One leg: slot 0 stays put while the others rotate one place per matchday
+	var leg [][]ScheduledFixture
+	for day := 0; day < n-1; day++ {
+		var matchday []ScheduledFixture
+		for i := 0; i < n/2; i++ {
+			home, away := slots[i], slots[n-1-i]
+			// The fixed team alternates venue by matchday; the other pairings alternate by position,
+			// so a rotating team's venue flips as it moves one place each matchday
+			if (i == 0 && day%2 == 1) || (i > 0 && i%2 == 1) {
+				home, away = away, home
+			}
+			if home == "" || away == "" {
+				continue
+			}
+			matchday = append(matchday, ScheduledFixture{League: league, HomeTeam: home, AwayTeam: away})
+		}
+		leg = append(leg, matchday)
+		slots = append(slots[:1], append([]string{slots[n-1]}, slots[1:n-1]...)...)
+	}
+
+	schedule := make([]ScheduledFixture, 0, rounds*len(teams)*(len(teams)-1))
+	round := 0
+	for cycle := 0; cycle < rounds; cycle++ {
+		for _, mirrored := range []bool{false, true} {
+			for _, matchday := range leg {
+				round++
+				for _, fixture := range matchday {
+					if mirrored {
+						fixture.HomeTeam, fixture.AwayTeam = fixture.AwayTeam, fixture.HomeTeam
+					}
+					fixture.Round = round
+					schedule = append(schedule, fixture)
+				}
+			}
+		}
+	}
+	return schedule, nil
+}
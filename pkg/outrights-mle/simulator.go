@@ -1,47 +1,131 @@
 package outrightsmle
 
 import (
+	"hash/fnv"
 	"math"
 	"math/rand"
+	"runtime"
 	"sort"
 	"strings"
-	"time"
+	"sync"
 )
 
 
 type SimPoints struct {
 	NPaths         int
 	TeamNames      []string
-	Points         [][]int  // Match points (3/1/0) per team per simulation path
-	GoalDifference [][]int  // Goal difference per team per simulation path
+	// Simulated points and goals as int16 deltas on the current table, in flat team-major slices
+	// (team*NPaths + path) carved from one pooled slab; read them through Points, GoalDifference,
+	// GoalsFor and GoalsAgainst, which add the table's totals back
+	points           []int16
+	goalsFor         []int16
+	goalsAgainst     []int16
+	slab             *[]int16 // Backing store of the three delta slices (nil once released)
+	basePoints       []int    // Current table points per team, handicaps included
+	baseGoalsFor     []int
+	baseGoalsAgainst []int
 	// Cache for position probabilities to avoid expensive recalculations
 	positionCache map[string]map[string][]float64 // sortedTeamsKey -> teamName -> probabilities
+	cacheMu       sync.Mutex                      // Guards positionCache for concurrent post-hoc queries
+	rng           *rand.Rand                      // Per-simulation random source (never shared)
+	deadHeat      bool                            // Split positions between exactly tied teams
+	tiebreaks     []string                        // Tiebreak chain after points (see rankStandings)
+	headToHead    [][]int                         // team -> opponent -> played points (nil unless head-to-head is a tiebreak)
+	fixtureTeams  [][2]int                        // fixture -> home and away team indices, aligned with outcomes
+	Fixtures      []string                        // Simulated fixtures ("{Home} vs {Away}") in simulation order
+	outcomes      [][]int8                        // fixture -> path -> outcome (outcomeHome/outcomeDraw/outcomeAway)
 }
 
+// Simulated match outcomes recorded per path
+const (
+	outcomeHome int8 = iota
+	outcomeDraw
+	outcomeAway
+)
+
+// newSimPoints returns a simulation of teamNames from an empty table
 func newSimPoints(teamNames []string, nPaths int) *SimPoints {
+	size := len(teamNames) * nPaths
+	slab := acquireSimBuffer(3 * size)
 	sp := &SimPoints{
-		NPaths:         nPaths,
-		TeamNames:      make([]string, len(teamNames)),
-		Points:         make([][]int, len(teamNames)),
-		GoalDifference: make([][]int, len(teamNames)),
-		positionCache:  make(map[string]map[string][]float64),
+		NPaths:           nPaths,
+		TeamNames:        append([]string(nil), teamNames...),
+		points:           (*slab)[:size:size],
+		goalsFor:         (*slab)[size : 2*size : 2*size],
+		goalsAgainst:     (*slab)[2*size:],
+		slab:             slab,
+		basePoints:       make([]int, len(teamNames)),
+		baseGoalsFor:     make([]int, len(teamNames)),
+		baseGoalsAgainst: make([]int, len(teamNames)),
+		positionCache:    make(map[string]map[string][]float64),
+		rng:              newSimulationRand(0, ""),
+		tiebreaks:        defaultTiebreaks,
 	}
-	
-	for i, teamName := range teamNames {
-		sp.TeamNames[i] = teamName
-		sp.Points[i] = make([]int, nPaths)
-		sp.GoalDifference[i] = make([]int, nPaths)
-		
-		// Initialize all paths to 0
-		for j := 0; j < nPaths; j++ {
-			sp.Points[i][j] = 0
-			sp.GoalDifference[i][j] = 0
-		}
-	}
-	
 	return sp
 }
 
+// simBuffers pools the delta slabs of released simulations, so the next league simulated reuses
+// one instead of allocating
+var simBuffers sync.Pool
+
+// acquireSimBuffer returns a zeroed slab of size int16s, from the pool when one is big enough
+func acquireSimBuffer(size int) *[]int16 {
+	if slab, ok := simBuffers.Get().(*[]int16); ok && cap(*slab) >= size {
+		*slab = (*slab)[:size]
+		clear(*slab)
+		return slab
+	}
+	slab := make([]int16, size)
+	return &slab
+}
+
+// release returns the simulation's slab to the pool; the path accessors must not be called
+// afterwards and the queries report ErrSimulationReleased. Releasing it again does nothing
+func (sp *SimPoints) release() {
+	if sp.slab == nil {
+		return
+	}
+	simBuffers.Put(sp.slab)
+	sp.slab, sp.points, sp.goalsFor, sp.goalsAgainst = nil, nil, nil, nil
+}
+
+// released reports whether release has handed the simulation's slab back to the pool
+func (sp *SimPoints) released() bool {
+	return sp.slab == nil
+}
+
+// Points returns a team's final points (table plus simulated) on one simulation path
+func (sp *SimPoints) Points(team, path int) int {
+	return sp.basePoints[team] + int(sp.points[team*sp.NPaths+path])
+}
+
+// GoalsFor returns a team's final goals scored on one simulation path
+func (sp *SimPoints) GoalsFor(team, path int) int {
+	return sp.baseGoalsFor[team] + int(sp.goalsFor[team*sp.NPaths+path])
+}
+
+// GoalsAgainst returns a team's final goals conceded on one simulation path
+func (sp *SimPoints) GoalsAgainst(team, path int) int {
+	return sp.baseGoalsAgainst[team] + int(sp.goalsAgainst[team*sp.NPaths+path])
+}
+
+// GoalDifference returns a team's final goal difference on one simulation path
+func (sp *SimPoints) GoalDifference(team, path int) int {
+	return sp.GoalsFor(team, path) - sp.GoalsAgainst(team, path)
+}
+
+
+// newSimulationRand returns a random source for one league's simulation
+// A zero seed draws a random seed; otherwise the league name is mixed in so leagues get
+// independent but reproducible streams regardless of the order they are simulated in
+func newSimulationRand(seed int64, league string) *rand.Rand {
+	if seed == 0 {
+		return rand.New(rand.NewSource(rand.Int63()))
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(league))
+	return rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+}
 
 func (sp *SimPoints) getTeamIndex(teamName string) int {
 	for i, name := range sp.TeamNames {
@@ -54,7 +138,8 @@ func (sp *SimPoints) getTeamIndex(teamName string) int {
 
 // simulate simulates a single match between home and away teams across all paths
 // Copied exactly from gist simulator.go lines 51-94
-func (sp *SimPoints) simulate(homeTeam, awayTeam string, solver *MLESolver) {
+// A non-nil fixed result pins the fixture's outcome (or exact score) on every path
+func (sp *SimPoints) simulate(homeTeam, awayTeam string, solver *MLESolver, fixed *FixedResult) {
 	homeIdx := sp.getTeamIndex(homeTeam)
 	awayIdx := sp.getTeamIndex(awayTeam)
 	
@@ -68,161 +153,349 @@ func (sp *SimPoints) simulate(homeTeam, awayTeam string, solver *MLESolver) {
 	awayAttack := solver.params.AttackRatings[awayTeam]
 	awayDefense := solver.params.DefenseRatings[awayTeam]
 	
-	lambdaHome := math.Exp(homeAttack - awayDefense + solver.params.HomeAdvantage)
-	lambdaAway := math.Exp(awayAttack - homeDefense)
+	lambdaHome := cappedLambda(math.Exp(solver.params.Intercept + homeAttack - awayDefense + solver.params.HomeAdvantage), solver.options.SimParams)
+	lambdaAway := cappedLambda(math.Exp(solver.params.Intercept + awayAttack - homeDefense), solver.options.SimParams)
+	
+	// Goals follow the params' count model
+	model := solver.goalModel()
+	sampleHome, sampleAway := goalSampler(model, lambdaHome), goalSampler(model, lambdaAway)
+	draw := func() (int, int) {
+		return sampleHome(sp.rng), sampleAway(sp.rng)
+	}
+	
+	// A fixed outcome the draws rarely reach samples from the score matrix's cells with that
+	// outcome, built on first use; rho is 0 as the draws themselves are independent
+	var conditional *conditionalScores
+	fallback := func() (int, int) {
+		if conditional == nil {
+			bound := solver.options.SimParams.GoalSimulationBound
+			if bound < 1 {
+				bound = DefaultSimParams().GoalSimulationBound
+			}
+			conditional = newConditionalScores(fixed, NewCountScoreMatrix(model, lambdaHome, lambdaAway, 0, bound))
+		}
+		return conditional.sample(sp.rng)
+	}
+	
+	// Record each path's result so outcomes can be conditioned on later
+	outcomes := make([]int8, sp.NPaths)
+	sp.Fixtures = append(sp.Fixtures, homeTeam+" vs "+awayTeam)
+	sp.outcomes = append(sp.outcomes, outcomes)
+	sp.fixtureTeams = append(sp.fixtureTeams, [2]int{homeIdx, awayIdx})
 	
 	// Simulate NPaths matches
+	homeRow, awayRow := sp.points[homeIdx*sp.NPaths:(homeIdx+1)*sp.NPaths], sp.points[awayIdx*sp.NPaths:(awayIdx+1)*sp.NPaths]
+	homeFor, awayFor := sp.goalsFor[homeIdx*sp.NPaths:(homeIdx+1)*sp.NPaths], sp.goalsFor[awayIdx*sp.NPaths:(awayIdx+1)*sp.NPaths]
+	homeAgainst, awayAgainst := sp.goalsAgainst[homeIdx*sp.NPaths:(homeIdx+1)*sp.NPaths], sp.goalsAgainst[awayIdx*sp.NPaths:(awayIdx+1)*sp.NPaths]
 	for path := 0; path < sp.NPaths; path++ {
-		// Generate Poisson scores
-		homeGoals := PoissonSample(lambdaHome)
-		awayGoals := PoissonSample(lambdaAway)
+		// Generate scores
+		homeGoals, awayGoals := draw()
+		if fixed != nil {
+			homeGoals, awayGoals = fixed.score(homeGoals, awayGoals, draw, fallback)
+		}
 		
-		// Calculate points and goal difference
-		var homePoints, awayPoints int
+		// Calculate points
+		var homePoints, awayPoints int16
 		if homeGoals > awayGoals {
 			homePoints = 3
 			awayPoints = 0
+			outcomes[path] = outcomeHome
 		} else if homeGoals == awayGoals {
 			homePoints = 1
 			awayPoints = 1
+			outcomes[path] = outcomeDraw
 		} else {
 			homePoints = 0
 			awayPoints = 3
+			outcomes[path] = outcomeAway
 		}
 		
-		// Track points and goal difference separately
-		homeGD := homeGoals - awayGoals
-		awayGD := awayGoals - homeGoals
-		
 		// Add match points (3/1/0 only)
-		sp.Points[homeIdx][path] += homePoints
-		sp.Points[awayIdx][path] += awayPoints
+		homeRow[path] += homePoints
+		awayRow[path] += awayPoints
 		
-		// Track goal difference separately for tiebreaking
-		sp.GoalDifference[homeIdx][path] += homeGD
-		sp.GoalDifference[awayIdx][path] += awayGD
+		// Track goals scored and conceded for tiebreaking; goal difference is derived from them
+		homeFor[path] += int16(homeGoals)
+		awayFor[path] += int16(awayGoals)
+		homeAgainst[path] += int16(awayGoals)
+		awayAgainst[path] += int16(homeGoals)
 	}
 }
 
 
-// positionProbabilities calculates position probabilities for given teams with caching
-func (sp *SimPoints) positionProbabilities(teamNames []string) map[string][]float64 {
-	if teamNames == nil {
-		teamNames = sp.TeamNames
-	}
-	
-	// Create cache key from sorted team names
-	sortedNames := make([]string, len(teamNames))
-	copy(sortedNames, teamNames)
-	sort.Strings(sortedNames)
-	cacheKey := strings.Join(sortedNames, "|")
-	
-	// Check cache first
-	if cachedResult, exists := sp.positionCache[cacheKey]; exists {
-		return cachedResult
-	}
-	
-	// Create mask for selected teams
+// selectTeams returns the SimPoints indices of the named teams, skipping unknown names
+func (sp *SimPoints) selectTeams(teamNames []string) []int {
 	selectedIndices := make([]int, 0, len(teamNames))
 	for _, name := range teamNames {
 		if idx := sp.getTeamIndex(name); idx >= 0 {
 			selectedIndices = append(selectedIndices, idx)
 		}
 	}
-	
-	if len(selectedIndices) == 0 {
-		return make(map[string][]float64)
+	return selectedIndices
+}
+
+// pathStandings ranks the selected teams for one simulation path by points then the tiebreak chain
+// Returns groups in finishing order, each holding positions into selectedIndices; a group has more
+// than one team only when dead-heating and those teams are level on every tiebreak
+func (sp *SimPoints) pathStandings(selectedIndices []int, path int) [][]int {
+	order := make([]int, len(selectedIndices))
+	for i := range order {
+		order[i] = i
 	}
 	
-	// Extract points and goal difference for selected teams
-	selectedPoints := make([][]int, len(selectedIndices))
-	selectedGoalDiff := make([][]int, len(selectedIndices))
-	for i, idx := range selectedIndices {
-		selectedPoints[i] = sp.Points[idx]
-		selectedGoalDiff[i] = sp.GoalDifference[idx]
+	groups := rankStandings(order, sp.tiebreaks, pathKeys{sp: sp, selected: selectedIndices, path: path})
+	if sp.deadHeat {
+		return groups
 	}
 	
-	// Calculate positions for each path
-	positions := make([][]int, len(selectedIndices))
-	for i := range positions {
-		positions[i] = make([]int, sp.NPaths)
+	// Without dead-heating, teams level on everything keep their sort order
+	singles := make([][]int, 0, len(order))
+	for _, group := range groups {
+		for i := range group {
+			singles = append(singles, group[i:i+1])
+		}
+	}
+	return singles
+}
+
+// pathPayoffs returns each market team's payoff on every simulation path (dead-heated when enabled)
+func (sp *SimPoints) pathPayoffs(teamNames []string, payoff []float64) map[string][]float64 {
+	selectedIndices := sp.selectTeams(teamNames)
+	payoffs := make([][]float64, len(selectedIndices))
+	for i := range payoffs {
+		payoffs[i] = make([]float64, sp.NPaths)
 	}
 	
 	for path := 0; path < sp.NPaths; path++ {
-		// Create array of team data for this path
-		teamData := make([]struct {
-			TeamIndex      int
-			Points         float64
-			GoalDifference float64
-		}, len(selectedIndices))
-		
-		for i := range selectedIndices {
-			teamData[i] = struct {
-				TeamIndex      int
-				Points         float64
-				GoalDifference float64
-			}{
-				TeamIndex:      i,
-				Points:         float64(selectedPoints[i][path]),
-				GoalDifference: float64(selectedGoalDiff[i][path]),
+		pos := 0
+		for _, group := range sp.pathStandings(selectedIndices, path) {
+			// Tied teams share the average payoff of the positions they occupy
+			groupPayoff := 0.0
+			for groupPos := pos; groupPos < pos+len(group); groupPos++ {
+				if groupPos < len(payoff) {
+					groupPayoff += payoff[groupPos]
+				}
+			}
+			groupPayoff /= float64(len(group))
+			for _, team := range group {
+				payoffs[team][path] = groupPayoff
 			}
+			pos += len(group)
 		}
-		
-		// Sort by points (descending), with goal difference as tiny tiebreaker
-		// Goal difference tiebreaker factor: 0.0001 (small enough to never exceed 1 point)
-		sort.Slice(teamData, func(i, j int) bool {
-			teamI := teamData[i]
-			teamJ := teamData[j]
-			
-			// Primary: sort by points (descending)
-			if teamI.Points != teamJ.Points {
-				return teamI.Points > teamJ.Points
+	}
+	
+	result := make(map[string][]float64)
+	for i, idx := range selectedIndices {
+		result[sp.TeamNames[idx]] = payoffs[i]
+	}
+	return result
+}
+
+// positionChunkPaths is how many paths one worker ranks at a time in positionCounts
+const positionChunkPaths = 4096
+
+// positionCounts returns, for each set of selected teams, how often each team finished in each
+// position among that set across the paths
+// With dead-heating, teams level on every tiebreak share their positions equally
+// Each path is ranked once for all the sets: a set's standings are the whole league's with the
+// other teams left out, which holds for every tiebreak but head-to-head, whose mini-league
+// depends on which teams are compared, so the sets are then ranked one by one
+// Paths are ranked in chunks, in parallel on up to GOMAXPROCS goroutines. Each chunk tallies the
+// placings by the size of the group sharing them, in integers, so the counts are exact and do not
+// depend on how the paths were split
+func (sp *SimPoints) positionCounts(sets [][]int) [][][]float64 {
+	shared := !containsTiebreak(sp.tiebreaks, TiebreakHeadToHead)
+	allTeams := make([]int, len(sp.TeamNames))
+	for i := range allTeams {
+		allTeams[i] = i
+	}
+	// team -> position within each set, or -1 when not in it
+	setPositions := make([][]int, len(sets))
+	for s, set := range sets {
+		setPositions[s] = make([]int, len(sp.TeamNames))
+		for i := range setPositions[s] {
+			setPositions[s][i] = -1
+		}
+		for pos, team := range set {
+			setPositions[s][team] = pos
+		}
+	}
+	
+	nChunks := (sp.NPaths + positionChunkPaths - 1) / positionChunkPaths
+	chunkTallies := make([][][]int, nChunks)
+	countChunk := func(chunk int) {
+		// set -> team -> position -> size of the group sharing it, less one
+		tallies := make([][]int, len(sets))
+		for s, set := range sets {
+			tallies[s] = make([]int, len(set)*len(set)*len(set))
+		}
+		tally := func(s int, group []int, pos int) {
+			n := len(sets[s])
+			for _, team := range group {
+				for groupPos := pos; groupPos < pos+len(group); groupPos++ {
+					tallies[s][(team*n+groupPos)*n+len(group)-1]++
+				}
+			}
+		}
+		var members []int
+		end := min((chunk+1)*positionChunkPaths, sp.NPaths)
+		for path := chunk * positionChunkPaths; path < end; path++ {
+			if !shared {
+				for s, set := range sets {
+					pos := 0
+					for _, group := range sp.pathStandings(set, path) {
+						tally(s, group, pos)
+						pos += len(group)
+					}
+				}
+				continue
 			}
 			
-			// Tiebreaker: sort by goal difference (descending) with tiny factor
-			return teamI.GoalDifference > teamJ.GoalDifference
-		})
-		
-		// Assign positions (0 = first place, 1 = second place, etc.)
-		for pos, team := range teamData {
-			positions[team.TeamIndex][path] = pos
+			groups := rankStandings(allTeams, sp.tiebreaks, pathKeys{sp: sp, selected: allTeams, path: path})
+			for s := range sets {
+				pos := 0
+				for _, group := range groups {
+					members = members[:0]
+					for _, team := range group {
+						if setPos := setPositions[s][team]; setPos >= 0 {
+							members = append(members, setPos)
+						}
+					}
+					if len(members) == 0 {
+						continue
+					}
+					if sp.deadHeat {
+						tally(s, members, pos)
+						pos += len(members)
+						continue
+					}
+					// Without dead-heating, teams level on everything keep their order in the set
+					sort.Ints(members)
+					for i := range members {
+						tally(s, members[i:i+1], pos)
+						pos++
+					}
+				}
+			}
 		}
+		chunkTallies[chunk] = tallies
 	}
 	
-	// Calculate probabilities
-	probabilities := make(map[string][]float64)
-	for _, name := range teamNames {
-		if idx := sp.getTeamIndex(name); idx >= 0 {
-			probs := make([]float64, len(selectedIndices))
-			
-			// Find which index in selectedIndices this team corresponds to
-			selectedIdx := -1
-			for j, selIdx := range selectedIndices {
-				if selIdx == idx {
-					selectedIdx = j
-					break
+	workers := min(runtime.GOMAXPROCS(0), nChunks)
+	if workers <= 1 {
+		for chunk := 0; chunk < nChunks; chunk++ {
+			countChunk(chunk)
+		}
+	} else {
+		var wg sync.WaitGroup
+		chunks := make(chan int)
+		for worker := 0; worker < workers; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for chunk := range chunks {
+					countChunk(chunk)
 				}
+			}()
+		}
+		for chunk := 0; chunk < nChunks; chunk++ {
+			chunks <- chunk
+		}
+		close(chunks)
+		wg.Wait()
+	}
+	
+	counts := make([][][]float64, len(sets))
+	for s, set := range sets {
+		n := len(set)
+		tallies := make([]int, n*n*n)
+		for _, chunk := range chunkTallies {
+			for i, tally := range chunk[s] {
+				tallies[i] += tally
 			}
-			
-			if selectedIdx >= 0 {
-				// Count occurrences of each position
-				for path := 0; path < sp.NPaths; path++ {
-					pos := positions[selectedIdx][path]
-					probs[pos] += 1.0 / float64(sp.NPaths)
+		}
+		counts[s] = make([][]float64, n)
+		for team := range counts[s] {
+			counts[s][team] = make([]float64, n)
+			for pos := range counts[s][team] {
+				for size := 1; size <= n; size++ {
+					counts[s][team][pos] += float64(tallies[(team*n+pos)*n+size-1]) / float64(size)
 				}
 			}
-			
-			probabilities[name] = probs
 		}
 	}
+	return counts
+}
+
+// positionProbabilities calculates position probabilities for given teams with caching
+func (sp *SimPoints) positionProbabilities(teamNames []string) map[string][]float64 {
+	return sp.positionProbabilitiesFor([][]string{teamNames})[0]
+}
+
+// positionProbabilitiesFor calculates position probabilities for several sets of teams (nil for
+// all), ranking each path once for every set not already cached
+func (sp *SimPoints) positionProbabilitiesFor(teamSets [][]string) []map[string][]float64 {
+	probabilities := make([]map[string][]float64, len(teamSets))
+	var missingKeys []string
+	var missingSets [][]int
+	missing := make(map[string][]int) // cache key -> positions in teamSets
+	
+	for i, teamNames := range teamSets {
+		if teamNames == nil {
+			teamNames = sp.TeamNames
+		}
+		
+		// Create cache key from sorted team names
+		sortedNames := make([]string, len(teamNames))
+		copy(sortedNames, teamNames)
+		sort.Strings(sortedNames)
+		cacheKey := strings.Join(sortedNames, "|")
+		
+		// Check cache first
+		sp.cacheMu.Lock()
+		cachedResult, exists := sp.positionCache[cacheKey]
+		sp.cacheMu.Unlock()
+		if exists {
+			probabilities[i] = cachedResult
+			continue
+		}
+		
+		// Create mask for selected teams
+		selectedIndices := sp.selectTeams(teamNames)
+		if len(selectedIndices) == 0 {
+			probabilities[i] = make(map[string][]float64)
+			continue
+		}
+		if _, pending := missing[cacheKey]; !pending {
+			missingKeys = append(missingKeys, cacheKey)
+			missingSets = append(missingSets, selectedIndices)
+		}
+		missing[cacheKey] = append(missing[cacheKey], i)
+	}
+	if len(missingSets) == 0 {
+		return probabilities
+	}
 	
-	// Cache the result
-	sp.positionCache[cacheKey] = probabilities
+	for s, counts := range sp.positionCounts(missingSets) {
+		// Calculate probabilities
+		selectedIndices := missingSets[s]
+		setProbabilities := make(map[string][]float64)
+		for selectedIdx, idx := range selectedIndices {
+			probs := make([]float64, len(selectedIndices))
+			for pos, count := range counts[selectedIdx] {
+				probs[pos] = count / float64(sp.NPaths)
+			}
+			setProbabilities[sp.TeamNames[idx]] = probs
+		}
+		
+		// Cache the result
+		sp.cacheMu.Lock()
+		sp.positionCache[missingKeys[s]] = setProbabilities
+		sp.cacheMu.Unlock()
+		for _, i := range missing[missingKeys[s]] {
+			probabilities[i] = setProbabilities
+		}
+	}
 	
 	return probabilities
 }
-
-func init() {
-	// Seed the random number generator
-	rand.Seed(time.Now().UnixNano())
-}
\ No newline at end of file
@@ -0,0 +1,195 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+)
+
+// skellamTolerance is the relative size below which a series or tail term no longer changes a sum
+const skellamTolerance = 1e-17
+
+// SkellamProb calculates P(X − Y = k) where X ~ Poisson(lambdaHome) and Y ~ Poisson(lambdaAway)
+// are independent: the sum over y of P(X = y + k)·P(Y = y), the series of the modified Bessel
+// function I_|k|. Terms are summed until they stop changing the total, with no goal bound
+func SkellamProb(lambdaHome, lambdaAway float64, k int) float64 {
+	if k < 0 {
+		return SkellamProb(lambdaAway, lambdaHome, -k)
+	}
+	if lambdaHome <= 0 {
+		if k == 0 {
+			return PoissonProb(lambdaAway, 0)
+		}
+		return 0
+	}
+	if lambdaAway <= 0 {
+		return PoissonProb(lambdaHome, k)
+	}
+
+	// Terms rise to a peak near y = √(λh·λa), then fall faster than geometrically
+	logHome, logAway := math.Log(lambdaHome), math.Log(lambdaAway)
+	peak := math.Sqrt(lambdaHome * lambdaAway)
+	total := 0.0
+	for y := 0; ; y++ {
+		logTerm := float64(y+k)*logHome + float64(y)*logAway - lambdaHome - lambdaAway - lgammaFactorial(y+k) - lgammaFactorial(y)
+		term := math.Exp(logTerm)
+		total += term
+		if float64(y) > peak && term <= total*skellamTolerance {
+			return total
+		}
+	}
+}
+
+// SkellamDistribution is the exact distribution of home goals minus away goals for one match:
+// the Skellam distribution of the two Poisson rates with the Dixon-Coles adjustment of the
+// 0-0, 1-0, 0-1 and 1-1 scores, so it agrees with a ScoreMatrix without its goal bound
+type SkellamDistribution struct {
+	LambdaHome float64 `json:"lambda_home"`
+	LambdaAway float64 `json:"lambda_away"`
+	Rho        float64 `json:"rho"`
+}
+
+// NewSkellamDistribution creates the margin distribution for Poisson lambdas with Dixon-Coles rho
+func NewSkellamDistribution(lambdaHome, lambdaAway, rho float64) *SkellamDistribution {
+	return &SkellamDistribution{LambdaHome: lambdaHome, LambdaAway: lambdaAway, Rho: rho}
+}
+
+// Probability returns the probability that home goals minus away goals equals margin (negative
+// margins are away wins)
+func (d *SkellamDistribution) Probability(margin int) float64 {
+	probability := SkellamProb(d.LambdaHome, d.LambdaAway, margin)
+
+	// The adjustment scales the four low scores' probabilities: 0-0 and 1-1 by 1 − ρ, 1-0 and 0-1 by 1 + ρ
+	home := func(goals int) float64 { return PoissonProb(d.LambdaHome, goals) }
+	away := func(goals int) float64 { return PoissonProb(d.LambdaAway, goals) }
+	switch margin {
+	case 0:
+		probability -= d.Rho * (home(0)*away(0) + home(1)*away(1))
+	case 1:
+		probability += d.Rho * home(1) * away(0)
+	case -1:
+		probability += d.Rho * home(0) * away(1)
+	}
+	return probability
+}
+
+// AtLeast returns the probability that home goals minus away goals is margin or more
+func (d *SkellamDistribution) AtLeast(margin int) float64 {
+	// The margin's mode is at most one from λh − λa; past it the terms fall away quickly
+	mode := int(math.Ceil(d.LambdaHome - d.LambdaAway))
+	total := 0.0
+	for k := margin; ; k++ {
+		term := d.Probability(k)
+		total += term
+		if k > mode && term <= total*skellamTolerance {
+			return total
+		}
+	}
+}
+
+// AtMost returns the probability that home goals minus away goals is margin or less
+func (d *SkellamDistribution) AtMost(margin int) float64 {
+	mirrored := SkellamDistribution{LambdaHome: d.LambdaAway, LambdaAway: d.LambdaHome, Rho: d.Rho}
+	return mirrored.AtLeast(-margin)
+}
+
+// WinningMargins returns the winning-margin bands without truncation: the 3-or-more bands are the
+// full tails of the margin distribution
+func (d *SkellamDistribution) WinningMargins() WinningMargins {
+	return WinningMargins{
+		HomeBy1:     d.Probability(1),
+		HomeBy2:     d.Probability(2),
+		HomeBy3Plus: d.AtLeast(3),
+		Draw:        d.Probability(0),
+		AwayBy1:     d.Probability(-1),
+		AwayBy2:     d.Probability(-2),
+		AwayBy3Plus: d.AtMost(-3),
+	}
+}
+
+// AsianHandicap holds the settlement probabilities of a home Asian handicap bet and its fair
+// prices. A quarter line splits the stake over the two lines either side, so it can half win or
+// half lose; a whole line can push
+type AsianHandicap struct {
+	Fixture  string  `json:"fixture,omitempty"`
+	League   string  `json:"league,omitempty"`
+	Line     float64 `json:"line"` // Home handicap in goals, e.g. -1.25 (the away side gets +1.25)
+	Win      float64 `json:"win"`  // Home outcomes; the away side's are the mirror image
+	HalfWin  float64 `json:"half_win"`
+	Push     float64 `json:"push"`
+	HalfLoss float64 `json:"half_loss"`
+	Loss     float64 `json:"loss"`
+	HomeOdds float64 `json:"home_odds"` // Fair decimal odds, 0 when the side cannot win
+	AwayOdds float64 `json:"away_odds"`
+}
+
+// AsianHandicap prices the home side at line, which must be a multiple of 0.25
+func (d *SkellamDistribution) AsianHandicap(line float64) (AsianHandicap, error) {
+	quarters := line * 4
+	if quarters != math.Trunc(quarters) || math.IsInf(line, 0) {
+		return AsianHandicap{}, fmt.Errorf("asian handicap line must be a multiple of 0.25, got %v", line)
+	}
+	handicap := AsianHandicap{Line: line}
+
+	// settle returns the home win, push and loss probabilities at a whole or half line: home wins
+	// when the margin plus the line is positive
+	settle := func(line float64) (win, push, loss float64) {
+		win = d.AtLeast(int(math.Floor(-line)) + 1)
+		loss = d.AtMost(int(math.Ceil(-line)) - 1)
+		if -line == math.Trunc(-line) {
+			push = d.Probability(int(-line))
+		}
+		return win, push, loss
+	}
+	if int(quarters)%2 == 0 {
+		handicap.Win, handicap.Push, handicap.Loss = settle(line)
+	} else {
+		// The lower line wins only where the upper one does, and the upper loses only where the lower does
+		lowerWin, _, lowerLoss := settle(line - 0.25)
+		upperWin, _, upperLoss := settle(line + 0.25)
+		handicap.Win, handicap.HalfWin = lowerWin, upperWin-lowerWin
+		handicap.Loss, handicap.HalfLoss = upperLoss, lowerLoss-upperLoss
+	}
+
+	homeReturn := handicap.Win + handicap.HalfWin/2
+	awayReturn := handicap.Loss + handicap.HalfLoss/2
+	if homeReturn > 0 {
+		handicap.HomeOdds = 1 + awayReturn/homeReturn
+	}
+	if awayReturn > 0 {
+		handicap.AwayOdds = 1 + homeReturn/awayReturn
+	}
+	return handicap, nil
+}
+
+// PriceAsianHandicap prices a home Asian handicap at line for a "{Home} vs {Away}" fixture from the
+// exact margin distribution; neutral leaves out home advantage. Uses DefaultSimParams if simParams
+// is nil (MaxLambda applies; GoalSimulationBound does not). The margins are Skellam, so params
+// fitted with a count model other than Poisson are an error
+func PriceAsianHandicap(params MLEParams, simParams *SimParams, fixture, league string, line float64, neutral bool) (*AsianHandicap, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	if !params.poissonGoals() {
+		return nil, fmt.Errorf("asian handicaps need Poisson goals, but params use the %s count model", params.CountModel)
+	}
+	homeTeam, awayTeam, err := fixtureTeams(params, fixture)
+	if err != nil {
+		return nil, err
+	}
+	params = params.forLeague(league)
+
+	solver := &MLESolver{
+		params:  &params,
+		options: MLEOptions{SimParams: simParams},
+	}
+	homeAdvantage := params.HomeAdvantage
+	if neutral {
+		homeAdvantage = 0
+	}
+	handicap, err := solver.marginDistribution(homeTeam, awayTeam, homeAdvantage).AsianHandicap(line)
+	if err != nil {
+		return nil, err
+	}
+	handicap.Fixture, handicap.League = fixture, league
+	return &handicap, nil
+}
@@ -0,0 +1,106 @@
+package outrightsmle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MarkSnapshot is one run's marks, stored one JSON line per run
+type MarkSnapshot struct {
+	Timestamp   time.Time                                `json:"timestamp"`
+	Fingerprint string                                   `json:"fingerprint"` // EventsFingerprint of the events the run used
+	AsOfDate    string                                   `json:"as_of_date,omitempty"`
+	MarkValues  map[string]map[string]map[string]float64 `json:"mark_values"` // league -> market -> team -> mark_value
+}
+
+// MarkPoint is one team's mark in one market at one snapshot
+type MarkPoint struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Fingerprint string    `json:"fingerprint"`
+	Value       float64   `json:"value"`
+}
+
+// NewMarkSnapshot captures a result's marks, stamped with the run time and a fingerprint of the
+// events the run used (events and options as passed to RunMLESolver, so as-of runs differ)
+func NewMarkSnapshot(result *MultiLeagueResult, events []MatchResult, options MLEOptions, timestamp time.Time) (MarkSnapshot, error) {
+	used, err := filterAsOfDate(events, options.AsOfDate)
+	if err != nil {
+		return MarkSnapshot{}, err
+	}
+	return MarkSnapshot{
+		Timestamp:   timestamp.UTC(),
+		Fingerprint: EventsFingerprint(used),
+		AsOfDate:    options.AsOfDate,
+		MarkValues:  result.MarkValues,
+	}, nil
+}
+
+// WriteJSONLine writes the snapshot as one JSON line in a single Write, so appends to a file opened
+// with O_APPEND do not interleave
+func (snapshot MarkSnapshot) WriteJSONLine(w io.Writer) error {
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadMarkSnapshots decodes JSON lines snapshots from r, in the order they were written
+func ReadMarkSnapshots(r io.Reader) ([]MarkSnapshot, error) {
+	var snapshots []MarkSnapshot
+	decoder := json.NewDecoder(r)
+	for {
+		var snapshot MarkSnapshot
+		if err := decoder.Decode(&snapshot); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("decoding snapshot %d: %w", len(snapshots)+1, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// TeamSeries returns a team's mark in one market across snapshots, oldest first
+// Snapshots without the team in that market are skipped
+func TeamSeries(snapshots []MarkSnapshot, league, market, team string) []MarkPoint {
+	var series []MarkPoint
+	for _, snapshot := range snapshots {
+		if value, exists := snapshot.MarkValues[league][market][team]; exists {
+			series = append(series, MarkPoint{
+				Timestamp:   snapshot.Timestamp,
+				Fingerprint: snapshot.Fingerprint,
+				Value:       value,
+			})
+		}
+	}
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Timestamp.Before(series[j].Timestamp) })
+	return series
+}
+
+// EventsFingerprint returns a stable SHA-256 hex digest of match results, independent of their order
+func EventsFingerprint(events []MatchResult) string {
+	lines := make([]string, len(events))
+	for i, match := range events {
+		lines[i] = match.Date + "|" + match.Season + "|" + match.League + "|" + match.Competition + "|" +
+			match.HomeTeam + "|" + match.AwayTeam + "|" + strconv.Itoa(match.HomeGoals) + "|" +
+			strconv.Itoa(match.AwayGoals) + "|" + strconv.FormatBool(match.Neutral)
+	}
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
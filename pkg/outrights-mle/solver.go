@@ -3,9 +3,11 @@ package outrightsmle
 import (
 	"fmt"
 	"math"
+	"time"
 )
 
 // MLESolver implements Maximum Likelihood Estimation for team ratings
+// A solver holds its fit state, so use one instance per goroutine; separate instances share nothing
 type MLESolver struct {
 	matches       []MatchResult
 	options       MLEOptions
@@ -13,10 +15,28 @@ type MLESolver struct {
 	leagueChangeTeams map[string]bool // Teams that changed leagues before season start
 	params        *MLEParams
 	latestSeason  string          // Dynamically determined latest season
+	initialParams *MLEParams      // Optional warm-start ratings (teams not present start at zero)
+	entryTeams    map[string]string // Teams first seen in the latest season -> league entered
+	maxIterations int               // Overrides SimParams.MaxIterations when positive (early stopping refit)
+	onIteration   func(iter int) bool // Optional per-iteration hook; returning true stops the fit
+	droppedTeams  []DroppedTeam       // Teams left out for too few matches (SimParams.MinMatchesPerTeam)
+	covariates    map[covariateKey][]float64 // Home minus away form per match (SimParams.FormCovariates)
+	managerChanges map[string][]string       // Team -> manager change dates, oldest first (MLEOptions.ManagerChanges)
+	managerMatches map[string]int            // Team -> matches since its latest manager change
+	dense          map[denseKey]*denseMatches // Packed match lists for MLEOptions.VectorizedFit
+	countModel     CountModel                 // Count model of the current shape (SimParams.CountModel)
 }
 
 // NewMLESolver creates a new MLE solver instance
 func NewMLESolver(matches []MatchResult, options MLEOptions, leagueChangeTeams map[string]bool) *MLESolver {
+	// Find latest season dynamically
+	latestSeason := findLatestSeason(matches)
+
+	var droppedTeams []DroppedTeam
+	if options.SimParams != nil {
+		matches, droppedTeams = dropSparseTeams(matches, options.SimParams.MinMatchesPerTeam, latestSeason)
+	}
+
 	teamNames := make(map[string]bool)
 	for _, match := range matches {
 		teamNames[match.HomeTeam] = true
@@ -27,8 +47,12 @@ func NewMLESolver(matches []MatchResult, options MLEOptions, leagueChangeTeams m
 		leagueChangeTeams = make(map[string]bool)
 	}
 
-	// Find latest season dynamically
-	latestSeason := findLatestSeason(matches)
+	var covariates map[covariateKey][]float64
+	if options.SimParams != nil && len(options.SimParams.FormCovariates) > 0 {
+		covariates = formCovariates(matches, options.SimParams.FormCovariates, options.SimParams.FormWindow)
+	}
+
+	managerChanges := managerChangeDates(options.ManagerChanges)
 
 	return &MLESolver{
 		matches:           matches,
@@ -36,6 +60,11 @@ func NewMLESolver(matches []MatchResult, options MLEOptions, leagueChangeTeams m
 		teamNames:         teamNames,
 		leagueChangeTeams: leagueChangeTeams,
 		latestSeason:      latestSeason,
+		entryTeams:        findEntryTeams(matches, latestSeason),
+		droppedTeams:      droppedTeams,
+		covariates:        covariates,
+		managerChanges:    managerChanges,
+		managerMatches:    matchesUnderManager(matches, managerChanges),
 	}
 }
 
@@ -54,29 +83,90 @@ func findLatestSeason(matches []MatchResult) string {
 func (s *MLESolver) Optimize() (*MLEParams, error) {
 	// Get simulation parameters
 	simParams := s.options.SimParams
+	startTime := time.Now()
+
+	if err := validateCountModel(simParams); err != nil {
+		return nil, err
+	}
+	switch simParams.RatingModel {
+	case "", RatingModelStatic:
+	case RatingModelDynamic:
+		return s.optimizeDynamic()
+	default:
+		return nil, fmt.Errorf("unknown rating model %q (expected %q or %q)", simParams.RatingModel, RatingModelStatic, RatingModelDynamic)
+	}
+
+	if simParams.ValidationGameweeks > 0 && s.maxIterations == 0 {
+		return s.optimizeWithEarlyStopping()
+	}
+	maxIterations := simParams.MaxIterations
+	if s.maxIterations > 0 {
+		maxIterations = s.maxIterations
+	}
 
 	// Initialize parameters
 	s.params = &MLEParams{
 		HomeAdvantage:  simParams.HomeAdvantage,  // From SimParams
-		Rho:           -0.1,                      // Dixon-Coles parameter (standard value)
+		Rho:            simParams.Rho,            // From SimParams
 		AttackRatings:  make(map[string]float64),
 		DefenseRatings: make(map[string]float64),
+		DroppedTeams:   s.droppedTeams,
+	}
+	s.params.Intercept = s.initialIntercept()
+	s.params.LeagueIntercepts = s.initialLeagueIntercepts()
+	if s.params.LeagueIntercepts != nil {
+		s.params.TeamLeagues = latestTeamLeagues(s.matches)
+	}
+	s.params.SeasonHomeAdvantage = s.initialSeasonHomeAdvantage()
+	s.params.SeasonRho = s.initialSeasonRho()
+	s.params.CountModel, s.params.CountShape = s.initialCountModel()
+	if len(s.covariates) > 0 {
+		s.params.CovariateCoefficients = make(map[string]float64)
+		s.params.CovariateStandardErrors = make(map[string]float64)
+		if containsString(simParams.FormCovariates, CovariateShortRest) {
+			s.params.LastMatchDates = lastMatchDates(s.matches) // Rest for fixtures priced after the fit
+		}
+		for _, name := range simParams.FormCovariates {
+			if s.initialParams != nil {
+				s.params.CovariateCoefficients[name] = s.initialParams.CovariateCoefficients[name]
+			}
+		}
 	}
 
-	// Initialize ratings to zero (average team)
+	// Initialize ratings to zero (average team), or from warm-start ratings where available
 	for team := range s.teamNames {
 		s.params.AttackRatings[team] = 0.0
 		s.params.DefenseRatings[team] = 0.0
+		if s.initialParams != nil {
+			if attack, exists := s.initialParams.AttackRatings[team]; exists {
+				s.params.AttackRatings[team] = attack
+				s.params.DefenseRatings[team] = s.initialParams.DefenseRatings[team]
+			}
+		}
 	}
 
 	if s.options.Debug {
 		fmt.Printf("🔧 Starting MLE optimization for %d teams, %d matches...\n", len(s.teamNames), len(s.matches))
 		fmt.Printf("📅 Latest season detected: %s\n", s.latestSeason)
+		if s.options.VectorizedFit {
+			fmt.Printf("🧮 Vectorized likelihood (%s kernels)\n", vectorKernels)
+		}
+		for _, dropped := range s.droppedTeams {
+			fmt.Printf("🚫 Dropped %s: %d matches (last season %s)\n", dropped.Team, dropped.Matches, dropped.LastSeason)
+		}
 		if len(s.leagueChangeTeams) > 0 {
 			fmt.Printf("📈 Enhanced learning enabled for %d teams with league changes\n", len(s.leagueChangeTeams))
 		}
+		if len(s.managerChanges) > 0 {
+			fmt.Printf("👔 Enhanced learning enabled for %d teams with manager changes\n", len(s.managerChanges))
+		}
+		if transferWindowsEnabled(simParams) {
+			fmt.Printf("🔁 Enhanced learning for %d days after transfer windows (x%.2f)\n", simParams.TransferWindowDays, simParams.TransferWindowLearningRate)
+		}
 	}
 
+	s.applyEntryPrior()
+
 	learningRate := simParams.BaseLearningRate // From SimParams
 	prevLogLikelihood := s.CalculateLogLikelihood()
 	
@@ -85,8 +175,20 @@ func (s *MLESolver) Optimize() (*MLEParams, error) {
 	}
 	
 	// Gradient ascent optimization
-	for iter := 0; iter < simParams.MaxIterations; iter++ {
+	for iter := 0; iter < maxIterations; iter++ {
 		s.updateRatings(learningRate)
+		if simParams.FitSeasonHomeAdvantage {
+			s.updateSeasonHomeAdvantage()
+		}
+		if simParams.FitSeasonRho {
+			s.updateSeasonRho()
+		}
+		if len(s.covariates) > 0 {
+			s.updateCovariateCoefficients()
+		}
+		if !s.params.poissonGoals() {
+			s.updateCountShape()
+		}
 		
 		currentLogLikelihood := s.CalculateLogLikelihood()
 		
@@ -103,6 +205,18 @@ func (s *MLESolver) Optimize() (*MLEParams, error) {
 			if s.options.Debug {
 				fmt.Printf("✅ Converged at iteration %d (change: %.2e)\n", iter, math.Abs(currentLogLikelihood-prevLogLikelihood))
 			}
+			s.options.observeOptimization(s.params.Iterations, true, time.Since(startTime))
+			s.finishFit()
+			return s.params, nil
+		}
+		
+		// Stop when the hook asks (e.g., validation loss stopped improving)
+		if s.onIteration != nil && s.onIteration(iter) {
+			s.params.LogLikelihood = currentLogLikelihood
+			s.params.Iterations = iter + 1
+			s.params.Converged = false
+			s.options.observeOptimization(s.params.Iterations, false, time.Since(startTime))
+			s.finishFit()
 			return s.params, nil
 		}
 		
@@ -111,36 +225,64 @@ func (s *MLESolver) Optimize() (*MLEParams, error) {
 
 	// Maximum iterations reached
 	s.params.LogLikelihood = s.CalculateLogLikelihood()
-	s.params.Iterations = simParams.MaxIterations
+	s.params.Iterations = maxIterations
 	s.params.Converged = false
+	s.options.observeOptimization(s.params.Iterations, false, time.Since(startTime))
+	s.finishFit()
 
 	return s.params, nil
 }
 
+// finishFit settles the values that are only final once the fit stops
+func (s *MLESolver) finishFit() {
+	s.finishHomeAdvantage()
+	s.finishRho()
+	if s.options.Debug && len(s.params.LeagueIntercepts) > 0 {
+		s.printLeagueIntercepts()
+	}
+	if s.options.Debug && len(s.covariates) > 0 {
+		s.printCovariates()
+	}
+	if s.options.Debug && !s.params.poissonGoals() {
+		s.printCountShape()
+	}
+}
+
 // CalculateLogLikelihood computes the log likelihood of the current parameters
 func (s *MLESolver) CalculateLogLikelihood() float64 {
+	return s.logLikelihood(s.matches)
+}
+
+// logLikelihood computes the weighted log likelihood of matches under the current parameters
+func (s *MLESolver) logLikelihood(matches []MatchResult) float64 {
+	if !s.params.poissonGoals() {
+		return s.countLogLikelihood(s.goalModel(), matches)
+	}
+	if s.options.VectorizedFit {
+		return s.denseLogLikelihood(matches)
+	}
 	logLikelihood := 0.0
 	
-	for _, match := range s.matches {
+	for _, match := range matches {
 		homeAttack := s.params.AttackRatings[match.HomeTeam]
 		homeDefense := s.params.DefenseRatings[match.HomeTeam]
 		awayAttack := s.params.AttackRatings[match.AwayTeam]
 		awayDefense := s.params.DefenseRatings[match.AwayTeam]
 		
-		lambdaHome := math.Exp(homeAttack - awayDefense + s.params.HomeAdvantage)
-		lambdaAway := math.Exp(awayAttack - homeDefense)
+		covariateTerm := s.covariateTerm(match)
+		lambdaHome := cappedLambda(math.Exp(s.matchIntercept(match) + homeAttack - awayDefense + s.matchHomeAdvantage(match) + covariateTerm), s.options.SimParams)
+		lambdaAway := cappedLambda(math.Exp(s.matchIntercept(match) + awayAttack - homeDefense - covariateTerm), s.options.SimParams)
 		
 		// Direct calculation for optimization (performance critical)
 		// ScoreMatrix would be overkill here - we only need one specific scoreline probability,
 		// not the entire matrix. Creating a full matrix per match would be much slower.
 		probHome := PoissonProb(lambdaHome, match.HomeGoals)
 		probAway := PoissonProb(lambdaAway, match.AwayGoals)
-		adjustment := DixonColesAdjustment(match.HomeGoals, match.AwayGoals, s.params.Rho)
+		adjustment := DixonColesAdjustment(match.HomeGoals, match.AwayGoals, s.matchRho(match))
 		prob := probHome * probAway * adjustment
 		if prob > 0 {
-			// Apply time weighting to log-likelihood
-			timeWeight := s.getTimeWeight(match.Season)
-			logLikelihood += timeWeight * math.Log(prob)
+			// Apply time and competition weighting to log-likelihood
+			logLikelihood += s.getMatchWeight(match) * math.Log(prob)
 		}
 	}
 	
@@ -149,35 +291,10 @@ func (s *MLESolver) CalculateLogLikelihood() float64 {
 
 // updateRatings performs one step of gradient ascent
 func (s *MLESolver) updateRatings(learningRate float64) {
-	gradients := make(map[string]float64)
+	gradients := s.gradients(s.matches)
+	s.addEntryPriorGradients(gradients)
 	teamLastMatch := make(map[string]MatchResult) // Track last match per team for adaptive LR
-	
-	// Calculate gradients with time weighting
 	for _, match := range s.matches {
-		homeAttack := s.params.AttackRatings[match.HomeTeam]
-		homeDefense := s.params.DefenseRatings[match.HomeTeam]
-		awayAttack := s.params.AttackRatings[match.AwayTeam]
-		awayDefense := s.params.DefenseRatings[match.AwayTeam]
-		
-		lambdaHome := math.Exp(homeAttack - awayDefense + s.params.HomeAdvantage)
-		lambdaAway := math.Exp(awayAttack - homeDefense)
-		
-		// Apply time weighting - recent matches matter more
-		timeWeight := s.getTimeWeight(match.Season)
-		
-		// Gradient for home team attack
-		gradients[match.HomeTeam+"_attack"] += timeWeight * (float64(match.HomeGoals) - lambdaHome)
-		
-		// Gradient for away team attack
-		gradients[match.AwayTeam+"_attack"] += timeWeight * (float64(match.AwayGoals) - lambdaAway)
-		
-		// Gradient for home team defense
-		gradients[match.HomeTeam+"_defense"] += timeWeight * (lambdaAway - float64(match.AwayGoals))
-		
-		// Gradient for away team defense  
-		gradients[match.AwayTeam+"_defense"] += timeWeight * (lambdaHome - float64(match.HomeGoals))
-		
-		// Track most recent match for each team (for adaptive learning rate)
 		teamLastMatch[match.HomeTeam] = match
 		teamLastMatch[match.AwayTeam] = match
 	}
@@ -196,18 +313,69 @@ func (s *MLESolver) updateRatings(learningRate float64) {
 		}
 	}
 	
-	// Apply zero-sum constraint to prevent rating drift
+	// Apply zero-sum constraint to prevent rating drift, then any rating bounds
 	s.normalizeRatings()
+	s.boundRatings()
+}
+
+// gradients returns the log likelihood gradient over matches, keyed "{team}_attack" and "{team}_defense"
+func (s *MLESolver) gradients(matches []MatchResult) map[string]float64 {
+	if !s.params.poissonGoals() {
+		return s.countGradients(s.goalModel(), matches)
+	}
+	if s.options.VectorizedFit {
+		return s.denseGradients(matches)
+	}
+	gradients := make(map[string]float64)
+	
+	// Calculate gradients with time weighting
+	for _, match := range matches {
+		homeAttack := s.params.AttackRatings[match.HomeTeam]
+		homeDefense := s.params.DefenseRatings[match.HomeTeam]
+		awayAttack := s.params.AttackRatings[match.AwayTeam]
+		awayDefense := s.params.DefenseRatings[match.AwayTeam]
+		
+		covariateTerm := s.covariateTerm(match)
+		lambdaHome, homeCapped := capLambda(math.Exp(s.matchIntercept(match) + homeAttack - awayDefense + s.matchHomeAdvantage(match) + covariateTerm), s.options.SimParams)
+		lambdaAway, awayCapped := capLambda(math.Exp(s.matchIntercept(match) + awayAttack - homeDefense - covariateTerm), s.options.SimParams)
+		
+		// Apply time weighting - recent matches matter more, cup matches less
+		weight := s.getMatchWeight(match)
+		
+		// A rate held at MaxLambda does not move with the ratings, so it contributes no gradient
+		if !homeCapped {
+			// Gradient for home team attack
+			gradients[match.HomeTeam+"_attack"] += weight * (float64(match.HomeGoals) - lambdaHome)
+			
+			// Gradient for away team defense  
+			gradients[match.AwayTeam+"_defense"] += weight * (lambdaHome - float64(match.HomeGoals))
+		}
+		if !awayCapped {
+			// Gradient for away team attack
+			gradients[match.AwayTeam+"_attack"] += weight * (float64(match.AwayGoals) - lambdaAway)
+			
+			// Gradient for home team defense
+			gradients[match.HomeTeam+"_defense"] += weight * (lambdaAway - float64(match.AwayGoals))
+		}
+	}
+	
+	return gradients
 }
 
 // normalizeRatings applies zero-sum constraint to prevent rating drift
 func (s *MLESolver) normalizeRatings() {
+	// League intercepts take up each league's scoring level first
+	if len(s.params.LeagueIntercepts) > 0 {
+		s.balanceLeagueRatings()
+	}
+	
 	// Calculate sums
 	attackSum := 0.0
 	defenseSum := 0.0
 	teamCount := float64(len(s.teamNames))
 	
-	for team := range s.teamNames {
+	// In team order, so the sums round the same way every run
+	for _, team := range sortedKeys(s.teamNames) {
 		attackSum += s.params.AttackRatings[team]
 		defenseSum += s.params.DefenseRatings[team]
 	}
@@ -221,10 +389,32 @@ func (s *MLESolver) normalizeRatings() {
 		s.params.AttackRatings[team] -= attackAverage
 		s.params.DefenseRatings[team] -= defenseAverage
 	}
+	
+	// A fitted intercept takes up the common scoring level, leaving every rate unchanged
+	if s.options.SimParams.FitIntercept {
+		s.params.Intercept += attackAverage - defenseAverage
+	}
 }
 
 
 
+// matchHomeAdvantage returns the home advantage term for a match: its season's value when per-season
+// home advantage applies, zero at a neutral venue
+func (s *MLESolver) matchHomeAdvantage(match MatchResult) float64 {
+	if match.Neutral {
+		return 0
+	}
+	if value, exists := s.params.SeasonHomeAdvantage[match.Season]; exists {
+		return value
+	}
+	return s.params.HomeAdvantage
+}
+
+// getMatchWeight returns the likelihood weight of a match: time decay scaled by the competition weight
+func (s *MLESolver) getMatchWeight(match MatchResult) float64 {
+	return s.getTimeWeight(match.Season) * competitionWeight(s.options.SimParams, match.Competition)
+}
+
 // getTimeWeight returns temporal weighting for matches
 func (s *MLESolver) getTimeWeight(season string) float64 {
 	// Get simulation parameters
@@ -261,6 +451,12 @@ func (s *MLESolver) getAdaptiveLearningRate(team string, baseLearningRate float6
 	// Get simulation parameters
 	simParams := s.options.SimParams
 
+	// Teams under a new manager learn faster until the manager has a few matches
+	baseLearningRate *= s.managerChangeFactor(team)
+
+	// Squads turn over in transfer windows, so results just after one carry more news
+	baseLearningRate *= transferWindowFactor(match.Date, simParams)
+
 	// Apply enhanced learning ONLY for teams in their first season after changing leagues
 	if s.leagueChangeTeams[team] && match.Season == s.latestSeason {
 		// Linear decay from LeagueChangeLearningRate to 1.0 over their first season in new league
@@ -279,11 +475,11 @@ func (s *MLESolver) calculateExpectedMatchPoints(homeTeam, awayTeam string) (flo
 	awayAttack := s.params.AttackRatings[awayTeam]
 	awayDefense := s.params.DefenseRatings[awayTeam]
 	
-	lambdaHome := math.Exp(homeAttack - awayDefense + s.params.HomeAdvantage)
-	lambdaAway := math.Exp(awayAttack - homeDefense)
+	lambdaHome := cappedLambda(math.Exp(s.params.Intercept + homeAttack - awayDefense + s.params.HomeAdvantage), s.options.SimParams)
+	lambdaAway := cappedLambda(math.Exp(s.params.Intercept + awayAttack - homeDefense), s.options.SimParams)
 	
 	// Create score matrix and get match odds
-	scoreMatrix := NewScoreMatrix(lambdaHome, lambdaAway, s.params.Rho, s.options.SimParams.GoalSimulationBound)
+	scoreMatrix := NewCountScoreMatrix(s.goalModel(), lambdaHome, lambdaAway, s.params.Rho, s.options.SimParams.GoalSimulationBound)
 	odds := scoreMatrix.MatchOdds()
 	
 	// Calculate expected points (3 for win, 1 for draw, 0 for loss)
@@ -295,15 +491,45 @@ func (s *MLESolver) calculateExpectedMatchPoints(homeTeam, awayTeam string) (flo
 
 // CalculateMatchProbabilities calculates 1X2 probabilities for a match between two teams
 func (s *MLESolver) CalculateMatchProbabilities(homeTeam, awayTeam string) [3]float64 {
+	return s.calculateMatchProbabilities(homeTeam, awayTeam, s.params.HomeAdvantage)
+}
+
+// CalculateNeutralMatchProbabilities calculates 1X2 probabilities for a match at a neutral venue
+func (s *MLESolver) CalculateNeutralMatchProbabilities(homeTeam, awayTeam string) [3]float64 {
+	return s.calculateMatchProbabilities(homeTeam, awayTeam, 0)
+}
+
+// calculateMatchProbabilities calculates 1X2 probabilities with the given home advantage
+func (s *MLESolver) calculateMatchProbabilities(homeTeam, awayTeam string, homeAdvantage float64) [3]float64 {
+	return s.scoreMatrix(homeTeam, awayTeam, homeAdvantage).MatchOdds()
+}
+
+// scoreMatrix builds the correct score matrix for a match with the given home advantage
+func (s *MLESolver) scoreMatrix(homeTeam, awayTeam string, homeAdvantage float64) *ScoreMatrix {
+	return s.shiftedScoreMatrix(homeTeam, awayTeam, homeAdvantage, 0)
+}
+
+// shiftedScoreMatrix builds the score matrix with shift added to the home log scoring rate and
+// taken from the away one, as covariate terms enter the likelihood
+func (s *MLESolver) shiftedScoreMatrix(homeTeam, awayTeam string, homeAdvantage, shift float64) *ScoreMatrix {
+	lambdaHome, lambdaAway := s.fixtureLambdas(homeTeam, awayTeam, homeAdvantage, shift)
+	return NewCountScoreMatrix(s.goalModel(), lambdaHome, lambdaAway, s.params.Rho, s.options.SimParams.GoalSimulationBound)
+}
+
+// marginDistribution builds the exact goal margin distribution for a match with the given home advantage
+func (s *MLESolver) marginDistribution(homeTeam, awayTeam string, homeAdvantage float64) *SkellamDistribution {
+	lambdaHome, lambdaAway := s.fixtureLambdas(homeTeam, awayTeam, homeAdvantage, 0)
+	return NewSkellamDistribution(lambdaHome, lambdaAway, s.params.Rho)
+}
+
+// fixtureLambdas returns the capped expected goals of both sides, with shift as in shiftedScoreMatrix
+func (s *MLESolver) fixtureLambdas(homeTeam, awayTeam string, homeAdvantage, shift float64) (float64, float64) {
 	homeAttack := s.params.AttackRatings[homeTeam]
 	homeDefense := s.params.DefenseRatings[homeTeam]
 	awayAttack := s.params.AttackRatings[awayTeam]
 	awayDefense := s.params.DefenseRatings[awayTeam]
 	
-	lambdaHome := math.Exp(homeAttack - awayDefense + s.params.HomeAdvantage)
-	lambdaAway := math.Exp(awayAttack - homeDefense)
-	
-	// Create score matrix and return match odds
-	scoreMatrix := NewScoreMatrix(lambdaHome, lambdaAway, s.params.Rho, s.options.SimParams.GoalSimulationBound)
-	return scoreMatrix.MatchOdds()
+	lambdaHome := cappedLambda(math.Exp(s.params.Intercept + homeAttack - awayDefense + homeAdvantage + shift), s.options.SimParams)
+	lambdaAway := cappedLambda(math.Exp(s.params.Intercept + awayAttack - homeDefense - shift), s.options.SimParams)
+	return lambdaHome, lambdaAway
 }
\ No newline at end of file
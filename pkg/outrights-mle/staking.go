@@ -0,0 +1,98 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// KellyOptions configures Kelly stake sizing
+type KellyOptions struct {
+	Bankroll float64 `json:"bankroll"`  // Total bankroll to stake from
+	Fraction float64 `json:"fraction"`  // Kelly multiplier (1 = full Kelly, 0.5 = half Kelly)
+	MaxStake float64 `json:"max_stake"` // Cap per bet as a fraction of bankroll (0 = no cap)
+}
+
+// DefaultKellyOptions returns half Kelly on a unit bankroll capped at 5% per bet
+func DefaultKellyOptions() KellyOptions {
+	return KellyOptions{
+		Bankroll: 1.0,
+		Fraction: 0.5,
+		MaxStake: 0.05,
+	}
+}
+
+// KellyStake is a recommended stake for one team in one market
+type KellyStake struct {
+	League        string  `json:"league"`
+	Market        string  `json:"market"`
+	Team          string  `json:"team"`
+	Probability   float64 `json:"probability"`    // Model mark value
+	Price         float64 `json:"price"`          // Offered decimal odds
+	KellyFraction float64 `json:"kelly_fraction"` // Full Kelly fraction of bankroll
+	Stake         float64 `json:"stake"`          // Stake after fraction and cap
+}
+
+// KellyFraction returns the full Kelly fraction (p*o - 1)/(o - 1) for decimal odds o
+// Returns 0 when there is no edge or the price is invalid
+func KellyFraction(probability, price float64) float64 {
+	if price <= 1 || probability <= 0 {
+		return 0
+	}
+	fraction := (probability*price - 1) / (price - 1)
+	if fraction < 0 {
+		return 0
+	}
+	return fraction
+}
+
+// CalculateKellyStakes computes stakes for every priced team/market with positive edge
+// Marks are treated as win probabilities, so markets should have 0/1 payoffs
+// Returns stakes sorted by size (largest first)
+func CalculateKellyStakes(result *MultiLeagueResult, options KellyOptions) ([]KellyStake, error) {
+	if options.Bankroll <= 0 {
+		return nil, fmt.Errorf("kelly bankroll must be positive, got %v", options.Bankroll)
+	}
+	if options.Fraction <= 0 {
+		return nil, fmt.Errorf("kelly fraction must be positive, got %v", options.Fraction)
+	}
+
+	var stakes []KellyStake
+	for _, market := range result.Markets {
+		teamMarks := result.MarkValues[market.League][market.Name]
+		for team, price := range market.Prices {
+			probability, exists := teamMarks[team]
+			if !exists {
+				continue
+			}
+
+			kelly := KellyFraction(probability, price)
+			if kelly <= 0 {
+				continue
+			}
+
+			stakeFraction := kelly * options.Fraction
+			if options.MaxStake > 0 && stakeFraction > options.MaxStake {
+				stakeFraction = options.MaxStake
+			}
+
+			stakes = append(stakes, KellyStake{
+				League:        market.League,
+				Market:        market.Name,
+				Team:          team,
+				Probability:   probability,
+				Price:         price,
+				KellyFraction: kelly,
+				Stake:         stakeFraction * options.Bankroll,
+			})
+		}
+	}
+
+	sort.Slice(stakes, func(i, j int) bool {
+		if stakes[i].Stake != stakes[j].Stake {
+			return stakes[i].Stake > stakes[j].Stake
+		}
+		return stakes[i].League+stakes[i].Market+stakes[i].Team < stakes[j].League+stakes[j].Market+stakes[j].Team
+	})
+
+	return stakes, nil
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // calcLeagueTable generates a league table from existing matches (adapted from go-outrights)
-func calcLeagueTable(teamNames []string, events []Event, handicaps map[string]int) []Team {
+func calcLeagueTable(teamNames []string, events []Event, handicaps map[string]int, tiebreaks []string) []Team {
 	teams := make(map[string]*Team)
 	
 	// Initialize teams
@@ -47,42 +47,57 @@ func calcLeagueTable(teamNames []string, events []Event, handicaps map[string]in
 		awayGoals := event.Score[1]
 		
 		// Calculate points
-		if homeGoals > awayGoals {
-			// Home team wins
-			teams[homeTeam].Points += 3
-		} else if homeGoals < awayGoals {
-			// Away team wins
-			teams[awayTeam].Points += 3
-		} else {
-			// Draw
-			teams[homeTeam].Points += 1
-			teams[awayTeam].Points += 1
-		}
+		homePoints, awayPoints := matchPoints(homeGoals, awayGoals)
+		teams[homeTeam].Points += homePoints
+		teams[awayTeam].Points += awayPoints
 		
-		// Update goal difference and games played
-		teams[homeTeam].GoalDifference += homeGoals - awayGoals
-		teams[awayTeam].GoalDifference += awayGoals - homeGoals
-		teams[homeTeam].Played += 1
-		teams[awayTeam].Played += 1
+		// Update results, goals and games played
+		recordResult(teams[homeTeam], homeGoals, awayGoals)
+		recordResult(teams[awayTeam], awayGoals, homeGoals)
 	}
 	
-	// Convert to slice and sort
-	result := make([]Team, 0, len(teams))
+	// Convert to slice (by name, so ties left by the chain are ordered the same every run) and sort
+	rows := make([]Team, 0, len(teams))
 	for _, team := range teams {
-		result = append(result, *team)
+		rows = append(rows, *team)
 	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
 	
-	// Sort by points (descending), then by goal difference (descending)
-	sort.Slice(result, func(i, j int) bool {
-		if result[i].Points == result[j].Points {
-			return result[i].GoalDifference > result[j].GoalDifference
-		}
-		return result[i].Points > result[j].Points
-	})
+	// Sort by points (descending), then by the tiebreak chain, as the simulator does
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	keys := tableKeys{rows: rows}
+	if containsTiebreak(tiebreaks, TiebreakHeadToHead) {
+		keys.headToHead = headToHeadTable(events)
+	}
 	
+	result := make([]Team, 0, len(rows))
+	for _, group := range rankStandings(order, tiebreaks, keys) {
+		for _, team := range group {
+			result = append(result, rows[team])
+		}
+	}
 	return result
 }
 
+// recordResult adds one played match to a team's standings fields (points are added separately)
+func recordResult(team *Team, goalsFor, goalsAgainst int) {
+	switch {
+	case goalsFor > goalsAgainst:
+		team.Won++
+	case goalsFor < goalsAgainst:
+		team.Lost++
+	default:
+		team.Drawn++
+	}
+	team.GoalsFor += goalsFor
+	team.GoalsAgainst += goalsAgainst
+	team.GoalDifference += goalsFor - goalsAgainst
+	team.Played++
+}
+
 // calcRemainingFixtures calculates what fixtures remain to be played (adapted from go-outrights)
 func calcRemainingFixtures(teamNames []string, events []Event, rounds int) []string {
 	// Count how many times each fixture has been played
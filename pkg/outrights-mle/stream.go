@@ -0,0 +1,64 @@
+package outrightsmle
+
+import "sync"
+
+// LeagueResult is one league's share of a MultiLeagueResult, emitted through
+// MLEOptions.OnLeagueResult as soon as the league's simulation completes
+type LeagueResult struct {
+	League        string                        `json:"league"`
+	Teams         []Team                        `json:"teams"`
+	MarkValues    map[string]map[string]float64 `json:"mark_values,omitempty"`     // market -> team -> mark_value
+	MarkStdErrors map[string]map[string]float64 `json:"mark_std_errors,omitempty"` // market -> team -> Monte Carlo standard error
+	PlaceValues   map[string]map[string]float64 `json:"place_values,omitempty"`    // each-way market -> team -> place probability
+	MarkOdds      map[string]map[string]float64 `json:"mark_odds,omitempty"`       // market -> team -> fair decimal odds (SimParams.DecimalOdds)
+	BookPrices    map[string]map[string]float64 `json:"book_prices,omitempty"`     // market -> team -> bookable price (SimParams.OutrightOverround)
+	EdgeReport    *EdgeReport                   `json:"edge_report,omitempty"`     // Marks vs offered prices (priced markets only)
+}
+
+// leagueResult derives a league's published outputs from its simulation outcome
+func (outcome *leagueOutcome) leagueResult(markets []Market, simParams *SimParams) LeagueResult {
+	result := LeagueResult{League: outcome.League, Teams: outcome.Teams}
+	if outcome.Marks == nil || len(outcome.Marks.Values) == 0 {
+		return result
+	}
+
+	result.MarkValues = outcome.Marks.Values
+	result.MarkStdErrors = outcome.Marks.StdErrors
+	if len(outcome.Marks.PlaceValues) > 0 {
+		result.PlaceValues = outcome.Marks.PlaceValues
+	}
+	if simParams.DecimalOdds {
+		result.MarkOdds = markDecimalOdds(outcome.Marks.Values, simParams.OddsProbabilityFloor)
+	}
+	if simParams.OutrightOverround > 0 {
+		result.BookPrices = marketBookPrices(outcome.Marks.Values, simParams)
+	}
+
+	reports := BuildEdgeReports(markets,
+		map[string]map[string]map[string]float64{outcome.League: result.MarkValues},
+		map[string]map[string]map[string]float64{outcome.League: result.PlaceValues})
+	if len(simParams.PriceLadder) > 0 {
+		snapEdgeReports(reports, simParams.PriceLadder)
+	}
+	if report, exists := reports[outcome.League]; exists {
+		result.EdgeReport = &report
+	}
+	return result
+}
+
+// simulateLeagues runs simulateLeague for each league in the worker pool; each completed league
+// is passed to options.OnLeagueResult (when set) before the remaining leagues finish
+// Emission is serialized, so the callback need not be safe for concurrent use
+func simulateLeagues(leagues []string, in *leagueSimInputs) []*leagueOutcome {
+	var emitMu sync.Mutex
+	return runLeagueWorkers(leagues, in.options.Workers, func(league string) *leagueOutcome {
+		outcome := simulateLeague(league, in)
+		outcome.Result = outcome.leagueResult(in.markets, in.options.SimParams)
+		if in.options.OnLeagueResult != nil {
+			emitMu.Lock()
+			defer emitMu.Unlock()
+			in.options.OnLeagueResult(outcome.Result)
+		}
+		return outcome
+	})
+}
@@ -1,20 +1,22 @@
 package outrightsmle
 
+import (
+	"math"
+	"sort"
+)
 
 // SeasonPointsResult contains both expected points and the simulation used to calculate them
 type SeasonPointsResult struct {
-	ExpectedPoints map[string]float64
-	SimPoints      *SimPoints
+	ExpectedPoints       map[string]float64
+	ExpectedGoalsFor     map[string]float64 // Mean season goals scored (played plus simulated)
+	ExpectedGoalsAgainst map[string]float64 // Mean season goals conceded (played plus simulated)
+	CleanSheets          map[string]*CleanSheetForecast
+	SimPoints            *SimPoints
+	Fixtures             int // Number of remaining fixtures simulated
 }
 
-// calculateLeagueSeasonPointsWithSim calculates expected points using realistic fixture approach
-// Returns both expected points and SimPoints for reuse in mark calculations
-func calculateLeagueSeasonPointsWithSim(teamNames []string, params MLEParams, simParams *SimParams, 
-	allEvents []MatchResult, league string, currentSeason string, handicaps map[string]int) *SeasonPointsResult {
-	
-	// Use SimParams for simulation paths
-	nPaths := simParams.SimulationPaths
-	
+// leagueSeasonEvents returns a league's current-season matches as events
+func leagueSeasonEvents(allEvents []MatchResult, league string, currentSeason string) []Event {
 	// Filter events for this league and current season
 	var leagueEvents []MatchResult
 	for _, event := range allEvents {
@@ -24,10 +26,29 @@ func calculateLeagueSeasonPointsWithSim(teamNames []string, params MLEParams, si
 	}
 	
 	// Convert to Event format for compatibility with go-outrights functions
-	events := convertMatchResultsToEvents(leagueEvents, currentSeason)
+	return convertMatchResultsToEvents(leagueEvents, currentSeason)
+}
+
+// calculateLeagueSeasonPointsWithSim calculates expected points using realistic fixture approach
+// Returns both expected points and SimPoints for reuse in mark calculations
+// Fixtures in fixed are simulated with their outcome pinned
+func calculateLeagueSeasonPointsWithSim(teamNames []string, params MLEParams, simParams *SimParams, 
+	allEvents []MatchResult, league string, currentSeason string, handicaps map[string]int, 
+	fixed map[string]*FixedResult) *SeasonPointsResult {
+	
+	// Use SimParams for simulation paths
+	nPaths := simParams.SimulationPaths
+	
+	// Fix the team (and so fixture) order, so a seeded simulation draws the same stream every run
+	teamNames = append([]string(nil), teamNames...)
+	sort.Strings(teamNames)
+	
+	// Current season events for this league
+	events := leagueSeasonEvents(allEvents, league, currentSeason)
 	
 	// Calculate current league table from existing matches
-	leagueTable := calcLeagueTable(teamNames, events, handicaps)
+	tiebreaks := leagueTiebreaks(simParams.Tiebreaks, league)
+	leagueTable := calcLeagueTable(teamNames, events, handicaps, tiebreaks)
 	
 	// Calculate remaining fixtures based on what's been played
 	rounds := getRounds(league)
@@ -35,8 +56,15 @@ func calculateLeagueSeasonPointsWithSim(teamNames []string, params MLEParams, si
 	
 	// Initialize simulation points tracker with current league table
 	simPoints := newSimPointsFromLeagueTable(leagueTable, nPaths)
+	simPoints.deadHeat = !simParams.DisableDeadHeat
+	simPoints.tiebreaks = tiebreaks
+	if containsTiebreak(tiebreaks, TiebreakHeadToHead) {
+		simPoints.headToHead = headToHeadMatrix(simPoints.TeamNames, events)
+	}
+	simPoints.rng = newSimulationRand(simParams.Seed, league)
 	
-	// Create a temporary solver for simulation with SimParams
+	// Create a temporary solver for simulation with SimParams, at the league's scoring level
+	params = params.forLeague(league)
 	solver := &MLESolver{
 		params:  &params,
 		options: MLEOptions{SimParams: simParams},
@@ -46,23 +74,33 @@ func calculateLeagueSeasonPointsWithSim(teamNames []string, params MLEParams, si
 	for _, fixtureName := range remainingFixtures {
 		homeTeam, awayTeam := parseEventName(fixtureName)
 		if homeTeam != "" && awayTeam != "" {
-			simPoints.simulate(homeTeam, awayTeam, solver)
+			simPoints.simulate(homeTeam, awayTeam, solver, fixed[fixtureName])
 		}
 	}
 	
-	// Calculate expected total season points (current + simulated remaining)
+	// Calculate expected total season points and goals (current + simulated remaining)
 	expectedPoints := make(map[string]float64)
+	expectedGoalsFor := make(map[string]float64)
+	expectedGoalsAgainst := make(map[string]float64)
 	for i, team := range leagueTable {
-		total := 0
+		total, goalsFor, goalsAgainst := 0, 0, 0
 		for path := 0; path < nPaths; path++ {
-			total += simPoints.Points[i][path]
+			total += simPoints.Points(i, path)
+			goalsFor += simPoints.GoalsFor(i, path)
+			goalsAgainst += simPoints.GoalsAgainst(i, path)
 		}
 		expectedPoints[team.Name] = float64(total) / float64(nPaths)
+		expectedGoalsFor[team.Name] = float64(goalsFor) / float64(nPaths)
+		expectedGoalsAgainst[team.Name] = float64(goalsAgainst) / float64(nPaths)
 	}
 	
 	return &SeasonPointsResult{
-		ExpectedPoints: expectedPoints,
-		SimPoints:      simPoints,
+		ExpectedPoints:       expectedPoints,
+		ExpectedGoalsFor:     expectedGoalsFor,
+		ExpectedGoalsAgainst: expectedGoalsAgainst,
+		CleanSheets:          calculateCleanSheets(teamNames, params, simParams, events, remainingFixtures, fixed),
+		SimPoints:            simPoints,
+		Fixtures:             len(remainingFixtures),
 	}
 }
 
@@ -70,38 +108,116 @@ func calculateLeagueSeasonPointsWithSim(teamNames []string, params MLEParams, si
 // Wrapper for backward compatibility
 func calculateLeagueSeasonPoints(teamNames []string, params MLEParams, simParams *SimParams, 
 	allEvents []MatchResult, league string, currentSeason string, handicaps map[string]int) map[string]float64 {
-	result := calculateLeagueSeasonPointsWithSim(teamNames, params, simParams, allEvents, league, currentSeason, handicaps)
+	result := calculateLeagueSeasonPointsWithSim(teamNames, params, simParams, allEvents, league, currentSeason, handicaps, nil)
 	return result.ExpectedPoints
 }
 
 // newSimPointsFromLeagueTable initializes SimPoints with current league table points (adapted from go-outrights)
+// The table's totals are kept once per team and added at read time, so paths store only deltas
 func newSimPointsFromLeagueTable(leagueTable []Team, nPaths int) *SimPoints {
-	sp := &SimPoints{
-		NPaths:         nPaths,
-		TeamNames:      make([]string, len(leagueTable)),
-		Points:         make([][]int, len(leagueTable)),
-		GoalDifference: make([][]int, len(leagueTable)),
-		positionCache:  make(map[string]map[string][]float64),
+	teamNames := make([]string, len(leagueTable))
+	for i, team := range leagueTable {
+		teamNames[i] = team.Name
 	}
+	sp := newSimPoints(teamNames, nPaths)
 	
 	for i, team := range leagueTable {
-		sp.TeamNames[i] = team.Name
-		sp.Points[i] = make([]int, nPaths)
-		sp.GoalDifference[i] = make([]int, nPaths)
-		
-		// Initialize with current league table data (points and goal difference separately)
-		currentPoints := team.Points
-		currentGoalDiff := team.GoalDifference
-		
-		for j := 0; j < nPaths; j++ {
-			sp.Points[i][j] = currentPoints
-			sp.GoalDifference[i][j] = currentGoalDiff
-		}
+		sp.basePoints[i] = team.Points
+		sp.baseGoalsFor[i] = team.GoalsFor
+		sp.baseGoalsAgainst[i] = team.GoalsFor - team.GoalDifference
 	}
 	
 	return sp
 }
 
+// positionStatistics returns the mean, median and modal finishing position (1-based) from position probabilities
+func positionStatistics(probabilities []float64) (float64, int, int) {
+	mean, cumulative := 0.0, 0.0
+	median, mode := 0, 0
+	for i, probability := range probabilities {
+		position := i + 1
+		mean += float64(position) * probability
+		cumulative += probability
+		if median == 0 && cumulative >= 0.5 {
+			median = position
+		}
+		if mode == 0 || probability > probabilities[mode-1] {
+			mode = position
+		}
+	}
+	if median == 0 {
+		median = len(probabilities)
+	}
+	return mean, median, mode
+}
+
+// defaultFormWindow is the number of recent matches used for form when SimParams.FormWindow is unset
+const defaultFormWindow = 6
+
+// averageOpponentGoals returns a team's expected goals against the mean ratings of the other
+// league teams, home and away. Nil when the team has no rating or plays alone
+func averageOpponentGoals(team string, leagueTeams []string, params MLEParams) *AverageOpponentGoals {
+	attack, rated := params.AttackRatings[team]
+	if !rated {
+		return nil
+	}
+	defense := params.DefenseRatings[team]
+
+	opponentAttack, opponentDefense, opponents := 0.0, 0.0, 0
+	for _, other := range leagueTeams {
+		if other == team {
+			continue
+		}
+		if otherAttack, exists := params.AttackRatings[other]; exists {
+			opponentAttack += otherAttack
+			opponentDefense += params.DefenseRatings[other]
+			opponents++
+		}
+	}
+	if opponents == 0 {
+		return nil
+	}
+	opponentAttack /= float64(opponents)
+	opponentDefense /= float64(opponents)
+
+	model := params.countModel()
+	goals := &AverageOpponentGoals{
+		HomeFor:     model.Mean(math.Exp(params.Intercept + attack - opponentDefense + params.HomeAdvantage)),
+		HomeAgainst: model.Mean(math.Exp(params.Intercept + opponentAttack - defense)),
+		AwayFor:     model.Mean(math.Exp(params.Intercept + attack - opponentDefense)),
+		AwayAgainst: model.Mean(math.Exp(params.Intercept + opponentAttack - defense + params.HomeAdvantage)),
+	}
+	goals.GoalsAboveAverage = (goals.HomeFor - goals.HomeAgainst + goals.AwayFor - goals.AwayAgainst) / 2
+	return goals
+}
+
+// calculateTeamForm summarizes a team's recent results in the given season's matches (any league)
+// Results are ordered oldest to newest, so the most recent match is rightmost
+func calculateTeamForm(team string, matches []MatchResult, season string, window int) *TeamForm {
+	if window <= 0 {
+		window = defaultFormWindow
+	}
+	
+	seasonMatches := QueryMatches(matches).BySeason(season).ByTeam(team)
+	seasonStats := seasonMatches.Stats()
+	if seasonStats.Matches == 0 {
+		return nil
+	}
+	recent := seasonMatches.Limit(window).Stats()
+	
+	n := float64(recent.Matches)
+	seasonN := float64(seasonStats.Matches)
+	return &TeamForm{
+		Results:             recent.Results,
+		Matches:             recent.Matches,
+		PointsPerGame:       recent.PointsPerGame,
+		GoalsForPerGame:     float64(recent.GoalsFor) / n,
+		GoalsAgainstPerGame: float64(recent.GoalsAgainst) / n,
+		GoalsForTrend:       float64(recent.GoalsFor)/n - float64(seasonStats.GoalsFor)/seasonN,
+		GoalsAgainstTrend:   float64(recent.GoalsAgainst)/n - float64(seasonStats.GoalsAgainst)/seasonN,
+	}
+}
+
 // Additional team metrics functions can be added here in the future:
 // - calculateExpectedGoals()
 // - calculateWinProbabilities()  
@@ -0,0 +1,286 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tiebreak criteria, applied in order to teams level on points
+const (
+	TiebreakGoalDifference = "goal_difference"
+	TiebreakGoalsFor       = "goals_for"
+	TiebreakHeadToHead     = "head_to_head" // Points in the matches between the teams still level
+	TiebreakAlphabetical   = "alphabetical"
+)
+
+// defaultTiebreaks is the chain for leagues without an entry in SimParams.Tiebreaks
+var defaultTiebreaks = []string{TiebreakGoalDifference}
+
+// leagueTiebreaks returns the tiebreak chain configured for a league
+func leagueTiebreaks(tiebreaks map[string][]string, league string) []string {
+	if chain, exists := tiebreaks[league]; exists {
+		return chain
+	}
+	return defaultTiebreaks
+}
+
+// validateTiebreaks checks every configured chain uses known criteria
+func validateTiebreaks(tiebreaks map[string][]string) error {
+	for league, chain := range tiebreaks {
+		for _, tiebreak := range chain {
+			switch tiebreak {
+			case TiebreakGoalDifference, TiebreakGoalsFor, TiebreakHeadToHead, TiebreakAlphabetical:
+			default:
+				return fmt.Errorf("league %s has unknown tiebreak %q", league, tiebreak)
+			}
+		}
+	}
+	return nil
+}
+
+// standingsKeys supplies the ranking criteria of one league table or one simulation path
+// Teams are identified by their position in the slice being ranked
+type standingsKeys interface {
+	points(team int) int
+	goalDifference(team int) int
+	goalsFor(team int) int
+	headToHeadPoints(team int, group []int) int // Points earned against the other teams in group
+	name(team int) string
+}
+
+// rankStandings orders teams by points and then by each tiebreak in turn
+// Returns groups in finishing order; a group holds more than one team only when they are level
+// on every criterion (alphabetical order always separates teams)
+func rankStandings(teams []int, tiebreaks []string, keys standingsKeys) [][]int {
+	groups := splitByKey(teams, keys.points)
+	for _, tiebreak := range tiebreaks {
+		refined := make([][]int, 0, len(teams))
+		for _, group := range groups {
+			if len(group) == 1 {
+				refined = append(refined, group)
+				continue
+			}
+			switch tiebreak {
+			case TiebreakGoalDifference:
+				refined = append(refined, splitByKey(group, keys.goalDifference)...)
+			case TiebreakGoalsFor:
+				refined = append(refined, splitByKey(group, keys.goalsFor)...)
+			case TiebreakHeadToHead:
+				// The mini-league is between the teams still level at this point in the chain
+				tied := group
+				refined = append(refined, splitByKey(group, func(team int) int { return keys.headToHeadPoints(team, tied) })...)
+			case TiebreakAlphabetical:
+				sorted := append([]int(nil), group...)
+				sort.SliceStable(sorted, func(i, j int) bool { return keys.name(sorted[i]) < keys.name(sorted[j]) })
+				for i := range sorted {
+					refined = append(refined, sorted[i:i+1])
+				}
+			}
+		}
+		groups = refined
+	}
+	return groups
+}
+
+// countingSortRange is the widest spread of key values splitByKey counting-sorts; points, goal
+// differences and goals in one table or path fall well inside it, and the bucket counts then
+// fit on the stack
+const countingSortRange = 256
+
+// splitByKey sorts teams by key (descending, keeping input order among equals) and splits them
+// into runs of equal key
+// Keys span a narrow range, so they are counting-sorted into buckets, with a stable comparison
+// sort only for a wider spread
+func splitByKey(teams []int, key func(team int) int) [][]int {
+	scratch := make([]int, 2*len(teams))
+	values, sorted := scratch[:len(teams)], scratch[len(teams):]
+	low, high := 0, 0
+	for i, team := range teams {
+		values[i] = key(team)
+		if i == 0 || values[i] < low {
+			low = values[i]
+		}
+		if i == 0 || values[i] > high {
+			high = values[i]
+		}
+	}
+	if high-low >= countingSortRange {
+		return splitByKeySorted(teams, values)
+	}
+
+	// Buckets run from the highest value down and each fills in input order; the counts become
+	// each bucket's next free position, which leaves them at the bucket's end
+	var buckets [countingSortRange]int
+	positions := buckets[:high-low+1]
+	for _, value := range values {
+		positions[high-value]++
+	}
+	for bucket, start := 0, 0; bucket < len(positions); bucket++ {
+		start, positions[bucket] = start+positions[bucket], start
+	}
+	for i, team := range teams {
+		bucket := high - values[i]
+		sorted[positions[bucket]] = team
+		positions[bucket]++
+	}
+
+	groups := make([][]int, 0, len(teams))
+	for bucket, start := 0, 0; bucket < len(positions); bucket++ {
+		if end := positions[bucket]; end > start {
+			groups = append(groups, sorted[start:end])
+			start = end
+		}
+	}
+	return groups
+}
+
+// splitByKeySorted is splitByKey by stable comparison sort, given each team's key value
+func splitByKeySorted(teams []int, values []int) [][]int {
+	type keyed struct{ team, value int }
+	entries := make([]keyed, len(teams))
+	for i, team := range teams {
+		entries[i] = keyed{team, values[i]}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].value > entries[j].value })
+
+	sorted := make([]int, len(entries))
+	for i, entry := range entries {
+		sorted[i] = entry.team
+	}
+	var groups [][]int
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].value == entries[start].value {
+			end++
+		}
+		groups = append(groups, sorted[start:end])
+		start = end
+	}
+	return groups
+}
+
+// containsTiebreak reports whether a chain uses the given criterion
+func containsTiebreak(tiebreaks []string, tiebreak string) bool {
+	for _, candidate := range tiebreaks {
+		if candidate == tiebreak {
+			return true
+		}
+	}
+	return false
+}
+
+// tableKeys ranks a league table; headToHead holds points earned by team against opponent
+type tableKeys struct {
+	rows       []Team
+	headToHead map[string]map[string]int
+}
+
+func (k tableKeys) points(team int) int         { return k.rows[team].Points }
+func (k tableKeys) goalDifference(team int) int { return k.rows[team].GoalDifference }
+func (k tableKeys) goalsFor(team int) int       { return k.rows[team].GoalsFor }
+func (k tableKeys) name(team int) string        { return k.rows[team].Name }
+
+func (k tableKeys) headToHeadPoints(team int, group []int) int {
+	total := 0
+	for _, opponent := range group {
+		total += k.headToHead[k.rows[team].Name][k.rows[opponent].Name]
+	}
+	return total
+}
+
+// pathKeys ranks the selected teams of a SimPoints on one simulation path
+type pathKeys struct {
+	sp       *SimPoints
+	selected []int
+	path     int
+}
+
+func (k pathKeys) points(team int) int         { return k.sp.Points(k.selected[team], k.path) }
+func (k pathKeys) goalDifference(team int) int { return k.sp.GoalDifference(k.selected[team], k.path) }
+func (k pathKeys) goalsFor(team int) int       { return k.sp.GoalsFor(k.selected[team], k.path) }
+func (k pathKeys) name(team int) string        { return k.sp.TeamNames[k.selected[team]] }
+
+// headToHeadPoints adds the played head-to-head points to those simulated on this path
+func (k pathKeys) headToHeadPoints(team int, group []int) int {
+	self := k.selected[team]
+	opponents := make(map[int]bool, len(group))
+	total := 0
+	for _, other := range group {
+		opponent := k.selected[other]
+		if opponent == self {
+			continue
+		}
+		opponents[opponent] = true
+		if k.sp.headToHead != nil {
+			total += k.sp.headToHead[self][opponent]
+		}
+	}
+
+	for fixture, pair := range k.sp.fixtureTeams {
+		home, away := pair[0], pair[1]
+		if home == self && opponents[away] {
+			total += outcomePoints(k.sp.outcomes[fixture][k.path], true)
+		} else if away == self && opponents[home] {
+			total += outcomePoints(k.sp.outcomes[fixture][k.path], false)
+		}
+	}
+	return total
+}
+
+// outcomePoints returns the points the home or away side earned from a simulated outcome
+func outcomePoints(outcome int8, home bool) int {
+	switch {
+	case outcome == outcomeDraw:
+		return 1
+	case (outcome == outcomeHome) == home:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// headToHeadTable returns points earned by each team against each opponent in played events
+func headToHeadTable(events []Event) map[string]map[string]int {
+	headToHead := make(map[string]map[string]int)
+	add := func(team, opponent string, points int) {
+		if headToHead[team] == nil {
+			headToHead[team] = make(map[string]int)
+		}
+		headToHead[team][opponent] += points
+	}
+	for _, event := range events {
+		if len(event.Score) != 2 {
+			continue
+		}
+		homeTeam, awayTeam := parseEventName(event.Name)
+		homePoints, awayPoints := matchPoints(event.Score[0], event.Score[1])
+		add(homeTeam, awayTeam, homePoints)
+		add(awayTeam, homeTeam, awayPoints)
+	}
+	return headToHead
+}
+
+// headToHeadMatrix indexes headToHeadTable by position in teamNames, for simulation paths
+func headToHeadMatrix(teamNames []string, events []Event) [][]int {
+	table := headToHeadTable(events)
+	matrix := make([][]int, len(teamNames))
+	for i, team := range teamNames {
+		matrix[i] = make([]int, len(teamNames))
+		for j, opponent := range teamNames {
+			matrix[i][j] = table[team][opponent]
+		}
+	}
+	return matrix
+}
+
+// matchPoints returns league points (3/1/0) for a scoreline
+func matchPoints(homeGoals, awayGoals int) (int, int) {
+	switch {
+	case homeGoals > awayGoals:
+		return 3, 0
+	case homeGoals < awayGoals:
+		return 0, 3
+	default:
+		return 1, 1
+	}
+}
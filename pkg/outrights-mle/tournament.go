@@ -0,0 +1,478 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Tournament describes a group stage followed by a single-elimination knockout bracket,
+// e.g. the Euros or the World Cup
+type Tournament struct {
+	Name       string              `json:"name"`                  // Mixed into the simulation seed
+	Groups     map[string][]string `json:"groups"`                // Group name -> teams, from the draw
+	Qualifiers int                 `json:"qualifiers"`            // Teams per group that go through (default: 2)
+	BestPlaced int                 `json:"best_placed,omitempty"` // Best teams in the next place across groups that also go through (e.g. 4 third-placed teams at the Euros)
+	Tiebreaks  []string            `json:"tiebreaks,omitempty"`   // Group tiebreak chain after points (default: goal_difference, goals_for); remaining ties are drawn by lot
+	Bracket    []string            `json:"bracket,omitempty"`     // First knockout round slots, paired in order (see tournamentSlots)
+	HostTeams  []string            `json:"host_teams,omitempty"`  // Teams with home advantage against non-hosts; other matches are neutral
+}
+
+// TournamentResult holds the probabilities from a tournament simulation
+type TournamentResult struct {
+	Winner        map[string]float64              `json:"winner"`         // team -> probability of winning the tournament
+	GroupWinner   map[string]map[string]float64   `json:"group_winner"`   // group -> team -> probability of topping the group
+	GroupPosition map[string]map[string][]float64 `json:"group_position"` // group -> team -> probability of each finishing place
+	Qualify       map[string]float64              `json:"qualify"`        // team -> probability of reaching the knockout stage
+	Reach         map[string]map[string]float64   `json:"reach"`          // knockout round (e.g. "Quarter-final") -> team -> probability of playing in it
+	Rounds        []string                        `json:"rounds"`         // Knockout rounds in order, keys of Reach
+	Paths         int                             `json:"paths"`
+}
+
+// defaultTournamentTiebreaks is the group chain used when Tournament.Tiebreaks is empty
+var defaultTournamentTiebreaks = []string{TiebreakGoalDifference, TiebreakGoalsFor}
+
+// SimulateTournament simulates a tournament with fitted ratings, e.g. from OptimizeRatings on
+// international matches. Each group plays a single round robin; the group tables, best placed
+// teams and bracket are resolved on every path. A knockout draw after 90 minutes goes to extra
+// time (a third of the expected goals) and then to penalties, won by either side with equal
+// probability. Uses DefaultSimParams if simParams is nil (SimulationPaths and Seed apply)
+func SimulateTournament(params MLEParams, simParams *SimParams, tournament Tournament) (*TournamentResult, error) {
+	if simParams == nil {
+		simParams = DefaultSimParams()
+	}
+	if simParams.SimulationPaths < 1 {
+		return nil, fmt.Errorf("simulation paths must be at least 1, got %d", simParams.SimulationPaths)
+	}
+	if tournament.Qualifiers == 0 {
+		tournament.Qualifiers = 2
+	}
+	if len(tournament.Tiebreaks) == 0 {
+		tournament.Tiebreaks = defaultTournamentTiebreaks
+	}
+	if err := validateTiebreaks(map[string][]string{tournament.Name: tournament.Tiebreaks}); err != nil {
+		return nil, err
+	}
+
+	params = params.forLeague("") // League intercepts, if any, go into the ratings
+	groupNames, teams, err := tournamentTeams(params, tournament)
+	if err != nil {
+		return nil, err
+	}
+	slots := tournament.Bracket
+	if len(slots) == 0 {
+		if slots, err = defaultBracket(groupNames, tournament); err != nil {
+			return nil, err
+		}
+	}
+	eligible := 0
+	for _, group := range groupNames {
+		if len(tournament.Groups[group]) > tournament.Qualifiers {
+			eligible++
+		}
+	}
+	if tournament.BestPlaced < 0 || tournament.BestPlaced > eligible {
+		return nil, fmt.Errorf("tournament %s best placed must be 0-%d (groups with a team below the qualifiers), got %d", tournament.Name, eligible, tournament.BestPlaced)
+	}
+	bracket, err := tournamentSlots(slots, groupNames, tournament)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := newTournamentSim(params, simParams, tournament, teams)
+	nPaths := simParams.SimulationPaths
+	var rounds []string
+	for size := len(bracket); size >= 2; size /= 2 {
+		rounds = append(rounds, knockoutRoundName(size))
+	}
+
+	positionCounts := make([][]int, len(teams))
+	for i := range positionCounts {
+		positionCounts[i] = make([]int, len(tournament.Groups[teams[i].group]))
+	}
+	qualifyCounts := make([]int, len(teams))
+	reachCounts := make([][]int, len(rounds))
+	for i := range reachCounts {
+		reachCounts[i] = make([]int, len(teams))
+	}
+	winnerCounts := make([]int, len(teams))
+
+	groupMembers := make([][]int, len(groupNames))
+	for i, team := range teams {
+		groupMembers[team.groupIndex] = append(groupMembers[team.groupIndex], i)
+	}
+
+	for path := 0; path < nPaths; path++ {
+		standings := make([][]int, len(groupNames)) // group -> team indices in finishing order
+		for g, members := range groupMembers {
+			standings[g] = sim.playGroup(members)
+			for place, team := range standings[g] {
+				positionCounts[team][place]++
+			}
+		}
+		bestPlaced := sim.rankBestPlaced(standings, tournament)
+
+		field := make([]int, len(bracket))
+		for i, slot := range bracket {
+			if slot.group >= 0 {
+				field[i] = standings[slot.group][slot.position]
+			} else {
+				field[i] = bestPlaced[slot.position]
+			}
+			qualifyCounts[field[i]]++
+		}
+		for round := range rounds {
+			next := make([]int, 0, len(field)/2)
+			for i := 0; i < len(field); i += 2 {
+				reachCounts[round][field[i]]++
+				reachCounts[round][field[i+1]]++
+				next = append(next, sim.playKnockout(field[i], field[i+1]))
+			}
+			field = next
+		}
+		winnerCounts[field[0]]++
+	}
+
+	paths := float64(nPaths)
+	result := &TournamentResult{
+		Winner:        make(map[string]float64, len(teams)),
+		GroupWinner:   make(map[string]map[string]float64, len(groupNames)),
+		GroupPosition: make(map[string]map[string][]float64, len(groupNames)),
+		Qualify:       make(map[string]float64, len(teams)),
+		Reach:         make(map[string]map[string]float64, len(rounds)),
+		Rounds:        rounds,
+		Paths:         nPaths,
+	}
+	for _, group := range groupNames {
+		result.GroupWinner[group] = make(map[string]float64)
+		result.GroupPosition[group] = make(map[string][]float64)
+	}
+	for _, round := range rounds {
+		result.Reach[round] = make(map[string]float64, len(teams))
+	}
+	for i, team := range teams {
+		result.Winner[team.name] = float64(winnerCounts[i]) / paths
+		result.Qualify[team.name] = float64(qualifyCounts[i]) / paths
+		positions := make([]float64, len(positionCounts[i]))
+		for place, count := range positionCounts[i] {
+			positions[place] = float64(count) / paths
+		}
+		result.GroupPosition[team.group][team.name] = positions
+		result.GroupWinner[team.group][team.name] = positions[0]
+		for round, name := range rounds {
+			result.Reach[name][team.name] = float64(reachCounts[round][i]) / paths
+		}
+	}
+	return result, nil
+}
+
+// tournamentTeam is one entrant and its group
+type tournamentTeam struct {
+	name       string
+	group      string
+	groupIndex int
+}
+
+// tournamentTeams lists the entrants with their groups (in sorted group order) and checks each
+// is rated and drawn once
+func tournamentTeams(params MLEParams, tournament Tournament) ([]string, []tournamentTeam, error) {
+	if len(tournament.Groups) == 0 {
+		return nil, nil, fmt.Errorf("tournament %s has no groups", tournament.Name)
+	}
+	groupNames := sortedKeys(tournament.Groups)
+	seen := make(map[string]string)
+	var teams []tournamentTeam
+	for g, group := range groupNames {
+		members := tournament.Groups[group]
+		if len(members) < 2 {
+			return nil, nil, fmt.Errorf("tournament %s group %s needs at least 2 teams, got %d", tournament.Name, group, len(members))
+		}
+		if tournament.Qualifiers < 1 || tournament.Qualifiers > len(members) {
+			return nil, nil, fmt.Errorf("tournament %s group %s has %d teams, cannot send %d through", tournament.Name, group, len(members), tournament.Qualifiers)
+		}
+		for _, team := range members {
+			if other, exists := seen[team]; exists {
+				return nil, nil, fmt.Errorf("tournament %s draws %s in groups %s and %s", tournament.Name, team, other, group)
+			}
+			if _, exists := params.AttackRatings[team]; !exists {
+				return nil, nil, &UnknownTeamError{Team: team, Context: "tournament " + tournament.Name}
+			}
+			seen[team] = group
+			teams = append(teams, tournamentTeam{name: team, group: group, groupIndex: g})
+		}
+	}
+	return groupNames, teams, nil
+}
+
+// bracketSlot is a resolved slot label: a group place, or (group -1) a best placed rank
+type bracketSlot struct {
+	group    int
+	position int // 0-based place in the group, or rank among the best placed
+}
+
+// tournamentSlots resolves bracket labels: "{group}{place}" (e.g. "A1" for the winner of group A)
+// or "#{rank}" for the rank-th best team finishing just below the qualifiers across groups
+// Every qualifier must fill exactly one slot, and the slot count must be a power of two
+func tournamentSlots(labels []string, groupNames []string, tournament Tournament) ([]bracketSlot, error) {
+	expected := len(groupNames)*tournament.Qualifiers + tournament.BestPlaced
+	if len(labels) != expected {
+		return nil, fmt.Errorf("tournament %s bracket has %d slots, expected %d qualifiers", tournament.Name, len(labels), expected)
+	}
+	if expected < 2 || expected&(expected-1) != 0 {
+		return nil, fmt.Errorf("tournament %s knockout needs a power of two teams, got %d", tournament.Name, expected)
+	}
+
+	groupIndex := make(map[string]int, len(groupNames))
+	for i, group := range groupNames {
+		groupIndex[group] = i
+	}
+	used := make(map[string]bool, len(labels))
+	slots := make([]bracketSlot, len(labels))
+	for i, label := range labels {
+		if used[label] {
+			return nil, fmt.Errorf("tournament %s bracket uses slot %s twice", tournament.Name, label)
+		}
+		used[label] = true
+
+		if rank, ok := strings.CutPrefix(label, "#"); ok {
+			n, err := strconv.Atoi(rank)
+			if err != nil || n < 1 || n > tournament.BestPlaced {
+				return nil, fmt.Errorf("tournament %s bracket slot %s: best placed rank must be 1-%d", tournament.Name, label, tournament.BestPlaced)
+			}
+			slots[i] = bracketSlot{group: -1, position: n - 1}
+			continue
+		}
+		split := strings.LastIndexFunc(label, func(r rune) bool { return r < '0' || r > '9' }) + 1
+		place, err := strconv.Atoi(label[split:])
+		g, exists := groupIndex[label[:split]]
+		if err != nil || !exists || place < 1 || place > tournament.Qualifiers {
+			return nil, fmt.Errorf("tournament %s bracket slot %s is not a qualifying group place", tournament.Name, label)
+		}
+		slots[i] = bracketSlot{group: g, position: place - 1}
+	}
+	return slots, nil
+}
+
+// defaultBracket pairs groups in sorted order when no bracket is given: with two qualifiers,
+// A1-B2, C1-D2, ... then B1-A2, D1-C2, ..., so group winners from paired groups meet no earlier
+// than the final stages; with one qualifier the winners meet in group order
+func defaultBracket(groupNames []string, tournament Tournament) ([]string, error) {
+	if tournament.BestPlaced > 0 {
+		return nil, fmt.Errorf("tournament %s sends best placed teams through, so needs a bracket", tournament.Name)
+	}
+	switch tournament.Qualifiers {
+	case 1:
+		labels := make([]string, len(groupNames))
+		for i, group := range groupNames {
+			labels[i] = group + "1"
+		}
+		return labels, nil
+	case 2:
+		if len(groupNames)%2 != 0 {
+			return nil, fmt.Errorf("tournament %s has an odd number of groups, so needs a bracket", tournament.Name)
+		}
+		var first, second []string
+		for i := 0; i < len(groupNames); i += 2 {
+			a, b := groupNames[i], groupNames[i+1]
+			first = append(first, a+"1", b+"2")
+			second = append(second, b+"1", a+"2")
+		}
+		return append(first, second...), nil
+	default:
+		return nil, fmt.Errorf("tournament %s sends %d teams through per group, so needs a bracket", tournament.Name, tournament.Qualifiers)
+	}
+}
+
+// knockoutRoundName names a knockout round by the number of teams in it
+func knockoutRoundName(teams int) string {
+	switch teams {
+	case 2:
+		return "Final"
+	case 4:
+		return "Semi-final"
+	case 8:
+		return "Quarter-final"
+	default:
+		return fmt.Sprintf("Round of %d", teams)
+	}
+}
+
+// tournamentSim plays matches between entrants, indexed by position in the teams slice
+type tournamentSim struct {
+	names         []string
+	attack        []float64
+	defense       []float64
+	host          []bool
+	homeAdvantage float64
+	tiebreaks     []string
+	rng           *rand.Rand
+	simParams     *SimParams
+	model         CountModel                          // Goals follow the params' count model
+	samplers      map[[3]int]func(rng randSource) int // team, opponent, extra time -> goal sampler
+
+	// Per-path group state, indexed by team
+	points, goalDifference, goalsFor []int
+	headToHead                       [][]int // team -> opponent -> points earned
+}
+
+func newTournamentSim(params MLEParams, simParams *SimParams, tournament Tournament, teams []tournamentTeam) *tournamentSim {
+	hosts := make(map[string]bool, len(tournament.HostTeams))
+	for _, team := range tournament.HostTeams {
+		hosts[team] = true
+	}
+	sim := &tournamentSim{
+		names:          make([]string, len(teams)),
+		attack:         make([]float64, len(teams)),
+		defense:        make([]float64, len(teams)),
+		host:           make([]bool, len(teams)),
+		homeAdvantage:  params.HomeAdvantage,
+		tiebreaks:      tournament.Tiebreaks,
+		rng:            newSimulationRand(simParams.Seed, tournament.Name),
+		simParams:      simParams,
+		model:          params.countModel(),
+		samplers:       make(map[[3]int]func(rng randSource) int),
+		points:         make([]int, len(teams)),
+		goalDifference: make([]int, len(teams)),
+		goalsFor:       make([]int, len(teams)),
+		headToHead:     make([][]int, len(teams)),
+	}
+	for i, team := range teams {
+		sim.headToHead[i] = make([]int, len(teams))
+		sim.names[i] = team.name
+		sim.attack[i] = params.Intercept + params.AttackRatings[team.name] // Intercept folded into attack
+		sim.defense[i] = params.DefenseRatings[team.name]
+		sim.host[i] = hosts[team.name]
+	}
+	return sim
+}
+
+// expectedGoals returns the scoring rates for a match; a host facing a non-host is the home side
+func (sim *tournamentSim) expectedGoals(home, away int) (float64, float64) {
+	homeAdvantage, awayAdvantage := 0.0, 0.0
+	if sim.host[home] && !sim.host[away] {
+		homeAdvantage = sim.homeAdvantage
+	} else if sim.host[away] && !sim.host[home] {
+		awayAdvantage = sim.homeAdvantage
+	}
+	return cappedLambda(math.Exp(sim.attack[home]-sim.defense[away]+homeAdvantage), sim.simParams),
+		cappedLambda(math.Exp(sim.attack[away]-sim.defense[home]+awayAdvantage), sim.simParams)
+}
+
+// goals samples the goals team scores against opponent, over extra time if set (a third of the
+// expected goals); samplers are built once per pairing, as the non-Poisson ones tabulate
+func (sim *tournamentSim) goals(team, opponent int, lambda float64, extraTime bool) int {
+	key := [3]int{team, opponent, 0}
+	if extraTime {
+		key[2], lambda = 1, lambda/3
+	}
+	sampler, exists := sim.samplers[key]
+	if !exists {
+		sampler = goalSampler(sim.model, lambda)
+		sim.samplers[key] = sampler
+	}
+	return sampler(sim.rng)
+}
+
+// playGroup plays a single round robin and returns the group's teams in finishing order
+func (sim *tournamentSim) playGroup(members []int) []int {
+	for _, team := range members {
+		sim.points[team], sim.goalDifference[team], sim.goalsFor[team] = 0, 0, 0
+	}
+	for i, home := range members {
+		for _, away := range members[i+1:] {
+			lambdaHome, lambdaAway := sim.expectedGoals(home, away)
+			homeGoals := sim.goals(home, away, lambdaHome, false)
+			awayGoals := sim.goals(away, home, lambdaAway, false)
+			homePoints, awayPoints := matchPoints(homeGoals, awayGoals)
+			sim.points[home] += homePoints
+			sim.points[away] += awayPoints
+			sim.goalDifference[home] += homeGoals - awayGoals
+			sim.goalDifference[away] += awayGoals - homeGoals
+			sim.goalsFor[home] += homeGoals
+			sim.goalsFor[away] += awayGoals
+			sim.headToHead[home][away] = homePoints
+			sim.headToHead[away][home] = awayPoints
+		}
+	}
+	return sim.rank(members, sim.tiebreaks)
+}
+
+// rank orders teams by points and the tiebreak chain, drawing lots between teams still level
+func (sim *tournamentSim) rank(members []int, tiebreaks []string) []int {
+	positions := make([]int, len(members))
+	for i := range positions {
+		positions[i] = i
+	}
+	keys := tournamentKeys{sim: sim, members: members}
+	ordered := make([]int, 0, len(members))
+	for _, tied := range rankStandings(positions, tiebreaks, keys) {
+		sim.rng.Shuffle(len(tied), func(i, j int) { tied[i], tied[j] = tied[j], tied[i] })
+		for _, position := range tied {
+			ordered = append(ordered, members[position])
+		}
+	}
+	return ordered
+}
+
+// rankBestPlaced ranks the teams finishing just below the qualifiers across groups, on points,
+// goal difference and goals scored; head-to-head does not apply between groups
+func (sim *tournamentSim) rankBestPlaced(standings [][]int, tournament Tournament) []int {
+	if tournament.BestPlaced == 0 {
+		return nil
+	}
+	var candidates []int
+	for _, standing := range standings {
+		if len(standing) > tournament.Qualifiers {
+			candidates = append(candidates, standing[tournament.Qualifiers])
+		}
+	}
+	ranked := sim.rank(candidates, defaultTournamentTiebreaks)
+	if len(ranked) > tournament.BestPlaced {
+		ranked = ranked[:tournament.BestPlaced]
+	}
+	return ranked
+}
+
+// playKnockout plays a tie to a winner: 90 minutes, then extra time, then penalties
+func (sim *tournamentSim) playKnockout(home, away int) int {
+	lambdaHome, lambdaAway := sim.expectedGoals(home, away)
+	homeGoals := sim.goals(home, away, lambdaHome, false)
+	awayGoals := sim.goals(away, home, lambdaAway, false)
+	if homeGoals == awayGoals {
+		homeGoals += sim.goals(home, away, lambdaHome, true)
+		awayGoals += sim.goals(away, home, lambdaAway, true)
+	}
+	switch {
+	case homeGoals > awayGoals:
+		return home
+	case awayGoals > homeGoals:
+		return away
+	case sim.rng.Float64() < 0.5:
+		return home
+	default:
+		return away
+	}
+}
+
+// tournamentKeys ranks a set of entrants on the current path's group results
+type tournamentKeys struct {
+	sim     *tournamentSim
+	members []int
+}
+
+func (k tournamentKeys) points(team int) int         { return k.sim.points[k.members[team]] }
+func (k tournamentKeys) goalDifference(team int) int { return k.sim.goalDifference[k.members[team]] }
+func (k tournamentKeys) goalsFor(team int) int       { return k.sim.goalsFor[k.members[team]] }
+func (k tournamentKeys) name(team int) string        { return k.sim.names[k.members[team]] }
+
+func (k tournamentKeys) headToHeadPoints(team int, group []int) int {
+	total := 0
+	for _, other := range group {
+		if other != team {
+			total += k.sim.headToHead[k.members[team]][k.members[other]]
+		}
+	}
+	return total
+}
@@ -0,0 +1,94 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RatingPoint is a team's fitted ratings as of a single date
+type RatingPoint struct {
+	Date    string  `json:"date"` // As-of date (YYYY-MM-DD, inclusive)
+	Attack  float64 `json:"attack"`
+	Defense float64 `json:"defense"`
+	Matches int     `json:"matches"` // Team matches played up to the as-of date
+}
+
+// RatingTrajectory holds per-team rating time series from refits at a sequence of as-of dates
+type RatingTrajectory struct {
+	Dates  []string                 `json:"dates"`
+	Teams  map[string][]RatingPoint `json:"teams"`  // Only dates at which the team had played appear
+	Params []*MLEParams             `json:"params"` // Full fit per date, aligned with Dates
+}
+
+// TrajectoryOptions configures rating trajectory refits
+type TrajectoryOptions struct {
+	AsOfDates []string `json:"as_of_dates"` // Refit dates (YYYY-MM-DD); sorted before fitting
+	WarmStart bool     `json:"warm_start"`  // Initialize each refit from the previous date's ratings
+}
+
+// CalculateRatingTrajectory refits the model on matches up to each as-of date and returns
+// each team's attack/defense ratings over time
+func CalculateRatingTrajectory(request MLERequest, trajectoryOptions TrajectoryOptions) (*RatingTrajectory, error) {
+	if len(trajectoryOptions.AsOfDates) == 0 {
+		return nil, fmt.Errorf("at least one as-of date is required")
+	}
+
+	options := request.Options
+	if options.SimParams == nil {
+		options.SimParams = DefaultSimParams()
+	}
+	historical, err := ApplyTeamRenames(request.HistoricalData, options.TeamRenames)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := append([]string(nil), trajectoryOptions.AsOfDates...)
+	sort.Strings(dates)
+
+	trajectory := &RatingTrajectory{
+		Dates:  dates,
+		Teams:  make(map[string][]RatingPoint),
+		Params: make([]*MLEParams, 0, len(dates)),
+	}
+
+	var previous *MLEParams
+	for _, date := range dates {
+		var matches []MatchResult
+		matchCounts := make(map[string]int)
+		for _, match := range historical {
+			if match.Date <= date {
+				matches = append(matches, match)
+				matchCounts[match.HomeTeam]++
+				matchCounts[match.AwayTeam]++
+			}
+		}
+
+		asOfRequest := request
+		asOfRequest.HistoricalData = matches
+		if err := validateRequest(asOfRequest); err != nil {
+			return nil, fmt.Errorf("invalid data as of %s: %w", date, err)
+		}
+
+		solver := NewMLESolver(matches, options, request.LeagueChangeTeams)
+		if trajectoryOptions.WarmStart {
+			solver.initialParams = previous
+		}
+		params, err := solver.Optimize()
+		if err != nil {
+			return nil, fmt.Errorf("MLE optimization as of %s failed: %w", date, err)
+		}
+
+		for team, attack := range params.AttackRatings {
+			trajectory.Teams[team] = append(trajectory.Teams[team], RatingPoint{
+				Date:    date,
+				Attack:  attack,
+				Defense: params.DefenseRatings[team],
+				Matches: matchCounts[team],
+			})
+		}
+		trajectory.Params = append(trajectory.Params, params)
+		previous = params
+	}
+
+	return trajectory, nil
+}
@@ -0,0 +1,83 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultTransferWindowCloses are the usual European deadlines (MM-DD): summer and January
+var defaultTransferWindowCloses = []string{"09-01", "02-01"}
+
+// validateTransferWindows checks SimParams.TransferWindowCloses are MM-DD dates
+func validateTransferWindows(simParams *SimParams) error {
+	for _, closeDate := range simParams.TransferWindowCloses {
+		if _, err := time.Parse("01-02", closeDate); err != nil {
+			return fmt.Errorf("transfer window close %q is invalid: expected MM-DD", closeDate)
+		}
+	}
+	if simParams.TransferWindowLearningRate > 1 && simParams.TransferWindowDays <= 0 {
+		return fmt.Errorf("transfer window learning rate %.2f needs positive transfer window days, got %d", simParams.TransferWindowLearningRate, simParams.TransferWindowDays)
+	}
+	return nil
+}
+
+// transferWindowsEnabled reports whether SimParams boost learning after transfer windows
+func transferWindowsEnabled(simParams *SimParams) bool {
+	return simParams.TransferWindowLearningRate > 1 && simParams.TransferWindowDays > 0
+}
+
+// lastTransferWindowClose returns the latest window close on or before date
+func lastTransferWindowClose(date time.Time, simParams *SimParams) time.Time {
+	closes := simParams.TransferWindowCloses
+	if len(closes) == 0 {
+		closes = defaultTransferWindowCloses
+	}
+	var latest time.Time
+	for _, closeDate := range closes {
+		monthDay, err := time.Parse("01-02", closeDate)
+		if err != nil {
+			continue
+		}
+		// The close in date's year, or the year before when that is still to come
+		candidate := time.Date(date.Year(), monthDay.Month(), monthDay.Day(), 0, 0, 0, 0, time.UTC)
+		if candidate.After(date) {
+			candidate = candidate.AddDate(-1, 0, 0)
+		}
+		if candidate.After(latest) {
+			latest = candidate
+		}
+	}
+	return latest
+}
+
+// transferWindowFactor returns the learning rate multiplier for a team whose latest match is date:
+// the full TransferWindowLearningRate on a window's closing day, decaying linearly to 1.0 over
+// TransferWindowDays
+func transferWindowFactor(date string, simParams *SimParams) float64 {
+	if !transferWindowsEnabled(simParams) {
+		return 1.0
+	}
+	matchDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 1.0
+	}
+	days := matchDate.Sub(lastTransferWindowClose(matchDate, simParams)).Hours() / 24
+	if days >= float64(simParams.TransferWindowDays) {
+		return 1.0
+	}
+	enhancementRange := simParams.TransferWindowLearningRate - 1.0
+	return 1.0 + enhancementRange*(1-days/float64(simParams.TransferWindowDays))
+}
+
+// transferWindowVariance returns the dynamic model's extra rating variance for a team whose
+// previous match was on lastDate: DynamicSeasonVariance × (TransferWindowLearningRate − 1) when a
+// window closed after it, on or before date
+func transferWindowVariance(lastDate, date time.Time, simParams *SimParams) float64 {
+	if !transferWindowsEnabled(simParams) {
+		return 0
+	}
+	if !lastTransferWindowClose(date, simParams).After(lastDate) {
+		return 0
+	}
+	return simParams.DynamicSeasonVariance * (simParams.TransferWindowLearningRate - 1.0)
+}
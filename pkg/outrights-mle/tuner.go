@@ -0,0 +1,187 @@
+package outrightsmle
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// TuningSpace lists candidate values per hyperparameter; an empty list keeps the base value
+type TuningSpace struct {
+	TimeDecayBase    []float64 `json:"time_decay_base,omitempty"`
+	TimeDecayPower   []float64 `json:"time_decay_power,omitempty"`
+	BaseLearningRate []float64 `json:"base_learning_rate,omitempty"`
+	Rho              []float64 `json:"rho,omitempty"`
+	HomeAdvantage    []float64 `json:"home_advantage,omitempty"`
+}
+
+// DefaultTuningSpace returns a small grid around the default parameters
+func DefaultTuningSpace() TuningSpace {
+	return TuningSpace{
+		TimeDecayBase:    []float64{0.7, 0.78, 0.85, 0.92},
+		TimeDecayPower:   []float64{1.0, 1.5, 2.0},
+		BaseLearningRate: []float64{0.0005, 0.001, 0.002},
+		Rho:              []float64{-0.15, -0.1, -0.05, 0},
+		HomeAdvantage:    []float64{0.2, 0.25, 0.3, 0.35},
+	}
+}
+
+// TuneOptions configures hyperparameter search
+type TuneOptions struct {
+	Space         TuningSpace `json:"space"`
+	RandomSamples int         `json:"random_samples"` // Candidates sampled from the grid (0 = full grid search)
+	Folds         int         `json:"folds"`          // Walk-forward folds: each of the last N seasons is predicted from earlier seasons (default: 3)
+	Seed          int64       `json:"seed"`           // Seed for random sampling
+	Workers       int         `json:"workers"`        // Concurrent fits (0 = runtime.NumCPU())
+}
+
+// CandidateScore is the walk-forward loss of one hyperparameter candidate
+type CandidateScore struct {
+	Params  SimParams `json:"params"`
+	LogLoss float64   `json:"log_loss"` // Mean 1X2 log loss over all test matches (lower is better)
+	Matches int       `json:"matches"`  // Test matches scored
+}
+
+// TuneResult holds the best parameters and every candidate's score, best first
+type TuneResult struct {
+	Best       *SimParams       `json:"best"`
+	BestScore  float64          `json:"best_score"`
+	Candidates []CandidateScore `json:"candidates"`
+}
+
+// TuneHyperparameters searches the tuning space against walk-forward predictive loss
+// For each fold, ratings are fitted on all seasons before the test season and scored by the log
+// loss of their 1X2 probabilities on that season's matches, so no candidate sees its test data
+func TuneHyperparameters(matches []MatchResult, base *SimParams, options TuneOptions) (*TuneResult, error) {
+	if base == nil {
+		base = DefaultSimParams()
+	}
+	folds := options.Folds
+	if folds <= 0 {
+		folds = 3
+	}
+
+	seasons := ExtractSeasons(matches)
+	sort.Strings(seasons)
+	if len(seasons) < folds+1 {
+		return nil, fmt.Errorf("need at least %d seasons for %d walk-forward folds, got %d", folds+1, folds, len(seasons))
+	}
+	testSeasons := seasons[len(seasons)-folds:]
+
+	candidates := tuningCandidates(*base, options.Space)
+	if options.RandomSamples > 0 && options.RandomSamples < len(candidates) {
+		rng := rand.New(rand.NewSource(options.Seed))
+		rng.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		candidates = candidates[:options.RandomSamples]
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	scores := make([]CandidateScore, len(candidates))
+	errs := make([]error, len(candidates))
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range candidates {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			scores[i], errs[i] = scoreCandidate(matches, candidates[i], testSeasons)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].LogLoss < scores[j].LogLoss
+	})
+	best := scores[0].Params
+	return &TuneResult{
+		Best:       &best,
+		BestScore:  scores[0].LogLoss,
+		Candidates: scores,
+	}, nil
+}
+
+// tuningCandidates expands the grid of the tuning space over the base parameters
+func tuningCandidates(base SimParams, space TuningSpace) []SimParams {
+	candidates := []SimParams{base}
+	expand := func(values []float64, set func(*SimParams, float64)) {
+		if len(values) == 0 {
+			return
+		}
+		expanded := make([]SimParams, 0, len(candidates)*len(values))
+		for _, candidate := range candidates {
+			for _, value := range values {
+				next := candidate
+				set(&next, value)
+				expanded = append(expanded, next)
+			}
+		}
+		candidates = expanded
+	}
+	expand(space.TimeDecayBase, func(p *SimParams, v float64) { p.TimeDecayBase = v })
+	expand(space.TimeDecayPower, func(p *SimParams, v float64) { p.TimeDecayPower = v })
+	expand(space.BaseLearningRate, func(p *SimParams, v float64) { p.BaseLearningRate = v })
+	expand(space.Rho, func(p *SimParams, v float64) { p.Rho = v })
+	expand(space.HomeAdvantage, func(p *SimParams, v float64) { p.HomeAdvantage = v })
+	return candidates
+}
+
+// scoreCandidate fits each fold's training seasons and scores the test season's 1X2 log loss
+func scoreCandidate(matches []MatchResult, params SimParams, testSeasons []string) (CandidateScore, error) {
+	score := CandidateScore{Params: params}
+	totalLoss := 0.0
+
+	for _, testSeason := range testSeasons {
+		var train, test []MatchResult
+		for _, match := range matches {
+			switch {
+			case match.Season < testSeason:
+				train = append(train, match)
+			case match.Season == testSeason && match.Competition == "":
+				test = append(test, match)
+			}
+		}
+
+		trainLeague, _ := splitCupMatches(train)
+		leagueChangeTeams := NewEventProcessor(trainLeague, false).DetectLeagueChangeTeams()
+		candidateParams := params
+		solver := NewMLESolver(train, MLEOptions{SimParams: &candidateParams}, leagueChangeTeams)
+		if _, err := solver.Optimize(); err != nil {
+			return score, fmt.Errorf("fit before season %s failed: %w", testSeason, err)
+		}
+
+		for _, match := range test {
+			// Teams new in the test season have no fitted rating; they are scored as average teams
+			probabilities := solver.calculateMatchProbabilities(match.HomeTeam, match.AwayTeam, solver.matchHomeAdvantage(match))
+			outcome := 1
+			if match.HomeGoals > match.AwayGoals {
+				outcome = 0
+			} else if match.HomeGoals < match.AwayGoals {
+				outcome = 2
+			}
+			totalLoss -= math.Log(math.Max(probabilities[outcome], 1e-12))
+			score.Matches++
+		}
+	}
+
+	if score.Matches > 0 {
+		score.LogLoss = totalLoss / float64(score.Matches)
+	}
+	return score, nil
+}
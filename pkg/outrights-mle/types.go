@@ -4,70 +4,178 @@ import "time"
 
 // MatchResult represents a completed football match with result
 type MatchResult struct {
-	Date      string `json:"date"`
-	Season    string `json:"season"`
-	League    string `json:"league"`
-	HomeTeam  string `json:"home_team"`
-	AwayTeam  string `json:"away_team"`
-	HomeGoals int    `json:"home_goals"`
-	AwayGoals int    `json:"away_goals"`
+	Date              string `json:"date"`
+	Season            string `json:"season"`
+	League            string `json:"league"`
+	HomeTeam          string `json:"home_team"`
+	AwayTeam          string `json:"away_team"`
+	HomeGoals         int    `json:"home_goals"`
+	AwayGoals         int    `json:"away_goals"`
+	Competition       string `json:"competition,omitempty"`          // Cup competition (e.g., "FA Cup"); empty for league matches
+	Neutral           bool   `json:"neutral,omitempty"`              // Played at a neutral venue (no home advantage)
+	HalfTimeHomeGoals *int   `json:"half_time_home_goals,omitempty"` // Home goals at half-time, when known
+	HalfTimeAwayGoals *int   `json:"half_time_away_goals,omitempty"` // Away goals at half-time, when known
 }
 
 
 // MLEParams holds the Maximum Likelihood Estimation parameters
 type MLEParams struct {
-	HomeAdvantage    float64            `json:"home_advantage"`    // Default: 0.3
-	Rho              float64            `json:"rho"`               // Dixon-Coles parameter: -0.1
-	AttackRatings    map[string]float64 `json:"attack_ratings"`
-	DefenseRatings   map[string]float64 `json:"defense_ratings"`
-	LogLikelihood    float64            `json:"log_likelihood"`
-	Iterations       int                `json:"iterations"`
-	Converged        bool               `json:"converged"`
+	HomeAdvantage           float64                     `json:"home_advantage"`                      // Default: 0.3 (latest season's value when per-season)
+	SeasonHomeAdvantage     map[string]float64          `json:"season_home_advantage,omitempty"`     // Season -> home advantage (schedule or fitted)
+	Rho                     float64                     `json:"rho"`                                 // Dixon-Coles parameter (from SimParams, default -0.1; latest season's value when per-season)
+	SeasonRho               map[string]float64          `json:"season_rho,omitempty"`                // Season -> Dixon-Coles rho (schedule or fitted)
+	CountModel              string                      `json:"count_model,omitempty"`               // Goal count model with a fitted shape (SimParams.CountModel); empty for Poisson
+	CountShape              float64                     `json:"count_shape,omitempty"`               // Fitted CMP dispersion or Weibull shape; 1 is Poisson
+	Intercept               float64                     `json:"intercept,omitempty"`                 // Log goal rate of an average team away (SimParams.FitIntercept; 0 otherwise)
+	LeagueIntercepts        map[string]float64          `json:"league_intercepts,omitempty"`         // League -> log goal rate on top of Intercept for its matches (SimParams.FitLeagueIntercepts)
+	TeamLeagues             map[string]string           `json:"team_leagues,omitempty"`              // Team -> league whose intercept its ratings are relative to (SimParams.FitLeagueIntercepts)
+	AttackRatings           map[string]float64          `json:"attack_ratings"`
+	DefenseRatings          map[string]float64          `json:"defense_ratings"`
+	LogLikelihood           float64                     `json:"log_likelihood"`
+	Iterations              int                         `json:"iterations"`
+	Converged               bool                        `json:"converged"`
+	ValidationLoss          float64                     `json:"validation_loss,omitempty"`           // Best held-out log loss per match when early stopping
+	DroppedTeams            []DroppedTeam               `json:"dropped_teams,omitempty"`             // Teams left out for too few matches (SimParams.MinMatchesPerTeam)
+	CovariateCoefficients   map[string]float64          `json:"covariate_coefficients,omitempty"`    // Form covariate -> fitted effect on log scoring rates
+	CovariateStandardErrors map[string]float64          `json:"covariate_standard_errors,omitempty"` // Form covariate -> standard error of its coefficient
+	LastMatchDates          map[string]string           `json:"last_match_dates,omitempty"`          // Team -> latest fitted match date, for short_rest pricing
+	LambdaMultipliers       map[string]LambdaMultiplier `json:"lambda_multipliers,omitempty"`        // Team -> availability multipliers folded into these ratings after the fit
+	RatingOverrides         []AppliedRatingOverride     `json:"rating_overrides,omitempty"`          // Manual overrides applied to these ratings after the fit, by team
 }
 
 // SimParams holds all simulation and MLE parameterization values
 type SimParams struct {
 	// Core MLE parameters
 	HomeAdvantage         float64 `json:"home_advantage"`          // Home team advantage (default: 0.3)
+	Rho                   float64 `json:"rho"`                     // Dixon-Coles low-score correlation (default: -0.1)
+	
+	// Home advantage regime parameters
+	SeasonHomeAdvantage        map[string]float64 `json:"season_home_advantage,omitempty"` // Fixed home advantage per season (e.g., "2021": 0.05 behind closed doors)
+	FitSeasonHomeAdvantage     bool               `json:"fit_season_home_advantage"`       // Estimate home advantage per season not in the schedule (default: false)
+	HomeAdvantagePriorVariance float64            `json:"home_advantage_prior_variance"`   // Shrinkage of fitted seasons toward HomeAdvantage (default: 0.01)
+	FitIntercept               bool               `json:"fit_intercept"`                   // Estimate the average log goal rate instead of fixing it at zero (default: false)
+	FitLeagueIntercepts        bool               `json:"fit_league_intercepts"`           // Estimate a log goal rate per league, so ratings are relative to their league (default: false)
+	
+	// Low-score regime parameters
+	SeasonRho        map[string]float64 `json:"season_rho,omitempty"` // Fixed Dixon-Coles rho per season (e.g., "2016": -0.05)
+	FitSeasonRho     bool               `json:"fit_season_rho"`       // Estimate rho per season not in the schedule (default: false)
+	RhoPriorVariance float64            `json:"rho_prior_variance"`   // Shrinkage of fitted seasons toward Rho (default: 0.0025)
 	
 	// Learning parameters
-	BaseLearningRate         float64 `json:"base_learning_rate"`         // Base learning rate for gradient ascent (default: 0.001)
-	LeagueChangeLearningRate float64 `json:"league_change_learning_rate"` // Enhancement multiplier for teams that changed leagues (default: 2.0)
+	BaseLearningRate          float64 `json:"base_learning_rate"`           // Base learning rate for gradient ascent (default: 0.001)
+	LeagueChangeLearningRate  float64 `json:"league_change_learning_rate"`  // Enhancement multiplier for teams that changed leagues (default: 2.0)
+	ManagerChangeLearningRate float64 `json:"manager_change_learning_rate"` // Enhancement multiplier for teams under a new manager (default: 2.0)
+	
+	// Transfer window parameters
+	TransferWindowLearningRate float64  `json:"transfer_window_learning_rate"`    // Enhancement multiplier for matches just after a transfer window closes (default: 1.0, disabled)
+	TransferWindowDays         int      `json:"transfer_window_days"`             // Days after a close over which the enhancement decays (default: 30)
+	TransferWindowCloses       []string `json:"transfer_window_closes,omitempty"` // Window closing dates as MM-DD (default: 09-01 and 02-01)
+	
+	// Rating bound parameters
+	MinRating float64 `json:"min_rating"` // Floor on every attack and defense rating during fitting (default: 0, disabled)
+	MaxRating float64 `json:"max_rating"` // Ceiling on every attack and defense rating during fitting (default: 0, disabled)
+	MaxLambda float64 `json:"max_lambda"` // Cap on expected goals per team per match in fitting and pricing (default: 0, disabled)
 	
 	// Time weighting parameters
 	TimeDecayBase         float64 `json:"time_decay_base"`         // Time decay base factor (default: 0.85)
 	TimeDecayPower        float64 `json:"time_decay_power"`        // Time decay power exponent (default: 1.5)
 	
+	// Cup match parameters
+	CupMatchWeight        float64            `json:"cup_match_weight"`              // Likelihood weight for cup matches without a CompetitionWeights entry (default: 0.5)
+	CompetitionWeights    map[string]float64 `json:"competition_weights,omitempty"` // Cup competition -> likelihood weight (e.g., "FA Cup": 0.5)
+	MinMatchesPerTeam     int                `json:"min_matches_per_team"`          // Leave out teams with fewer matches, unless in a latest-season league (default: 0, disabled)
+	
 	// Optimization parameters
 	MaxIterations         int     `json:"max_iterations"`          // Maximum MLE iterations (default: 200)
 	Tolerance             float64 `json:"tolerance"`               // Convergence tolerance (default: 1e-6)
+	ValidationGameweeks   int     `json:"validation_gameweeks"`    // Hold out the most recent K gameweeks for early stopping (default: 0, disabled)
+	EarlyStoppingPatience int     `json:"early_stopping_patience"` // Iterations without validation improvement before stopping (default: 10)
+	
+	// Rating model parameters
+	RatingModel            string   `json:"rating_model,omitempty"`    // "static" (default) or "dynamic" random-walk ratings
+	DynamicInitialVariance float64  `json:"dynamic_initial_variance"`  // Prior rating variance for a team's first match (default: 0.1)
+	DynamicWeeklyVariance  float64  `json:"dynamic_weekly_variance"`   // Random-walk variance added per week between matches (default: 0.0005)
+	DynamicSeasonVariance  float64  `json:"dynamic_season_variance"`   // Extra variance added across a season break (default: 0.01)
+	EntryPriorQuantile     float64  `json:"entry_prior_quantile"`      // Division rating quantile for teams new in the latest season (default: 0.25, 0 disables)
+	EntryPriorVariance     float64  `json:"entry_prior_variance"`      // Shrinkage of new teams' static ratings toward that quantile (default: 0.05)
+	FormCovariates         []string `json:"form_covariates,omitempty"` // Form covariates fitted with the static ratings (recent_ppg, unbeaten_streak, short_rest)
+	
+	// Count model parameters
+	CountModel string  `json:"count_model,omitempty"` // "poisson" (default), "cmp" Conway-Maxwell-Poisson or "weibull" inter-arrival goals, with a fitted shape
+	CountShape float64 `json:"count_shape"`           // Starting shape: CMP dispersion 0.5 to 3, Weibull shape 0.5 to 2; 1 is Poisson (default: 0, meaning 1)
 	
 	// Simulation parameters
 	SimulationPaths       int     `json:"simulation_paths"`        // Monte Carlo simulation paths (default: 5000)
 	GoalSimulationBound   int     `json:"goal_simulation_bound"`   // Upper bound for goal calculations (default: 10)
+	DisableDeadHeat       bool    `json:"disable_dead_heat"`       // Resolve exact ties by sort order instead of dead-heating (default: false)
+	Tiebreaks             map[string][]string `json:"tiebreaks,omitempty"` // League -> tiebreak chain after points (default: goal_difference)
+	Seed                  int64   `json:"seed"`                    // Simulation random seed; 0 seeds randomly (default: 0)
+	
+	// Output parameters
+	FormWindow            int     `json:"form_window"`             // Recent matches used for team form (default: 6)
+	DecimalOdds           bool    `json:"decimal_odds"`            // Emit fair decimal odds alongside probabilities (default: false)
+	OddsProbabilityFloor  float64 `json:"odds_probability_floor"`  // Probabilities below this are priced at 1/floor (default: 0.001)
+	PriceLadder           PriceLadder `json:"price_ladder,omitempty"` // Quotable prices that fair prices snap to in edge reports (default: none)
+	MatchOverround        float64 `json:"match_overround"`         // Margin added to 1X2 probabilities for bookable prices (default: 0, disabled)
+	OutrightOverround     float64 `json:"outright_overround"`      // Margin added to each market's marks for bookable prices (default: 0, disabled)
+	MarginMethod          string  `json:"margin_method"`           // "proportional" or "favourite_weighted" (default: proportional)
 }
 
 // MLEOptions configures the MLE optimization parameters
 type MLEOptions struct {
-	SimParams *SimParams `json:"sim_params,omitempty"` // Simulation parameters (uses defaults if nil)
-	Debug     bool       `json:"debug"`                // Enable debug output during optimization
+	SimParams          *SimParams                  `json:"sim_params,omitempty"`          // Simulation parameters (uses defaults if nil)
+	Debug              bool                        `json:"debug"`                         // Enable debug output during optimization
+	Workers            int                         `json:"workers,omitempty"`             // Max concurrent league fits/simulations (0 = runtime.NumCPU())
+	Metrics            MetricsRecorder             `json:"-"`                             // Optional metrics hooks, e.g. Prometheus (nil disables)
+	Conditioning       *Conditioning               `json:"conditioning,omitempty"`        // Optional fixed future results for what-if simulation
+	IndependentLeagues bool                        `json:"independent_leagues,omitempty"` // Fit each league on its own matches only (no cross-league pooling)
+	AsOfDate           string                      `json:"as_of_date,omitempty"`          // Ignore results after this date (YYYY-MM-DD, inclusive)
+	OnLeagueResult     func(LeagueResult)          `json:"-"`                             // Optional: called with each league's result as soon as it completes
+	TeamRenames        []TeamRename                `json:"team_renames,omitempty"`        // Former team names mapped to canonical ones before fitting
+	RatingsBlend       *RatingsBlend               `json:"ratings_blend,omitempty"`       // Optional external rating sets mixed into the fitted ratings
+	RequireConvergence bool                        `json:"require_convergence,omitempty"` // Fail with ErrNotConverged when the fit hits MaxIterations
+	ManagerChanges     []ManagerChange             `json:"manager_changes,omitempty"`     // New managers; their teams get ManagerChangeLearningRate
+	LambdaMultipliers  map[string]LambdaMultiplier `json:"lambda_multipliers,omitempty"`  // Team -> expected goals multipliers for simulation and pricing, not fitting
+	RatingOverrides    map[string]RatingOverride   `json:"rating_overrides,omitempty"`    // Team -> ratings pinned or offset after the fit
+	Deterministic      bool                        `json:"deterministic,omitempty"`       // Seed unseeded simulations with DeterministicSeed and zero timings, for repeatable output
+	IncludeMatchOdds   bool                        `json:"include_match_odds,omitempty"`  // Add remaining-fixture odds per league to MultiLeagueResult.MatchOdds
+	RatingsOnly        bool                        `json:"ratings_only,omitempty"`        // Skip the season simulation and market evaluation; return ratings and tables only
+	SuppliedRatings    *RatingSet                  `json:"supplied_ratings,omitempty"`    // Skip the fit and simulate from these ratings (Weight is unused)
+	VectorizedFit      bool                        `json:"vectorized_fit,omitempty"`      // Evaluate the static fit's likelihood and gradients with dense vector kernels
 }
 
 
 // MatchOdds represents the 1X2 probabilities for a fixture
 type MatchOdds struct {
-	Fixture       string      `json:"fixture"`       // "{Home} vs {Away}"
-	League        string      `json:"league"`        // League code (e.g., "EPL", "SCO1")
-	Probabilities [3]float64  `json:"probabilities"` // [home_win, draw, away_win]
+	Fixture       string          `json:"fixture"`                // "{Home} vs {Away}"
+	League        string          `json:"league"`                 // League code (e.g., "EPL", "SCO1")
+	Probabilities [3]float64      `json:"probabilities"`          // [home_win, draw, away_win]
+	Neutral       bool            `json:"neutral,omitempty"`      // Priced without home advantage
+	Date          string          `json:"date,omitempty"`         // Scheduled date, when supplied in MLERequest.Schedule
+	Round         int             `json:"round,omitempty"`        // Scheduled round, when supplied in MLERequest.Schedule
+	DecimalOdds   []float64       `json:"decimal_odds,omitempty"` // Fair [home, draw, away] odds (SimParams.DecimalOdds)
+	BookOdds      []float64       `json:"book_odds,omitempty"`    // Bookable [home, draw, away] prices (SimParams.MatchOverround)
+	Margins       *WinningMargins `json:"margins,omitempty"`      // Winning-margin bands (PriceFixtures and PriceNeutralFixtures only)
+}
+
+// ScheduledFixture is an unplayed league match with its scheduled date and round
+type ScheduledFixture struct {
+	League   string `json:"league"`
+	HomeTeam string `json:"home_team"`
+	AwayTeam string `json:"away_team"`
+	Date     string `json:"date,omitempty"`    // YYYY-MM-DD
+	Round    int    `json:"round,omitempty"`   // Matchday number
+	Neutral  bool   `json:"neutral,omitempty"` // Played at a neutral venue
 }
 
 // MLEResult contains the output of MLE optimization
 type MLEResult struct {
-	Teams            []Team        `json:"teams"`
-	MatchOdds        []MatchOdds   `json:"match_odds"`
-	MLEParams        MLEParams     `json:"mle_params"`
-	ProcessingTime   time.Duration `json:"processing_time"`
-	MatchesProcessed int           `json:"matches_processed"`
+	Teams            []Team          `json:"teams"`
+	MatchOdds        []MatchOdds     `json:"match_odds"`
+	MLEParams        MLEParams       `json:"mle_params"`
+	ProcessingTime   time.Duration   `json:"processing_time"`
+	MatchesProcessed int             `json:"matches_processed"`
+	Fingerprint      DataFingerprint `json:"fingerprint"` // Digests of the historical data and handicaps
 }
 
 // MLERequest contains all parameters needed for MLE optimization
@@ -76,20 +184,67 @@ type MLERequest struct {
 	LeagueChangeTeams map[string]bool `json:"league_change_teams"` // Teams that changed leagues before season start
 	LeagueGroups   map[string][]string `json:"league_groups,omitempty"` // Optional: league -> teams mapping
 	Handicaps      map[string]int `json:"handicaps,omitempty"` // Initial points for teams (team name -> points)
+	Schedule       []ScheduledFixture `json:"schedule,omitempty"` // Optional: fixture list used for MatchOdds
 	Options        MLEOptions        `json:"options"`
 }
 
+// LeagueChange records a team playing in a different league from the previous season
+// Every team with a change receives LeagueChangeLearningRate on its latest-season matches
+type LeagueChange struct {
+	Team             string `json:"team"`
+	FromLeague       string `json:"from_league"`
+	ToLeague         string `json:"to_league"`
+	Season           string `json:"season"`             // First season in the new league
+	Direction        string `json:"direction"`          // "promoted" or "relegated"
+	IntoLatestSeason bool   `json:"into_latest_season"` // Change took effect in the latest season
+}
+
 // Team represents a team with all related parameters
 type Team struct {
-	Name                 string  `json:"name"`
-	Points               int     `json:"points"`
-	GoalDifference       int     `json:"goal_difference"`
-	Played               int     `json:"played"`
-	AttackRating         float64 `json:"attack_rating"`
-	DefenseRating        float64 `json:"defense_rating"`
-	LambdaHome           float64 `json:"lambda_home"`
-	LambdaAway           float64 `json:"lambda_away"`
-	ExpectedSeasonPoints float64 `json:"expected_season_points"`
+	Name                       string                `json:"name"`
+	Points                     int                   `json:"points"`
+	GoalDifference             int                   `json:"goal_difference"`
+	GoalsFor                   int                   `json:"goals_for"`
+	GoalsAgainst               int                   `json:"goals_against"`
+	Played                     int                   `json:"played"`
+	Won                        int                   `json:"won"`
+	Drawn                      int                   `json:"drawn"`
+	Lost                       int                   `json:"lost"`
+	AttackRating               float64               `json:"attack_rating"`
+	DefenseRating              float64               `json:"defense_rating"`
+	LambdaHome                 float64               `json:"lambda_home"`
+	LambdaAway                 float64               `json:"lambda_away"`
+	ExpectedSeasonPoints       float64               `json:"expected_season_points"`
+	ExpectedSeasonGoalsFor     float64               `json:"expected_season_goals_for"`     // Mean simulated season goals scored, including those already played
+	ExpectedSeasonGoalsAgainst float64               `json:"expected_season_goals_against"` // Mean simulated season goals conceded, including those already played
+	ExpectedPosition           float64               `json:"expected_position"`             // Mean simulated finishing position (1 = top)
+	MedianPosition             int                   `json:"median_position"`
+	ModalPosition              int                   `json:"modal_position"`                // Most likely finishing position
+	CleanSheets                *CleanSheetForecast   `json:"clean_sheets,omitempty"`        // Season clean sheets, kept plus priced from the remaining fixtures
+	VsAverage                  *AverageOpponentGoals `json:"vs_average,omitempty"`          // Expected goals per match against a league-average opponent
+	Form                       *TeamForm             `json:"form,omitempty"`                // Recent form in the latest season
+	Scenarios                  []Scenario            `json:"scenarios,omitempty"`           // Best (90th percentile), median and worst (10th) simulated seasons
+}
+
+// AverageOpponentGoals reads a team's ratings as expected goals per match against an opponent
+// with the mean ratings of the rest of its league, so the table needs no log scale
+type AverageOpponentGoals struct {
+	HomeFor           float64 `json:"home_for"`            // Goals scored at home
+	HomeAgainst       float64 `json:"home_against"`        // Goals conceded at home
+	AwayFor           float64 `json:"away_for"`            // Goals scored away
+	AwayAgainst       float64 `json:"away_against"`        // Goals conceded away
+	GoalsAboveAverage float64 `json:"goals_above_average"` // Goal difference per match, home and away averaged
+}
+
+// TeamForm summarizes a team's most recent matches in the latest season
+type TeamForm struct {
+	Results             string  `json:"results"`                // W/D/L, oldest to newest (e.g., "WWDLWD")
+	Matches             int     `json:"matches"`                // Matches in the form window
+	PointsPerGame       float64 `json:"points_per_game"`        // Over the form window
+	GoalsForPerGame     float64 `json:"goals_for_per_game"`     // Over the form window
+	GoalsAgainstPerGame float64 `json:"goals_against_per_game"` // Over the form window
+	GoalsForTrend       float64 `json:"goals_for_trend"`        // Form-window minus season goals for per game
+	GoalsAgainstTrend   float64 `json:"goals_against_trend"`    // Form-window minus season goals against per game
 }
 
 // Event represents a match event (adapted from go-outrights)
@@ -101,13 +256,24 @@ type Event struct {
 
 // Market represents a betting market (adapted from go-outrights)
 type Market struct {
-	Name         string    `json:"name"`
-	League       string    `json:"league"`          // League this market applies to
-	Payoff       string    `json:"payoff"`          // Payoff expression like "1|4x0.25|19x0"
-	ParsedPayoff []float64 `json:"-"`               // Parsed version, not serialized
-	Teams        []string  `json:"teams,omitempty"` // Computed teams for this market
-	Include      []string  `json:"include,omitempty"`
-	Exclude      []string  `json:"exclude,omitempty"`
+	Name         string             `json:"name"`
+	Type         string             `json:"type,omitempty"`         // Market type (default: finishing position payoff)
+	League       string             `json:"league"`                 // League this market applies to
+	Payoff       string             `json:"payoff"`                 // Payoff expression like "1|4x0.25|19x0"
+	ParsedPayoff []float64          `json:"-"`                      // Parsed version, not serialized
+	Teams        []string           `json:"teams,omitempty"`        // Computed teams for this market
+	Include      []string           `json:"include,omitempty"`
+	Exclude      []string           `json:"exclude,omitempty"`
+	Prices       map[string]float64 `json:"prices,omitempty"`       // Optional bookmaker decimal odds (team -> price)
+	EachWay      *EachWayTerms      `json:"each_way,omitempty"`     // Optional each-way place terms
+	ExcludeRule  *ExcludeRule       `json:"exclude_rule,omitempty"` // Optional rule-based exclusions, e.g. top 6 by rating
+	Lines        map[string]float64 `json:"lines,omitempty"`        // Line markets: team -> line (e.g., 82.5 points)
+}
+
+// EachWayTerms describes each-way place terms, e.g. 1/4 odds top 4 is {Places: 4, Fraction: 0.25}
+type EachWayTerms struct {
+	Places   int     `json:"places"`   // Finishing positions that pay the place part
+	Fraction float64 `json:"fraction"` // Fraction of the win odds paid on the place part
 }
 
 
@@ -117,22 +283,52 @@ func DefaultSimParams() *SimParams {
 	return &SimParams{
 		// Core MLE parameters
 		HomeAdvantage:         0.3,   // Home team advantage
+		Rho:                   -0.1,  // Dixon-Coles parameter (standard value)
+		
+		// Home advantage regime parameters
+		HomeAdvantagePriorVariance: 0.01, // Fitted seasons stay within about ±0.1 of HomeAdvantage on little data
+		
+		// Low-score regime parameters
+		RhoPriorVariance: 0.0025, // Fitted seasons stay within about ±0.05 of Rho on little data
 		
 		// Learning parameters
-		BaseLearningRate:         0.001,  // Base learning rate for gradient ascent
-		LeagueChangeLearningRate: 2.0,    // Enhancement multiplier for teams that changed leagues
+		BaseLearningRate:          0.001, // Base learning rate for gradient ascent
+		LeagueChangeLearningRate:  2.0,   // Enhancement multiplier for teams that changed leagues
+		ManagerChangeLearningRate: 2.0,   // Enhancement multiplier for teams under a new manager
+		
+		// Transfer window parameters
+		TransferWindowLearningRate: 1.0, // No enhancement after transfer windows
+		TransferWindowDays:         30,  // Enhancement decays over a month when enabled
 		
 		// Time weighting parameters
 		TimeDecayBase:        0.85,   // Time decay base factor
 		TimeDecayPower:       1.5,    // Time decay power exponent
 		
+		// Cup match parameters
+		CupMatchWeight:       0.5,    // Cup matches count half as much as league matches
+		
 		// Optimization parameters
 		MaxIterations:        200,    // Maximum MLE iterations
 		Tolerance:            1e-6,   // Convergence tolerance
+		ValidationGameweeks:   0,     // Early stopping disabled
+		EarlyStoppingPatience: 10,    // Iterations without validation improvement
+		
+		// Rating model parameters
+		RatingModel:            RatingModelStatic, // Single rating per team with time decay
+		DynamicInitialVariance: 0.1,               // Prior rating variance for new teams
+		DynamicWeeklyVariance:  0.0005,            // Random-walk variance per week
+		DynamicSeasonVariance:  0.01,              // Extra variance across a season break
+		EntryPriorQuantile:     0.25,              // Bottom quartile of the division entered
+		EntryPriorVariance:     0.05,              // About half a season of matches' worth of evidence
 		
 		// Simulation parameters
 		SimulationPaths:      5000,   // Monte Carlo simulation paths
 		GoalSimulationBound:  10,     // Upper bound for goal calculations
+		
+		// Output parameters
+		FormWindow:           6,      // Recent matches used for team form
+		OddsProbabilityFloor: defaultOddsProbabilityFloor, // Longest fair price 1000.0
+		MarginMethod:         MarginProportional, // Overrounds are 0, so no bookable prices by default
 	}
 }
 
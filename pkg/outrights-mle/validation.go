@@ -81,21 +81,34 @@ func ValidateLeagueGroups(leagueGroups map[string][]string, globalEntities Globa
 	return nil
 }
 
+// Minimum data for a fit: at least minTeams teams, and minHistoricalMatches matches or
+// minMatchesPerTeam per team, whichever is fewer
+const (
+	minTeams             = 4
+	minHistoricalMatches = 100
+	minMatchesPerTeam    = 4
+)
+
 // validateRequest checks if the MLE request is valid
 func validateRequest(request MLERequest) error {
 	if len(request.HistoricalData) == 0 {
-		return fmt.Errorf("historical data is required")
-	}
-
-	// Validate that we have enough data
-	if len(request.HistoricalData) < 100 {
-		return fmt.Errorf("insufficient historical data: need at least 100 matches, got %d", len(request.HistoricalData))
+		return fmt.Errorf("%w: historical data is required", ErrInsufficientData)
 	}
 
 	// Check for required teams
 	teams := ExtractTeams(request.HistoricalData)
-	if len(teams) < 10 {
-		return fmt.Errorf("insufficient teams: need at least 10 teams, got %d", len(teams))
+	if len(teams) < minTeams {
+		return fmt.Errorf("%w: need at least %d teams, got %d", ErrInsufficientData, minTeams, len(teams))
+	}
+
+	// Validate that we have enough data; small leagues (e.g. an 8-team women's league with a
+	// short history) need a few matches per team rather than the full minimum
+	requiredMatches := minHistoricalMatches
+	if perTeam := minMatchesPerTeam * len(teams); perTeam < requiredMatches {
+		requiredMatches = perTeam
+	}
+	if len(request.HistoricalData) < requiredMatches {
+		return fmt.Errorf("%w: need at least %d matches, got %d", ErrInsufficientData, requiredMatches, len(request.HistoricalData))
 	}
 
 	// Validate handicaps against global team list
@@ -107,11 +120,42 @@ func validateRequest(request MLERequest) error {
 		
 		for teamName := range request.Handicaps {
 			if !teamSet[teamName] {
-				return fmt.Errorf("handicaps contains unknown team: %s", teamName)
+				return &UnknownTeamError{Team: teamName, Context: "handicaps"}
 			}
 		}
 	}
 
+	if err := validateManagerChanges(request.Options.ManagerChanges, teams); err != nil {
+		return err
+	}
+	if err := validateLambdaMultipliers(request.Options.LambdaMultipliers, teams); err != nil {
+		return err
+	}
+	if err := validateRatingOverrides(request.Options.RatingOverrides, teams); err != nil {
+		return err
+	}
+
+	if request.Options.SimParams != nil {
+		if err := validateCovariates(request.Options.SimParams); err != nil {
+			return err
+		}
+		if err := validateTransferWindows(request.Options.SimParams); err != nil {
+			return err
+		}
+		if err := validateRatingBounds(request.Options.SimParams); err != nil {
+			return err
+		}
+		if err := validateCompetitionWeights(request.Options.SimParams); err != nil {
+			return err
+		}
+		if err := request.Options.SimParams.PriceLadder.validate(); err != nil {
+			return err
+		}
+		if err := validateMargins(request.Options.SimParams); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,43 @@
+//go:build !gonum
+
+package outrightsmle
+
+// vectorKernels names the implementation behind the vec helpers, for debug output
+const vectorKernels = "go"
+
+// vecSubTo sets dst to s − t, element by element
+func vecSubTo(dst, s, t []float64) {
+	for i := range dst {
+		dst[i] = s[i] - t[i]
+	}
+}
+
+// vecSub subtracts s from dst, element by element
+func vecSub(dst, s []float64) {
+	for i := range dst {
+		dst[i] -= s[i]
+	}
+}
+
+// vecMulTo sets dst to s × t, element by element
+func vecMulTo(dst, s, t []float64) {
+	for i := range dst {
+		dst[i] = s[i] * t[i]
+	}
+}
+
+// vecMul multiplies dst by s, element by element
+func vecMul(dst, s []float64) {
+	for i := range dst {
+		dst[i] *= s[i]
+	}
+}
+
+// vecDot returns the dot product of s and t
+func vecDot(s, t []float64) float64 {
+	sum := 0.0
+	for i := range s {
+		sum += s[i] * t[i]
+	}
+	return sum
+}
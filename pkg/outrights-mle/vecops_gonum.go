@@ -0,0 +1,33 @@
+//go:build gonum
+
+package outrightsmle
+
+import "gonum.org/v1/gonum/floats"
+
+// vectorKernels names the implementation behind the vec helpers, for debug output
+const vectorKernels = "gonum"
+
+// vecSubTo sets dst to s − t, element by element
+func vecSubTo(dst, s, t []float64) {
+	floats.SubTo(dst, s, t)
+}
+
+// vecSub subtracts s from dst, element by element
+func vecSub(dst, s []float64) {
+	floats.Sub(dst, s)
+}
+
+// vecMulTo sets dst to s × t, element by element
+func vecMulTo(dst, s, t []float64) {
+	floats.MulTo(dst, s, t)
+}
+
+// vecMul multiplies dst by s, element by element
+func vecMul(dst, s []float64) {
+	floats.Mul(dst, s)
+}
+
+// vecDot returns the dot product of s and t
+func vecDot(s, t []float64) float64 {
+	return floats.Dot(s, t)
+}
@@ -0,0 +1,155 @@
+package outrightsmle
+
+import "math"
+
+// Shape bounds for the Weibull count fit: below 1 goals are overdispersed relative to Poisson,
+// above 1 underdispersed
+const (
+	minWeibullShape = 0.5
+	maxWeibullShape = 2.0
+)
+
+// Series sizes for the Weibull count model: probabilities are tabulated up to weibullMaxGoals,
+// each from weibullTerms terms, which converges for λ^c up to about 15
+const (
+	weibullMaxGoals = 40
+	weibullTerms    = 100
+)
+
+// weibullProbabilityFloor keeps the log of a probability lost to cancellation in the alternating
+// series finite
+const weibullProbabilityFloor = 1e-300
+
+// weibullCountModel counts goals as events of a renewal process with Weibull(c) gaps at rate λ
+// (McShane et al., 2008): P(k) = Σ_{j≥k} (−1)^(j+k) (λ^c)^j α_j^k / Γ(cj + 1). The α depend on
+// the shape only, so they are tabulated once per model, signed and divided by Γ(cj + 1); each
+// probability is then a polynomial in λ^c
+type weibullCountModel struct {
+	shape        float64
+	coefficients [][]float64 // Goals k -> term j -> (−1)^(j+k) α_j^k / Γ(cj + 1), zero below k
+}
+
+// newWeibullCountModel creates the Weibull count model with shape c
+func newWeibullCountModel(shape float64) CountModel {
+	// α_j^0 / Γ(cj + 1) = 1 / j!, and α_j^(k+1) = Σ_{m=k}^{j−1} α_m^k Γ(c(j−m) + 1) / (j−m)!.
+	// Dividing through by Γ(cj + 1) leaves ratios of gamma functions that stay in range
+	logGammas := make([]float64, weibullTerms+1)
+	for j := range logGammas {
+		logGammas[j], _ = math.Lgamma(shape*float64(j) + 1)
+	}
+	ratios := make([][]float64, weibullTerms+1) // j -> m -> Γ(cm + 1) Γ(c(j−m) + 1) / (Γ(cj + 1) (j−m)!)
+	for j := range ratios {
+		ratios[j] = make([]float64, j)
+		for m := range ratios[j] {
+			ratios[j][m] = math.Exp(logGammas[m] + logGammas[j-m] - logGammas[j] - lgammaFactorial(j-m))
+		}
+	}
+
+	scaled := make([]float64, weibullTerms+1) // α_j^k / Γ(cj + 1) for the current k
+	for j := range scaled {
+		scaled[j] = math.Exp(-lgammaFactorial(j))
+	}
+	model := &weibullCountModel{shape: shape, coefficients: make([][]float64, weibullMaxGoals+1)}
+	for k := 0; k <= weibullMaxGoals; k++ {
+		if k > 0 {
+			next := make([]float64, weibullTerms+1)
+			for j := k; j <= weibullTerms; j++ {
+				for m := k - 1; m < j; m++ {
+					next[j] += scaled[m] * ratios[j][m]
+				}
+			}
+			scaled = next
+		}
+		coefficients := make([]float64, weibullTerms+1)
+		for j := k; j <= weibullTerms; j++ {
+			coefficients[j] = scaled[j]
+			if (j+k)%2 == 1 {
+				coefficients[j] = -scaled[j]
+			}
+		}
+		model.coefficients[k] = coefficients
+	}
+	return model
+}
+
+func (m *weibullCountModel) Name() string   { return CountModelWeibull }
+func (m *weibullCountModel) Shape() float64 { return m.shape }
+
+// probability returns P(K = goals) and its derivative in log λ; a probability lost to
+// cancellation is weibullProbabilityFloor with no derivative, and goals beyond the table have neither.
+// Terms peak near j = goals + λ^c, and are summed until they stop changing the total
+func (m *weibullCountModel) probability(logLambda float64, goals int) (probability, derivative float64) {
+	if goals < 0 || goals > weibullMaxGoals {
+		return 0, 0
+	}
+	x := math.Exp(m.shape * logLambda)
+	peak := goals + int(x)
+	power := math.Exp(m.shape * logLambda * float64(goals))
+	for j := goals; j <= weibullTerms; j++ {
+		term := m.coefficients[goals][j] * power
+		probability += term
+		derivative += term * m.shape * float64(j)
+		if j > peak && math.Abs(term) <= math.Abs(probability)*skellamTolerance {
+			break
+		}
+		power *= x
+	}
+	if probability < weibullProbabilityFloor {
+		return weibullProbabilityFloor, 0
+	}
+	return probability, derivative
+}
+
+// LogProb returns log P(K = goals)
+func (m *weibullCountModel) LogProb(logLambda float64, goals int) float64 {
+	probability, _ := m.probability(logLambda, goals)
+	return math.Log(probability)
+}
+
+// Score returns the derivative of log P(K = goals) in log λ
+func (m *weibullCountModel) Score(logLambda float64, goals int) float64 {
+	probability, derivative := m.probability(logLambda, goals)
+	return derivative / probability
+}
+
+// Probabilities returns P(K = k) for k = 0..bound
+func (m *weibullCountModel) Probabilities(lambda float64, bound int) []float64 {
+	probabilities := make([]float64, bound+1)
+	if lambda <= 0 {
+		probabilities[0] = 1
+		return probabilities
+	}
+	logLambda := math.Log(lambda)
+	for k := range probabilities {
+		if k <= weibullMaxGoals {
+			probabilities[k], _ = m.probability(logLambda, k)
+		}
+	}
+	return probabilities
+}
+
+// Mean returns E[K] over the tabulated goals
+func (m *weibullCountModel) Mean(lambda float64) float64 {
+	mean := 0.0
+	for k, probability := range m.Probabilities(lambda, weibullMaxGoals) {
+		mean += float64(k) * probability
+	}
+	return mean
+}
+
+// WeibullCountProb calculates the Weibull count probability P(X = k) for goals arriving with
+// Weibull(shape) gaps at rate λ. Shape 1 is Poisson(λ); a larger shape makes the gaps more
+// regular and the goals less dispersed
+func WeibullCountProb(lambda, shape float64, k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	if lambda <= 0 {
+		if k == 0 {
+			return 1.0
+		}
+		return 0
+	}
+	probability, _ := newWeibullCountModel(shape).(*weibullCountModel).probability(math.Log(lambda), k)
+	return probability
+}